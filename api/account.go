@@ -8,6 +8,7 @@ import (
 	"github.com/jwambugu/go-simple-bank-class/token"
 	"github.com/lib/pq"
 	"net/http"
+	"time"
 )
 
 type (
@@ -20,9 +21,20 @@ type (
 	}
 
 	getAccountsRequest struct {
+		Owner    string `form:"owner" binding:"omitempty,alphanum"`
+		PageID   int32  `form:"page_id" binding:"required,min=1"`
+		PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+	}
+
+	listAccountEntriesRequest struct {
 		PageID   int32 `form:"page_id" binding:"required,min=1"`
 		PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
 	}
+
+	getAccountStatementRequest struct {
+		From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00" binding:"required"`
+		To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" binding:"required,gtfield=From"`
+	}
 )
 
 func (server *Server) createAccount(ctx *gin.Context) {
@@ -61,34 +73,45 @@ func (server *Server) createAccount(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, account)
 }
 
-func (server *Server) getAccountByID(ctx *gin.Context) {
-	var req getAccountByIDRequest
-
-	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
-	// Get account
-	account, err := server.store.GetAccount(ctx, int32(req.ID))
+// viewableAccount finds the account and checks that the authenticated user is allowed to view it.
+// The error response is written if the account cannot be viewed.
+func (server *Server) viewableAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, int32(accountID))
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
+			return account, false
 		}
 
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return account, false
 	}
 
 	// Get the auth user
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	if account.Owner != authPayload.Username {
+	if account.Owner != authPayload.Username && !hasPermission(authPayload.Role, permissionViewAnyAccount) {
 		err := errors.New("account does not belong to the authenticated user")
 
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
+
+func (server *Server) getAccountByID(ctx *gin.Context) {
+	var req getAccountByIDRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.viewableAccount(ctx, req.ID)
+
+	if !ok {
 		return
 	}
 
@@ -106,8 +129,22 @@ func (server *Server) getAccounts(ctx *gin.Context) {
 	// Get the auth user
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
+	// Users list their own accounts unless they are allowed to view the accounts of other customers
+	owner := authPayload.Username
+
+	if req.Owner != "" && req.Owner != authPayload.Username {
+		if !hasPermission(authPayload.Role, permissionViewAnyAccount) {
+			err := errors.New("cannot list the accounts of another user")
+
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		owner = req.Owner
+	}
+
 	arg := db.ListAccountsParams{
-		Owner:  authPayload.Username,
+		Owner:  owner,
 		Limit:  req.PageSize,
 		Offset: (req.PageID - 1) * req.PageSize,
 	}
@@ -122,3 +159,73 @@ func (server *Server) getAccounts(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, accounts)
 }
+
+func (server *Server) listAccountEntries(ctx *gin.Context) {
+	var uri getAccountByIDRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listAccountEntriesRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.viewableAccount(ctx, uri.ID); !ok {
+		return
+	}
+
+	arg := db.ListEntriesParams{
+		AccountID: uri.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	}
+
+	entries, err := server.store.ListEntries(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+func (server *Server) getAccountStatement(ctx *gin.Context) {
+	var uri getAccountByIDRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getAccountStatementRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.viewableAccount(ctx, uri.ID); !ok {
+		return
+	}
+
+	arg := db.AccountStatementTxParams{
+		AccountID: uri.ID,
+		From:      req.From,
+		To:        req.To,
+	}
+
+	statement, err := server.store.AccountStatementTx(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statement)
+}
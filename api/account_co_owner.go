@@ -0,0 +1,153 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/token"
+	"github.com/lib/pq"
+	"net/http"
+)
+
+type (
+	addAccountCoOwnerRequest struct {
+		Username string `json:"username" binding:"required,alphanum"`
+	}
+
+	removeAccountCoOwnerRequest struct {
+		ID       int64  `uri:"id" binding:"required,min=1"`
+		Username string `uri:"username" binding:"required,alphanum"`
+	}
+)
+
+// ownedAccount finds the account and checks that the authenticated user owns it. Only the owner manages the
+// co-owners, co-owners cannot add other co-owners. The error response is written if the account is not owned.
+func (server *Server) ownedAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	account, exists := server.accountExists(ctx, accountID)
+
+	if !exists {
+		return account, false
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if account.Owner != authPayload.Username {
+		err := errors.New("account does not belong to the authenticated user")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
+
+func (server *Server) listAccountCoOwners(ctx *gin.Context) {
+	var req getAccountByIDRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.viewableAccount(ctx, req.ID)
+
+	if !ok {
+		return
+	}
+
+	coOwners, err := server.store.ListAccountCoOwners(ctx, int64(account.ID))
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, coOwners)
+}
+
+func (server *Server) addAccountCoOwner(ctx *gin.Context) {
+	var uri getAccountByIDRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req addAccountCoOwnerRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.ownedAccount(ctx, uri.ID)
+
+	if !ok {
+		return
+	}
+
+	// The owner would otherwise approve the transfers they requested themselves
+	if req.Username == account.Owner {
+		err := errors.New("the owner cannot be a co-owner of their own account")
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	coOwner, err := server.store.CreateAccountCoOwner(ctx, db.CreateAccountCoOwnerParams{
+		AccountID: int64(account.ID),
+		Username:  req.Username,
+	})
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation":
+				ctx.JSON(http.StatusNotFound, errorResponse(errors.New("user not found")))
+				return
+			case "unique_violation":
+				ctx.JSON(http.StatusConflict, errorResponse(errors.New("user is already a co-owner of the account")))
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, coOwner)
+}
+
+func (server *Server) removeAccountCoOwner(ctx *gin.Context) {
+	var req removeAccountCoOwnerRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.ownedAccount(ctx, req.ID)
+
+	if !ok {
+		return
+	}
+
+	coOwner, err := server.store.DeleteAccountCoOwner(ctx, db.DeleteAccountCoOwnerParams{
+		AccountID: int64(account.ID),
+		Username:  req.Username,
+	})
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, coOwner)
+}
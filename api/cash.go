@@ -0,0 +1,182 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/token"
+	"net/http"
+)
+
+type (
+	cashRequest struct {
+		Amount   int64  `json:"amount" binding:"required,gt=0"`
+		Currency string `json:"currency" binding:"required,currency"`
+		Memo     string `json:"memo" binding:"max=140"`
+	}
+
+	// cashResponse shows the customer's side of a deposit or a withdrawal
+	cashResponse struct {
+		Transfer db.Transfer `json:"transfer"`
+		Account  db.Account  `json:"account"`
+		Entry    db.Entry    `json:"entry"`
+	}
+)
+
+func (server *Server) depositCash(ctx *gin.Context) {
+	server.postCash(ctx, true)
+}
+
+func (server *Server) withdrawCash(ctx *gin.Context) {
+	server.postCash(ctx, false)
+}
+
+// hashCashRequest returns the hash stored with the idempotency key of a deposit awaiting approval
+func hashCashRequest(accountID int64, req cashRequest) string {
+	data, _ := json.Marshal(struct {
+		AccountID int64 `json:"account_id"`
+		cashRequest
+	}{accountID, req})
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// postCash runs a deposit or a withdrawal on the account in the uri on behalf of the authenticated staff member
+func (server *Server) postCash(ctx *gin.Context, deposit bool) {
+	var uri getAccountByIDRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req cashRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// The currency is repeated by the client so that cash is not posted to an account in another currency by mistake
+	account, isValid := server.isValidAccount(ctx, uri.ID, req.Currency)
+
+	if !isValid {
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if deposit && account.Owner == authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrCashOnOwnAccount))
+		return
+	}
+
+	// Large deposits are only made once another user approves them, like large transfers
+	if threshold := server.config.TransferApprovalThreshold; deposit && threshold > 0 && req.Amount > threshold {
+		server.requestDepositApproval(ctx, account, req, authPayload.Username)
+		return
+	}
+
+	arg := db.CashTxParams{
+		AccountID: uri.ID,
+		Amount:    req.Amount,
+		Memo:      req.Memo,
+		PostedBy:  authPayload.Username,
+	}
+
+	var (
+		result db.TransferTxResult
+		err    error
+		rsp    cashResponse
+	)
+
+	if deposit {
+		result, err = server.store.DepositTx(ctx, arg)
+		rsp = cashResponse{Transfer: result.Transfer, Account: result.ToAccount, Entry: result.ToEntry}
+	} else {
+		result, err = server.store.WithdrawTx(ctx, arg)
+		rsp = cashResponse{Transfer: result.Transfer, Account: result.FromAccount, Entry: result.FromEntry}
+	}
+
+	if err != nil {
+		cashErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// cashErrorResponse responds with the status matching the reason the store could not post the cash
+func cashErrorResponse(ctx *gin.Context, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+
+	if errors.Is(err, db.ErrCashOnOwnAccount) {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrCashOnSettlementAccount) {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
+
+// requestDepositApproval holds the deposit as a transfer from the settlement account until a user allowed to approve
+// transfers decides on it
+func (server *Server) requestDepositApproval(ctx *gin.Context, account db.Account, req cashRequest, username string) {
+	settlementAccount, err := server.store.GetSettlementAccount(ctx, account.Currency)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = db.ErrSettlementAccountNotFound
+		}
+
+		cashErrorResponse(ctx, err)
+		return
+	}
+
+	if settlementAccount.ID == account.ID {
+		cashErrorResponse(ctx, db.ErrCashOnSettlementAccount)
+		return
+	}
+
+	// The staff member is kept in the metadata like DepositTx does
+	metadata, err := json.Marshal(map[string]string{"posted_by": username})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: int64(settlementAccount.ID),
+		ToAccountID:   int64(account.ID),
+		Amount:        req.Amount,
+		Memo:          req.Memo,
+		Metadata:      metadata,
+	}
+
+	server.requestTransferApproval(ctx, arg, username, hashCashRequest(int64(account.ID), req))
+}
+
+func (server *Server) listSettlementAccounts(ctx *gin.Context) {
+	accounts, err := server.store.ListSettlementAccounts(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accounts)
+}
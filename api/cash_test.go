@@ -0,0 +1,394 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPostCash(t *testing.T) {
+	user, _ := randomUser(t)
+
+	account := createRandomAccount(user.Username)
+	account.Currency = util.USD
+
+	settlementAccount := createRandomAccount("bank_settlement")
+	settlementAccount.Currency = util.USD
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "TellerDeposits",
+			action: "deposits",
+			body: gin.H{
+				"amount":   500,
+				"currency": util.USD,
+				"memo":     "Cash at branch",
+			},
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CashTxParams{
+					AccountID: int64(account.ID),
+					Amount:    500,
+					Memo:      "Cash at branch",
+					PostedBy:  "staff",
+				}
+
+				deposited := account
+				deposited.Balance += 500
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer:    db.Transfer{ID: 1, Amount: 500},
+						FromAccount: settlementAccount,
+						ToAccount:   deposited,
+						ToEntry:     db.Entry{ID: 2, AccountID: int64(account.ID), Amount: 500},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got cashResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, account.ID, got.Account.ID)
+				require.Equal(t, account.Balance+500, got.Account.Balance)
+				require.Equal(t, int64(500), got.Entry.Amount)
+			},
+		},
+		{
+			name:   "AdminWithdraws",
+			action: "withdrawals",
+			body: gin.H{
+				"amount":   100,
+				"currency": util.USD,
+			},
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CashTxParams{
+					AccountID: int64(account.ID),
+					Amount:    100,
+					PostedBy:  "staff",
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferTxResult{
+						FromAccount: account,
+						ToAccount:   settlementAccount,
+						FromEntry:   db.Entry{AccountID: int64(account.ID), Amount: -100},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got cashResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, account.ID, got.Account.ID)
+				require.Equal(t, int64(-100), got.Entry.Amount)
+			},
+		},
+		{
+			name:   "DepositorForbidden",
+			action: "deposits",
+			body: gin.H{
+				"amount":   500,
+				"currency": util.USD,
+			},
+			role: util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "BankerForbidden",
+			action: "withdrawals",
+			body: gin.H{
+				"amount":   500,
+				"currency": util.USD,
+			},
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidAmount",
+			action: "deposits",
+			body: gin.H{
+				"amount":   0,
+				"currency": util.USD,
+			},
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "CurrencyMismatch",
+			action: "deposits",
+			body: gin.H{
+				"amount":   500,
+				"currency": util.EUR,
+			},
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "AccountNotFound",
+			action: "deposits",
+			body: gin.H{
+				"amount":   500,
+				"currency": util.USD,
+			},
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InsufficientFunds",
+			action: "withdrawals",
+			body: gin.H{
+				"amount":   500,
+				"currency": util.USD,
+			},
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:   "SettlementAccount",
+			action: "deposits",
+			body: gin.H{
+				"amount":   500,
+				"currency": util.USD,
+			},
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrCashOnSettlementAccount)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:   "TellerDepositsIntoOwnAccount",
+			action: "deposits",
+			body: gin.H{
+				"amount":   500,
+				"currency": util.USD,
+			},
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				ownAccount := account
+				ownAccount.Owner = "staff"
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(ownAccount, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "LargeDepositNeedsApproval",
+			action: "deposits",
+			body: gin.H{
+				"amount":   5000,
+				"currency": util.USD,
+				"memo":     "Cash at branch",
+			},
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetSettlementAccount(gomock.Any(), gomock.Eq(util.USD)).
+					Times(1).
+					Return(settlementAccount, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+
+				store.EXPECT().
+					RequestTransferApprovalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RequestTransferApprovalTxParams) (
+						db.RequestTransferApprovalTxResult, error) {
+
+						// The deposit is held as a transfer from the settlement account
+						require.Equal(t, int64(settlementAccount.ID), arg.FromAccountID)
+						require.Equal(t, int64(account.ID), arg.ToAccountID)
+						require.Equal(t, int64(5000), arg.Amount)
+						require.Equal(t, "Cash at branch", arg.Memo)
+						require.JSONEq(t, `{"posted_by":"staff"}`, string(arg.Metadata))
+						require.Equal(t, "staff", arg.RequestedBy)
+
+						return db.RequestTransferApprovalTxResult{
+							Approval: db.TransferApproval{ID: 1, Status: util.TransferApprovalPending},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			action: "deposits",
+			body: gin.H{
+				"amount":   500,
+				"currency": util.USD,
+			},
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrSettlementAccountNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			server.config.TransferApprovalThreshold = 1000
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/v1/accounts/%d/%s", account.ID, tc.action)
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "staff", tc.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListSettlementAccounts(t *testing.T) {
+	accounts := []db.Account{
+		createRandomAccount("bank_settlement"),
+		createRandomAccount("bank_settlement"),
+	}
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Teller",
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListSettlementAccounts(gomock.Any()).Times(1).Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccounts(t, recorder.Body, accounts)
+			},
+		},
+		{
+			name: "DepositorForbidden",
+			role: util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListSettlementAccounts(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/settlement-accounts", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "staff", tc.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"net/http"
+)
+
+type (
+	exchangeRateURIRequest struct {
+		FromCurrency string `uri:"from_currency" binding:"required,currency"`
+		ToCurrency   string `uri:"to_currency" binding:"required,currency,nefield=FromCurrency"`
+	}
+
+	upsertExchangeRateRequest struct {
+		Rate string `json:"rate" binding:"required,exchange_rate"`
+	}
+)
+
+func (server *Server) listExchangeRates(ctx *gin.Context) {
+	rates, err := server.store.ListExchangeRates(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rates)
+}
+
+func (server *Server) upsertExchangeRate(ctx *gin.Context) {
+	var uri exchangeRateURIRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req upsertExchangeRateRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.UpsertExchangeRateParams{
+		FromCurrency: uri.FromCurrency,
+		ToCurrency:   uri.ToCurrency,
+		Rate:         req.Rate,
+	}
+
+	rate, err := server.store.UpsertExchangeRate(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rate)
+}
+
+func (server *Server) deleteExchangeRate(ctx *gin.Context) {
+	var uri exchangeRateURIRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.DeleteExchangeRateParams{
+		FromCurrency: uri.FromCurrency,
+		ToCurrency:   uri.ToCurrency,
+	}
+
+	if _, err := server.store.DeleteExchangeRate(ctx, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,296 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func randomExchangeRate() db.ExchangeRate {
+	return db.ExchangeRate{
+		FromCurrency: util.USD,
+		ToCurrency:   util.EUR,
+		Rate:         "0.92",
+		UpdatedAt:    time.Now(),
+	}
+}
+
+func TestListExchangeRates(t *testing.T) {
+	rates := []db.ExchangeRate{randomExchangeRate()}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "StatusOK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListExchangeRates(gomock.Any()).Times(1).Return(rates, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []db.ExchangeRate
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got, 1)
+				require.Equal(t, rates[0].Rate, got[0].Rate)
+			},
+		},
+		{
+			name: "InternalServerError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListExchangeRates(gomock.Any()).Times(1).Return([]db.ExchangeRate{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/exchange-rates", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpsertExchangeRate(t *testing.T) {
+	rate := randomExchangeRate()
+
+	testCases := []struct {
+		name          string
+		fromCurrency  string
+		toCurrency    string
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:         "StatusOK",
+			fromCurrency: rate.FromCurrency,
+			toCurrency:   rate.ToCurrency,
+			body:         gin.H{"rate": rate.Rate},
+			role:         util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertExchangeRateParams{
+					FromCurrency: rate.FromCurrency,
+					ToCurrency:   rate.ToCurrency,
+					Rate:         rate.Rate,
+				}
+
+				store.EXPECT().UpsertExchangeRate(gomock.Any(), gomock.Eq(arg)).Times(1).Return(rate, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:         "Forbidden",
+			fromCurrency: rate.FromCurrency,
+			toCurrency:   rate.ToCurrency,
+			body:         gin.H{"rate": rate.Rate},
+			role:         util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:         "SameCurrency",
+			fromCurrency: util.USD,
+			toCurrency:   util.USD,
+			body:         gin.H{"rate": "1"},
+			role:         util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "UnsupportedCurrency",
+			fromCurrency: util.USD,
+			toCurrency:   "XYZ",
+			body:         gin.H{"rate": rate.Rate},
+			role:         util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "NegativeRate",
+			fromCurrency: rate.FromCurrency,
+			toCurrency:   rate.ToCurrency,
+			body:         gin.H{"rate": "-0.92"},
+			role:         util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "ZeroRate",
+			fromCurrency: rate.FromCurrency,
+			toCurrency:   rate.ToCurrency,
+			body:         gin.H{"rate": "0.000"},
+			role:         util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "InternalServerError",
+			fromCurrency: rate.FromCurrency,
+			toCurrency:   rate.ToCurrency,
+			body:         gin.H{"rate": rate.Rate},
+			role:         util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertExchangeRate(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ExchangeRate{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/v1/exchange-rates/%s/%s", testCase.fromCurrency, testCase.toCurrency)
+
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteExchangeRate(t *testing.T) {
+	rate := randomExchangeRate()
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "StatusNoContent",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DeleteExchangeRateParams{
+					FromCurrency: rate.FromCurrency,
+					ToCurrency:   rate.ToCurrency,
+				}
+
+				store.EXPECT().DeleteExchangeRate(gomock.Any(), gomock.Eq(arg)).Times(1).Return(rate, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "Forbidden",
+			role: util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteExchangeRate(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ExchangeRate{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/exchange-rates/%s/%s", rate.FromCurrency, rate.ToCurrency)
+
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"net/http"
+)
+
+type (
+	feeScheduleURIRequest struct {
+		Currency string `uri:"currency" binding:"required,currency"`
+	}
+
+	upsertFeeScheduleRequest struct {
+		Flat                  int64  `json:"flat" binding:"min=0"`
+		Percentage            string `json:"percentage" binding:"omitempty,percentage"`
+		MinFee                int64  `json:"min_fee" binding:"min=0"`
+		MaxFee                int64  `json:"max_fee" binding:"min=0"`
+		FreeTransfersPerMonth int32  `json:"free_transfers_per_month" binding:"min=0"`
+	}
+)
+
+func (server *Server) listFeeSchedules(ctx *gin.Context) {
+	schedules, err := server.store.ListFeeSchedules(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedules)
+}
+
+func (server *Server) upsertFeeSchedule(ctx *gin.Context) {
+	var uri feeScheduleURIRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req upsertFeeScheduleRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// A maximum of zero means the fee has no maximum
+	if req.MaxFee > 0 && req.MaxFee < req.MinFee {
+		err := errors.New("max_fee must not be lower than min_fee")
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Percentage == "" {
+		req.Percentage = "0"
+	}
+
+	arg := db.UpsertFeeScheduleParams{
+		Currency:              uri.Currency,
+		Flat:                  req.Flat,
+		Percentage:            req.Percentage,
+		MinFee:                req.MinFee,
+		MaxFee:                req.MaxFee,
+		FreeTransfersPerMonth: req.FreeTransfersPerMonth,
+	}
+
+	schedule, err := server.store.UpsertFeeSchedule(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+func (server *Server) deleteFeeSchedule(ctx *gin.Context) {
+	var uri feeScheduleURIRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, err := server.store.DeleteFeeSchedule(ctx, uri.Currency); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,331 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func randomFeeSchedule() db.FeeSchedule {
+	return db.FeeSchedule{
+		Currency:              util.USD,
+		Flat:                  25,
+		Percentage:            "1.5",
+		MinFee:                50,
+		MaxFee:                500,
+		FreeTransfersPerMonth: 3,
+		UpdatedAt:             time.Now(),
+	}
+}
+
+func TestListFeeSchedules(t *testing.T) {
+	schedules := []db.FeeSchedule{randomFeeSchedule()}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "StatusOK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFeeSchedules(gomock.Any()).Times(1).Return(schedules, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotSchedules []db.FeeSchedule
+
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotSchedules)
+				require.NoError(t, err)
+				require.Len(t, gotSchedules, 1)
+				require.Equal(t, schedules[0].Percentage, gotSchedules[0].Percentage)
+			},
+		},
+		{
+			name: "InternalServerError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFeeSchedules(gomock.Any()).Times(1).Return([]db.FeeSchedule{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/fee-schedules", nil)
+			require.NoError(t, err)
+
+			// Customers can see the fees they will be charged
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", util.DepositorRole,
+				time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpsertFeeSchedule(t *testing.T) {
+	schedule := randomFeeSchedule()
+
+	body := gin.H{
+		"flat":                     schedule.Flat,
+		"percentage":               schedule.Percentage,
+		"min_fee":                  schedule.MinFee,
+		"max_fee":                  schedule.MaxFee,
+		"free_transfers_per_month": schedule.FreeTransfersPerMonth,
+	}
+
+	testCases := []struct {
+		name          string
+		currency      string
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "StatusOK",
+			currency: util.USD,
+			body:     body,
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertFeeScheduleParams{
+					Currency:              schedule.Currency,
+					Flat:                  schedule.Flat,
+					Percentage:            schedule.Percentage,
+					MinFee:                schedule.MinFee,
+					MaxFee:                schedule.MaxFee,
+					FreeTransfersPerMonth: schedule.FreeTransfersPerMonth,
+				}
+
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Eq(arg)).Times(1).Return(schedule, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "FlatFeeOnly",
+			currency: util.USD,
+			body:     gin.H{"flat": 25},
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertFeeScheduleParams{
+					Currency:   util.USD,
+					Flat:       25,
+					Percentage: "0",
+				}
+
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Eq(arg)).Times(1).Return(schedule, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Forbidden",
+			currency: util.USD,
+			body:     body,
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "UnsupportedCurrency",
+			currency: "XYZ",
+			body:     body,
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "PercentageAboveHundred",
+			currency: util.USD,
+			body:     gin.H{"percentage": "100.5"},
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidPercentage",
+			currency: util.USD,
+			body:     gin.H{"percentage": "-1"},
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "MaxFeeBelowMinFee",
+			currency: util.USD,
+			body:     gin.H{"min_fee": 50, "max_fee": 10},
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NegativeFreeTransfers",
+			currency: util.USD,
+			body:     gin.H{"free_transfers_per_month": -1},
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalServerError",
+			currency: util.USD,
+			body:     body,
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.FeeSchedule{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/v1/fee-schedules/%s", testCase.currency)
+
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteFeeSchedule(t *testing.T) {
+	schedule := randomFeeSchedule()
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "StatusNoContent",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteFeeSchedule(gomock.Any(), gomock.Eq(schedule.Currency)).Times(1).
+					Return(schedule, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "Forbidden",
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteFeeSchedule(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.FeeSchedule{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/fee-schedules/%s", schedule.Currency)
+
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/risk"
+	"github.com/jwambugu/go-simple-bank-class/token"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"net/http"
+	"time"
+)
+
+// defaultMoneyRequestExpiry is how long a money request stays pending when the client does not set expires_at
+const defaultMoneyRequestExpiry = 7 * 24 * time.Hour
+
+type (
+	createMoneyRequestRequest struct {
+		Payer     string    `json:"payer" binding:"required,alphanum"`
+		Amount    int64     `json:"amount" binding:"required,gt=0"`
+		Currency  string    `json:"currency" binding:"required,currency"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	getMoneyRequestRequest struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+
+	listMoneyRequestsRequest struct {
+		Direction string `form:"direction" binding:"omitempty,oneof=incoming outgoing"`
+		Status    string `form:"status" binding:"omitempty,oneof=pending accepted declined cancelled expired"`
+		PageID    int32  `form:"page_id" binding:"required,min=1"`
+		PageSize  int32  `form:"page_size" binding:"required,min=5,max=10"`
+	}
+
+	moneyRequestResponse struct {
+		ID         int64     `json:"id"`
+		Requester  string    `json:"requester"`
+		Payer      string    `json:"payer"`
+		Amount     int64     `json:"amount"`
+		Currency   string    `json:"currency"`
+		Status     string    `json:"status"`
+		ExpiresAt  time.Time `json:"expiresAt"`
+		TransferID *int64    `json:"transferID"`
+		CreatedAt  time.Time `json:"createdAt"`
+	}
+
+	// acceptMoneyRequestResponse is what the sender of any other transfer sees, it leaves out the account of the
+	// requester since the payer only addressed them by username
+	acceptMoneyRequestResponse struct {
+		sentTransferResponse
+		MoneyRequest moneyRequestResponse `json:"money_request"`
+	}
+)
+
+func newMoneyRequestResponse(request db.MoneyRequest) moneyRequestResponse {
+	return moneyRequestResponse{
+		ID:         request.ID,
+		Requester:  request.Requester,
+		Payer:      request.Payer,
+		Amount:     request.Amount,
+		Currency:   request.Currency,
+		Status:     request.Status,
+		ExpiresAt:  request.ExpiresAt,
+		TransferID: nullableInt64(request.TransferID),
+		CreatedAt:  request.CreatedAt,
+	}
+}
+
+func (server *Server) createMoneyRequest(ctx *gin.Context) {
+	var req createMoneyRequestRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	expiresAt := req.ExpiresAt
+
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(defaultMoneyRequestExpiry)
+	} else if !expiresAt.After(time.Now()) {
+		err := errors.New("expires_at must be in the future")
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if req.Payer == authPayload.Username {
+		err := errors.New("cannot request money from yourself")
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// The requester must be able to receive the money once the request is accepted
+	_, err := server.store.GetRecipientAccount(ctx, db.GetRecipientAccountParams{
+		Username: authPayload.Username,
+		Currency: req.Currency,
+	})
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := errors.New("you do not have an account in the requested currency")
+
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if _, err := server.store.GetUser(ctx, req.Payer); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateMoneyRequestParams{
+		Requester: authPayload.Username,
+		Payer:     req.Payer,
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		ExpiresAt: expiresAt,
+	}
+
+	request, err := server.store.CreateMoneyRequest(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newMoneyRequestResponse(request))
+}
+
+func (server *Server) listMoneyRequests(ctx *gin.Context) {
+	var req listMoneyRequestsRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Payers see the requests they have to pay by default
+	if req.Direction == "" {
+		req.Direction = "incoming"
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.ListMoneyRequestsParams{
+		Direction: req.Direction,
+		Username:  authPayload.Username,
+		Status:    req.Status,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	}
+
+	requests, err := server.store.ListMoneyRequests(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]moneyRequestResponse, len(requests))
+
+	for i, request := range requests {
+		rsp[i] = newMoneyRequestResponse(request)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// moneyRequestExists returns the money request in the uri if the authenticated user is its requester or its payer
+func (server *Server) moneyRequestExists(ctx *gin.Context) (db.MoneyRequest, bool) {
+	var req getMoneyRequestRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.MoneyRequest{}, false
+	}
+
+	request, err := server.store.GetMoneyRequest(ctx, req.ID)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return request, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return request, false
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if request.Requester != authPayload.Username && request.Payer != authPayload.Username {
+		err := errors.New("money request does not belong to the authenticated user")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return request, false
+	}
+
+	return request, true
+}
+
+func (server *Server) getMoneyRequest(ctx *gin.Context) {
+	request, ok := server.moneyRequestExists(ctx)
+
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newMoneyRequestResponse(request))
+}
+
+// moneyRequestAccount returns the account of the user in the currency of the money request
+func (server *Server) moneyRequestAccount(ctx *gin.Context, username, currency string) (db.Account, bool) {
+	account, err := server.store.GetRecipientAccount(ctx, db.GetRecipientAccountParams{
+		Username: username,
+		Currency: currency,
+	})
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := errors.New(username + " does not have an account in " + currency)
+
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return account, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
+
+func (server *Server) acceptMoneyRequest(ctx *gin.Context) {
+	request, ok := server.moneyRequestExists(ctx)
+
+	if !ok {
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if request.Payer != authPayload.Username {
+		err := errors.New("only the payer can accept a money request")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if request.Status != util.MoneyRequestPending {
+		ctx.JSON(http.StatusConflict, errorResponse(db.ErrMoneyRequestNotPending))
+		return
+	}
+
+	fromAccount, ok := server.moneyRequestAccount(ctx, request.Payer, request.Currency)
+
+	if !ok {
+		return
+	}
+
+	toAccount, ok := server.moneyRequestAccount(ctx, request.Requester, request.Currency)
+
+	if !ok {
+		return
+	}
+
+	// The payer can make a transfer instead, which is held until another user approves it
+	if !server.isBelowApprovalThreshold(ctx, request.Amount, "made by accepting a money request") {
+		return
+	}
+
+	isAllowed := server.assessTransfer(ctx, risk.Transfer{
+		Username:      authPayload.Username,
+		FromAccountID: int64(fromAccount.ID),
+		ToAccountID:   int64(toAccount.ID),
+		Amount:        request.Amount,
+		Currency:      fromAccount.Currency,
+	})
+
+	if !isAllowed {
+		return
+	}
+
+	result, err := server.store.AcceptMoneyRequestTx(ctx, db.AcceptMoneyRequestTxParams{
+		ID:            request.ID,
+		FromAccountID: int64(fromAccount.ID),
+		ToAccountID:   int64(toAccount.ID),
+	})
+
+	if err != nil {
+		var limitErr *db.TransferLimitError
+
+		if errors.As(err, &limitErr) {
+			ctx.JSON(http.StatusUnprocessableEntity, transferLimitErrorResponse(limitErr))
+			return
+		}
+
+		if errors.Is(err, db.ErrMoneyRequestNotPending) || errors.Is(err, db.ErrMoneyRequestExpired) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, acceptMoneyRequestResponse{
+		sentTransferResponse: sentTransferResponse{
+			Transfer:    result.Transfer,
+			FromAccount: result.FromAccount,
+			FromEntry:   result.FromEntry,
+			Fee:         result.Fee,
+		},
+		MoneyRequest: newMoneyRequestResponse(result.MoneyRequest),
+	})
+}
+
+// closeMoneyRequest moves a pending money request to the given status on behalf of the user allowed to do so
+func (server *Server) closeMoneyRequest(ctx *gin.Context, status string, allowed func(db.MoneyRequest) bool) {
+	request, ok := server.moneyRequestExists(ctx)
+
+	if !ok {
+		return
+	}
+
+	if !allowed(request) {
+		err := errors.New("money request cannot be " + status + " by the authenticated user")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	request, err := server.store.CloseMoneyRequest(ctx, db.CloseMoneyRequestParams{
+		Status: status,
+		ID:     request.ID,
+	})
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrMoneyRequestNotPending))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newMoneyRequestResponse(request))
+}
+
+func (server *Server) declineMoneyRequest(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	server.closeMoneyRequest(ctx, util.MoneyRequestDeclined, func(request db.MoneyRequest) bool {
+		return request.Payer == authPayload.Username
+	})
+}
+
+func (server *Server) cancelMoneyRequest(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	server.closeMoneyRequest(ctx, util.MoneyRequestCancelled, func(request db.MoneyRequest) bool {
+		return request.Requester == authPayload.Username
+	})
+}
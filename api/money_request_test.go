@@ -0,0 +1,643 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func createRandomMoneyRequest(requester, payer string) db.MoneyRequest {
+	return db.MoneyRequest{
+		ID:        util.RandomInt(1, 1000),
+		Requester: requester,
+		Payer:     payer,
+		Amount:    util.RandomMoney(),
+		Currency:  util.USD,
+		Status:    util.MoneyRequestPending,
+		ExpiresAt: time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+	}
+}
+
+func TestCreateMoneyRequest(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+
+	requesterAccount := createRandomAccount(requester.Username)
+	requesterAccount.Currency = util.USD
+
+	expiresAt := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"payer":      payer.Username,
+				"amount":     100,
+				"currency":   util.USD,
+				"expires_at": expiresAt,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateMoneyRequestParams{
+					Requester: requester.Username,
+					Payer:     payer.Username,
+					Amount:    100,
+					Currency:  util.USD,
+					ExpiresAt: expiresAt,
+				}
+
+				store.EXPECT().
+					GetRecipientAccount(gomock.Any(), gomock.Eq(db.GetRecipientAccountParams{
+						Username: requester.Username,
+						Currency: util.USD,
+					})).
+					Times(1).
+					Return(requesterAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+				store.EXPECT().
+					CreateMoneyRequest(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.MoneyRequest{ID: 1, Requester: arg.Requester, Payer: arg.Payer, Status: "pending"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got moneyRequestResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, payer.Username, got.Payer)
+				require.Nil(t, got.TransferID)
+			},
+		},
+		{
+			name: "DefaultExpiry",
+			body: gin.H{
+				"payer":    payer.Username,
+				"amount":   100,
+				"currency": util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetRecipientAccount(gomock.Any(), gomock.Any()).Times(1).Return(requesterAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+				store.EXPECT().
+					CreateMoneyRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateMoneyRequestParams) (db.MoneyRequest, error) {
+						require.WithinDuration(t, time.Now().Add(defaultMoneyRequestExpiry), arg.ExpiresAt, time.Minute)
+
+						return db.MoneyRequest{ID: 1}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiresInThePast",
+			body: gin.H{
+				"payer":      payer.Username,
+				"amount":     100,
+				"currency":   util.USD,
+				"expires_at": time.Now().Add(-time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateMoneyRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "RequestFromSelf",
+			body: gin.H{
+				"payer":    requester.Username,
+				"amount":   100,
+				"currency": util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateMoneyRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			body: gin.H{
+				"payer":    payer.Username,
+				"amount":   -1,
+				"currency": util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateMoneyRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "RequesterHasNoAccountInCurrency",
+			body: gin.H{
+				"payer":    payer.Username,
+				"amount":   100,
+				"currency": util.EUR,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRecipientAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().CreateMoneyRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PayerNotFound",
+			body: gin.H{
+				"payer":    payer.Username,
+				"amount":   100,
+				"currency": util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetRecipientAccount(gomock.Any(), gomock.Any()).Times(1).Return(requesterAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateMoneyRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/v1/money-requests", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, requester.Username,
+				util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListMoneyRequests(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+
+	requests := []db.MoneyRequest{
+		createRandomMoneyRequest(requester.Username, payer.Username),
+		createRandomMoneyRequest(requester.Username, payer.Username),
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "IncomingByDefault",
+			query: "page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListMoneyRequestsParams{
+					Direction: "incoming",
+					Username:  payer.Username,
+					Limit:     5,
+					Offset:    0,
+				}
+
+				store.EXPECT().ListMoneyRequests(gomock.Any(), gomock.Eq(arg)).Times(1).Return(requests, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []moneyRequestResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got, len(requests))
+			},
+		},
+		{
+			name:  "OutgoingPending",
+			query: "direction=outgoing&status=pending&page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListMoneyRequestsParams{
+					Direction: "outgoing",
+					Username:  payer.Username,
+					Status:    util.MoneyRequestPending,
+					Limit:     5,
+					Offset:    5,
+				}
+
+				store.EXPECT().ListMoneyRequests(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.MoneyRequest{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidDirection",
+			query: "direction=sideways&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListMoneyRequests(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidStatus",
+			query: "status=paid&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListMoneyRequests(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/money-requests?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, payer.Username,
+				util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateMoneyRequestStatus(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	otherUser, _ := randomUser(t)
+
+	requesterAccount := createRandomAccount(requester.Username)
+	requesterAccount.Currency = util.USD
+
+	payerAccount := createRandomAccount(payer.Username)
+	payerAccount.Currency = util.USD
+	payerAccount.ID = requesterAccount.ID + 1
+
+	moneyRequest := createRandomMoneyRequest(requester.Username, payer.Username)
+
+	acceptedRequest := moneyRequest
+	acceptedRequest.Status = util.MoneyRequestAccepted
+	acceptedRequest.TransferID = sql.NullInt64{Int64: 1, Valid: true}
+
+	acceptArg := db.AcceptMoneyRequestTxParams{
+		ID:            moneyRequest.ID,
+		FromAccountID: int64(payerAccount.ID),
+		ToAccountID:   int64(requesterAccount.ID),
+	}
+
+	stubAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().
+			GetRecipientAccount(gomock.Any(), gomock.Eq(db.GetRecipientAccountParams{
+				Username: payer.Username,
+				Currency: util.USD,
+			})).
+			Times(1).
+			Return(payerAccount, nil)
+		store.EXPECT().
+			GetRecipientAccount(gomock.Any(), gomock.Eq(db.GetRecipientAccountParams{
+				Username: requester.Username,
+				Currency: util.USD,
+			})).
+			Times(1).
+			Return(requesterAccount, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		method        string
+		action        string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "GetAsRequester",
+			method:   http.MethodGet,
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "GetAsOtherUser",
+			method:   http.MethodGet,
+			username: otherUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			method:   http.MethodGet,
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).
+					Times(1).
+					Return(db.MoneyRequest{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "Accept",
+			method:   http.MethodPost,
+			action:   "/accept",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+				stubAccounts(store)
+				store.EXPECT().
+					AcceptMoneyRequestTx(gomock.Any(), gomock.Eq(acceptArg)).
+					Times(1).
+					Return(db.AcceptMoneyRequestTxResult{
+						TransferTxResult: db.TransferTxResult{
+							Transfer:    db.Transfer{ID: 1},
+							FromAccount: payerAccount,
+							ToAccount:   requesterAccount,
+							Fee:         db.FeeBreakdown{Currency: payerAccount.Currency, Flat: 25, Total: 25},
+						},
+						MoneyRequest: acceptedRequest,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got map[string]json.RawMessage
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+
+				// The payer must not see the account of the requester
+				require.NotContains(t, got, "to_account")
+				require.NotContains(t, got, "to_entry")
+				require.Contains(t, got, "from_account")
+				require.Contains(t, got, "from_entry")
+
+				// The payer sees the fee they were charged, like for any other transfer
+				var fee db.FeeBreakdown
+				err = json.Unmarshal(got["fee"], &fee)
+				require.NoError(t, err)
+				require.Equal(t, int64(25), fee.Total)
+
+				var request moneyRequestResponse
+				err = json.Unmarshal(got["money_request"], &request)
+				require.NoError(t, err)
+				require.Equal(t, util.MoneyRequestAccepted, request.Status)
+				require.Equal(t, int64(1), *request.TransferID)
+			},
+		},
+		{
+			name:     "AcceptAboveApprovalThreshold",
+			method:   http.MethodPost,
+			action:   "/accept",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				largeRequest := moneyRequest
+				largeRequest.Amount = 1001
+
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(largeRequest, nil)
+				stubAccounts(store)
+				store.EXPECT().AcceptMoneyRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "AcceptAsRequester",
+			method:   http.MethodPost,
+			action:   "/accept",
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+				store.EXPECT().AcceptMoneyRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "AcceptClosed",
+			method:   http.MethodPost,
+			action:   "/accept",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(acceptedRequest, nil)
+				store.EXPECT().AcceptMoneyRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "AcceptPayerHasNoAccountInCurrency",
+			method:   http.MethodPost,
+			action:   "/accept",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+				store.EXPECT().
+					GetRecipientAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().AcceptMoneyRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "AcceptExpired",
+			method:   http.MethodPost,
+			action:   "/accept",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+				stubAccounts(store)
+				store.EXPECT().
+					AcceptMoneyRequestTx(gomock.Any(), gomock.Eq(acceptArg)).
+					Times(1).
+					Return(db.AcceptMoneyRequestTxResult{}, db.ErrMoneyRequestExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "AcceptInsufficientFunds",
+			method:   http.MethodPost,
+			action:   "/accept",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+				stubAccounts(store)
+				store.EXPECT().
+					AcceptMoneyRequestTx(gomock.Any(), gomock.Eq(acceptArg)).
+					Times(1).
+					Return(db.AcceptMoneyRequestTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "Decline",
+			method:   http.MethodPost,
+			action:   "/decline",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				declinedRequest := moneyRequest
+				declinedRequest.Status = util.MoneyRequestDeclined
+
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+				store.EXPECT().
+					CloseMoneyRequest(gomock.Any(), gomock.Eq(db.CloseMoneyRequestParams{
+						Status: util.MoneyRequestDeclined,
+						ID:     moneyRequest.ID,
+					})).
+					Times(1).
+					Return(declinedRequest, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got moneyRequestResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, util.MoneyRequestDeclined, got.Status)
+			},
+		},
+		{
+			name:     "DeclineAsRequester",
+			method:   http.MethodPost,
+			action:   "/decline",
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+				store.EXPECT().CloseMoneyRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "Cancel",
+			method:   http.MethodDelete,
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+				store.EXPECT().
+					CloseMoneyRequest(gomock.Any(), gomock.Eq(db.CloseMoneyRequestParams{
+						Status: util.MoneyRequestCancelled,
+						ID:     moneyRequest.ID,
+					})).
+					Times(1).
+					Return(moneyRequest, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "CancelClosed",
+			method:   http.MethodDelete,
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+				store.EXPECT().
+					CloseMoneyRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MoneyRequest{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			server.config.TransferApprovalThreshold = 1000
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/money-requests/%d%s", moneyRequest.ID, tc.action)
+
+			request, err := http.NewRequest(tc.method, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
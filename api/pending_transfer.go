@@ -0,0 +1,276 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/risk"
+	"github.com/jwambugu/go-simple-bank-class/token"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultPendingTransferExpiry is how long the money of a pending transfer stays held when the client does not
+	// set expires_at
+	defaultPendingTransferExpiry = 7 * 24 * time.Hour
+
+	// maxPendingTransferExpiry is the longest the money of a pending transfer can be held
+	maxPendingTransferExpiry = 30 * 24 * time.Hour
+)
+
+type (
+	createPendingTransferRequest struct {
+		FromAccountID int64     `json:"from_account_id" binding:"required,min=1"`
+		ToAccountID   int64     `json:"to_account_id" binding:"required,min=1"`
+		Amount        int64     `json:"amount" binding:"required,gt=0"`
+		Currency      string    `json:"currency" binding:"required,currency"`
+		Memo          string    `json:"memo,omitempty" binding:"max=140"`
+		Reference     string    `json:"reference,omitempty" binding:"max=64"`
+		ExpiresAt     time.Time `json:"expires_at"`
+		// Metadata must be a JSON object, it is checked by transferMetadata
+		Metadata json.RawMessage `json:"metadata,omitempty"`
+	}
+
+	// postTransferRequest posts the whole amount held when the amount is not set
+	postTransferRequest struct {
+		Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+	}
+
+	// receivedTransferResponse leaves out the account and the entry of the sender, which the receiver does not own
+	receivedTransferResponse struct {
+		Transfer  db.Transfer `json:"transfer"`
+		ToAccount db.Account  `json:"to_account"`
+		ToEntry   db.Entry    `json:"to_entry"`
+	}
+
+	// voidedTransferResponse leaves out the hold and the account of the sender, a void does not touch the receiver
+	// account so the receiver only gets the transfer back
+	voidedTransferResponse struct {
+		Transfer db.Transfer `json:"transfer"`
+	}
+)
+
+func (server *Server) createPendingTransfer(ctx *gin.Context) {
+	var req createPendingTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	expiresAt := req.ExpiresAt
+
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(defaultPendingTransferExpiry)
+	} else if !expiresAt.After(time.Now()) {
+		err := errors.New("expires_at must be in the future")
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	} else if expiresAt.After(time.Now().Add(maxPendingTransferExpiry)) {
+		err := fmt.Errorf("expires_at must be within %d days", int(maxPendingTransferExpiry.Hours()/24))
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	metadata, err := transferMetadata(req.Metadata)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Check if the sender account is valid
+	fromAccount, isValid := server.isValidAccount(ctx, req.FromAccountID, req.Currency)
+
+	if !isValid {
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account does not belong to the authenticated user")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if _, exists := server.accountExists(ctx, req.ToAccountID); !exists {
+		return
+	}
+
+	// Pending transfers are posted without another user looking at them, so large transfers must be approved instead
+	if !server.isBelowApprovalThreshold(ctx, req.Amount, "pending") {
+		return
+	}
+
+	isAllowed := server.assessTransfer(ctx, risk.Transfer{
+		Username:      authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      fromAccount.Currency,
+	})
+
+	if !isAllowed {
+		return
+	}
+
+	result, err := server.store.CreatePendingTransferTx(ctx, db.PendingTransferTxParams{
+		TransferTxParams: db.TransferTxParams{
+			FromAccountID: req.FromAccountID,
+			ToAccountID:   req.ToAccountID,
+			Amount:        req.Amount,
+			Memo:          req.Memo,
+			Reference:     req.Reference,
+			Metadata:      metadata,
+		},
+		ExpiresAt: expiresAt,
+	})
+
+	if err != nil {
+		transferErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// authorizePendingTransfer checks that the auth user owns one of the accounts of the transfer, the sender may post or
+// void it as well as the receiver collecting the money
+func (server *Server) authorizePendingTransfer(ctx *gin.Context, id int64) bool {
+	transfer, err := server.store.GetTransfer(ctx, id)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	isOwner, err := server.ownsAnyAccount(ctx, authPayload.Username, transfer.FromAccountID, transfer.ToAccountID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if !isOwner {
+		err := errors.New("transfer does not belong to the authenticated user")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// pendingTransferErrorResponse responds with the status matching the reason the store could not post or void a
+// transfer
+func pendingTransferErrorResponse(ctx *gin.Context, err error) {
+	if errors.Is(err, db.ErrTransferNotPending) || errors.Is(err, db.ErrTransferHoldExpired) {
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	if errors.Is(err, db.ErrPostAmountExceedsHold) {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	transferErrorResponse(ctx, err)
+}
+
+func (server *Server) postTransfer(ctx *gin.Context) {
+	var uri getTransferRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req postTransferRequest
+
+	// The body is optional, an empty one posts the whole amount held
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.authorizePendingTransfer(ctx, uri.ID) {
+		return
+	}
+
+	result, err := server.store.PostTransferTx(ctx, db.PostTransferTxParams{
+		ID:     uri.ID,
+		Amount: req.Amount,
+	})
+
+	if err != nil {
+		pendingTransferErrorResponse(ctx, err)
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	ctx.JSON(http.StatusOK, newPostedTransferResponse(result, authPayload.Username))
+}
+
+// newPostedTransferResponse returns the side of a posted transfer that the user owns, the sender gets the same response
+// as for a transfer they made while the receiver collecting the money only gets their own account and entry
+func newPostedTransferResponse(result db.TransferTxResult, username string) interface{} {
+	if result.FromAccount.Owner == username {
+		return newTransferResponse(result, result.ToAccount, username)
+	}
+
+	return receivedTransferResponse{
+		Transfer:  result.Transfer,
+		ToAccount: result.ToAccount,
+		ToEntry:   result.ToEntry,
+	}
+}
+
+func (server *Server) voidTransfer(ctx *gin.Context) {
+	var uri getTransferRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.authorizePendingTransfer(ctx, uri.ID) {
+		return
+	}
+
+	result, err := server.store.VoidTransferTx(ctx, uri.ID)
+
+	if err != nil {
+		pendingTransferErrorResponse(ctx, err)
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if result.FromAccount.Owner != authPayload.Username {
+		ctx.JSON(http.StatusOK, voidedTransferResponse{Transfer: result.Transfer})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
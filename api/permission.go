@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jwambugu/go-simple-bank-class/token"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"net/http"
+)
+
+// permission is an action a user may be allowed to perform on resources they do not own
+type permission string
+
+const (
+	// permissionViewAnyAccount allows viewing the accounts of any customer
+	permissionViewAnyAccount permission = "accounts:view_any"
+	// permissionManageUsers allows managing the roles and tokens of any user
+	permissionManageUsers permission = "users:manage"
+	// permissionManageExchangeRates allows setting and removing the rates used for cross-currency transfers
+	permissionManageExchangeRates permission = "exchange_rates:manage"
+	// permissionReverseTransfers allows moving the money of any transfer back to the sender
+	permissionReverseTransfers permission = "transfers:reverse"
+	// permissionManageTransferLimits allows setting and removing the transfer limits of users and roles
+	permissionManageTransferLimits permission = "transfer_limits:manage"
+	// permissionHandleCash allows depositing cash into and withdrawing cash from the accounts of any customer
+	permissionHandleCash permission = "cash:handle"
+	// permissionViewRiskDecisions allows viewing the decisions of the risk rules and the rules that matched
+	permissionViewRiskDecisions permission = "risk_decisions:view"
+	// permissionApproveTransfers allows approving and rejecting the large transfers of other users, without it only the
+	// transfers of co-owned accounts can be decided
+	permissionApproveTransfers permission = "transfers:approve"
+	// permissionManageFees allows setting and removing the fees charged on transfers
+	permissionManageFees permission = "fees:manage"
+)
+
+// rolePermissions lists the permissions granted to each role. Depositors have none, they can only act on what they own.
+var rolePermissions = map[string][]permission{
+	util.TellerRole: {permissionViewAnyAccount, permissionHandleCash},
+	util.BankerRole: {permissionViewAnyAccount, permissionViewRiskDecisions, permissionApproveTransfers},
+	util.AdminRole: {
+		permissionViewAnyAccount,
+		permissionManageUsers,
+		permissionManageExchangeRates,
+		permissionReverseTransfers,
+		permissionManageTransferLimits,
+		permissionHandleCash,
+		permissionViewRiskDecisions,
+		permissionApproveTransfers,
+		permissionManageFees,
+	},
+}
+
+// hasPermission returns true if the role is granted the permission
+func hasPermission(role string, p permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requirePermission aborts the request unless the role of the authenticated user is granted the permission.
+// It must run after authMiddleware.
+func requirePermission(p permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		if !hasPermission(authPayload.Role, p) {
+			err := errors.New("user does not have permission to perform this action")
+
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.Next()
+	}
+}
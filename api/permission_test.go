@@ -0,0 +1,79 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequirePermission(t *testing.T) {
+	testCases := []struct {
+		name         string
+		role         string
+		permission   permission
+		expectedCode int
+	}{
+		{
+			name:         "AdminManagesUsers",
+			role:         util.AdminRole,
+			permission:   permissionManageUsers,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "BankerViewsAnyAccount",
+			role:         util.BankerRole,
+			permission:   permissionViewAnyAccount,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "BankerCannotManageUsers",
+			role:         util.BankerRole,
+			permission:   permissionManageUsers,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "DepositorCannotViewAnyAccount",
+			role:         util.DepositorRole,
+			permission:   permissionViewAnyAccount,
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+
+			path := "/permission"
+			server.router.GET(
+				path,
+				authMiddleware(server.tokenMaker, server.revocations),
+				requirePermission(testCase.permission),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, path, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, testCase.expectedCode, recorder.Code)
+		})
+	}
+}
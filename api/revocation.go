@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"github.com/jwambugu/go-simple-bank-class/cache"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/token"
+	"time"
+)
+
+// tokenRevocations checks tokens against the revocations persisted in the store.
+// Lookups are cached for ttl so the store is not queried on every request, which means a revocation made on another
+// server is enforced within ttl.
+type tokenRevocations struct {
+	store db.Store
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+func newTokenRevocations(store db.Store, cache cache.Cache, ttl time.Duration) *tokenRevocations {
+	return &tokenRevocations{
+		store: store,
+		cache: cache,
+		ttl:   ttl,
+	}
+}
+
+// isRevoked returns true if the token was revoked on its own or as part of revoking all the user's tokens
+func (r *tokenRevocations) isRevoked(ctx context.Context, payload *token.Payload) (bool, error) {
+	key := payload.ID.String()
+
+	if revoked, ok := r.cache.Get(key); ok {
+		return revoked.(bool), nil
+	}
+
+	revoked, err := r.store.IsTokenRevoked(ctx, db.IsTokenRevokedParams{
+		ID:       payload.ID,
+		Username: payload.Username,
+		IssuedAt: payload.IssuedAt,
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	r.cache.Set(key, revoked, r.ttl)
+	return revoked, nil
+}
+
+// revoke revokes a single token. The revocation is cached until the token expires since it can never be undone.
+func (r *tokenRevocations) revoke(ctx context.Context, payload *token.Payload) error {
+	_, err := r.store.CreateRevokedToken(ctx, db.CreateRevokedTokenParams{
+		ID:        payload.ID,
+		Username:  payload.Username,
+		ExpiresAt: payload.ExpiresAt,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	r.cache.Set(payload.ID.String(), true, time.Until(payload.ExpiresAt))
+	return nil
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/risk"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"net/http"
+)
+
+// RiskEvaluator assesses a transfer before it is made, only transfers it allows are made
+type RiskEvaluator interface {
+	Evaluate(ctx context.Context, transfer risk.Transfer) (risk.Assessment, error)
+}
+
+// Different types of error returned when the risk rules block a transfer. The rules that matched are left out, the
+// customer must not learn how to get around them.
+var (
+	errTransferDenied      = errors.New("transfer was denied")
+	errTransferNeedsReview = errors.New("transfer must be reviewed before it can be made")
+)
+
+type (
+	getRiskDecisionRequest struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+
+	listRiskDecisionsRequest struct {
+		Username string `form:"username" binding:"omitempty,alphanum"`
+		Decision string `form:"decision" binding:"omitempty,oneof=allow review deny"`
+		PageID   int32  `form:"page_id" binding:"required,min=1"`
+		PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+	}
+)
+
+// assessTransfer runs the risk rules against the transfer and records the decision if any rule matched.
+// It returns true if the transfer can be made.
+func (server *Server) assessTransfer(ctx *gin.Context, transfer risk.Transfer) bool {
+	assessment, err := server.riskEvaluator.Evaluate(ctx, transfer)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if len(assessment.Hits) == 0 {
+		return true
+	}
+
+	ruleHits, err := json.Marshal(assessment.Hits)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	decision, err := server.store.CreateRiskDecision(ctx, db.CreateRiskDecisionParams{
+		Username:      transfer.Username,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Currency:      transfer.Currency,
+		Decision:      assessment.Decision,
+		RuleHits:      ruleHits,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	switch decision.Decision {
+	case util.RiskDeny:
+		ctx.JSON(http.StatusUnprocessableEntity, riskDecisionErrorResponse(errTransferDenied, decision))
+		return false
+	case util.RiskReview:
+		ctx.JSON(http.StatusUnprocessableEntity, riskDecisionErrorResponse(errTransferNeedsReview, decision))
+		return false
+	}
+
+	return true
+}
+
+// riskDecisionErrorResponse returns the ID of the decision so that the customer can quote it to the bank
+func riskDecisionErrorResponse(err error, decision db.RiskDecision) gin.H {
+	return gin.H{
+		"error":          err.Error(),
+		"decision":       decision.Decision,
+		"riskDecisionID": decision.ID,
+	}
+}
+
+func (server *Server) listRiskDecisions(ctx *gin.Context) {
+	var req listRiskDecisionsRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.ListRiskDecisionsParams{
+		Username: req.Username,
+		Decision: req.Decision,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
+	}
+
+	decisions, err := server.store.ListRiskDecisions(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, decisions)
+}
+
+func (server *Server) getRiskDecision(ctx *gin.Context) {
+	var req getRiskDecisionRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	decision, err := server.store.GetRiskDecision(ctx, req.ID)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, decision)
+}
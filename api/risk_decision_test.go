@@ -0,0 +1,572 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/risk"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func randomRiskDecision(username string, decision string) db.RiskDecision {
+	return db.RiskDecision{
+		ID:            util.RandomInt(1, 1000),
+		Username:      username,
+		FromAccountID: util.RandomInt(1, 1000),
+		ToAccountID:   util.RandomInt(1, 1000),
+		Amount:        util.RandomMoney(),
+		Currency:      util.USD,
+		Decision:      decision,
+		RuleHits:      json.RawMessage(`[{"rule":"round","type":"round_amount","decision":"review","reason":"amount"}]`),
+	}
+}
+
+func TestCreateTransferRisk(t *testing.T) {
+	user, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+
+	fromAccount := createRandomAccount(user.Username)
+	toAccount := createRandomAccount(recipient.Username)
+
+	fromAccount.Currency = util.USD
+	toAccount.Currency = util.USD
+
+	rules := []risk.Rule{
+		{Name: "round", Type: risk.RoundAmountRule, Decision: util.RiskAllow, Multiple: 100},
+		{Name: "large_round", Type: risk.RoundAmountRule, Decision: util.RiskReview, Multiple: 1000},
+		{Name: "huge_round", Type: risk.RoundAmountRule, Decision: util.RiskDeny, Multiple: 10000},
+	}
+
+	testCases := []struct {
+		name           string
+		amount         int64
+		idempotencyKey string
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponse  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "NoHits",
+			amount: 10,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateRiskDecision(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "AllowedWithHits",
+			amount: 500,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateRiskDecision(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateRiskDecisionParams) (db.RiskDecision, error) {
+						require.Equal(t, util.RiskAllow, arg.Decision)
+						return db.RiskDecision{ID: 1, Decision: arg.Decision}, nil
+					})
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Review",
+			amount: 5000,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateRiskDecision(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateRiskDecisionParams) (db.RiskDecision, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, int64(fromAccount.ID), arg.FromAccountID)
+						require.Equal(t, int64(toAccount.ID), arg.ToAccountID)
+						require.Equal(t, int64(5000), arg.Amount)
+						require.Equal(t, util.USD, arg.Currency)
+						require.Equal(t, util.RiskReview, arg.Decision)
+
+						var hits []risk.Hit
+						require.NoError(t, json.Unmarshal(arg.RuleHits, &hits))
+						require.Len(t, hits, 2)
+
+						return db.RiskDecision{ID: 7, Decision: arg.Decision}, nil
+					})
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var got map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, util.RiskReview, got["decision"])
+				require.Equal(t, float64(7), got["riskDecisionID"])
+				require.NotContains(t, got, "ruleHits")
+			},
+		},
+		{
+			name:   "Deny",
+			amount: 50000,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateRiskDecision(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateRiskDecisionParams) (db.RiskDecision, error) {
+						require.Equal(t, util.RiskDeny, arg.Decision)
+						return db.RiskDecision{ID: 8, Decision: arg.Decision}, nil
+					})
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var got map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, util.RiskDeny, got["decision"])
+			},
+		},
+		{
+			name:           "RetryReplayedWithoutRules",
+			amount:         50000,
+			idempotencyKey: "transfer-key",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReplayTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+				store.EXPECT().CreateRiskDecision(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:           "ApprovalRetryReplayedWithoutRules",
+			amount:         50000,
+			idempotencyKey: "transfer-key",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReplayTransfer(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{}, sql.ErrNoRows)
+
+				// The transfer is waiting for approval, the retry gets the approval back without being assessed
+				// again
+				store.EXPECT().
+					ReplayTransferApproval(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RequestTransferApprovalTxResult{
+						Approval:    db.TransferApproval{ID: 9, Amount: 50000, Status: util.TransferApprovalPending},
+						FromAccount: fromAccount,
+					}, nil)
+
+				store.EXPECT().CreateRiskDecision(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var got requestTransferApprovalResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, int64(9), got.Approval.ID)
+			},
+		},
+		{
+			name:   "CreateRiskDecisionError",
+			amount: 5000,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateRiskDecision(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RiskDecision{}, sql.ErrConnDone)
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+
+			engine, err := risk.NewEngine(store, rules)
+			require.NoError(t, err)
+
+			server.riskEvaluator = engine
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          tc.amount,
+				"currency":        util.USD,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/v1/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+
+			if tc.idempotencyKey != "" {
+				request.Header.Set(idempotencyKeyHeader, tc.idempotencyKey)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestTransferPathsRisk(t *testing.T) {
+	user, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+
+	fromAccount := createRandomAccount(user.Username)
+	toAccount := createRandomAccount(recipient.Username)
+
+	fromAccount.Currency = util.USD
+	toAccount.Currency = util.USD
+
+	moneyRequest := db.MoneyRequest{
+		ID:        1,
+		Requester: recipient.Username,
+		Payer:     user.Username,
+		Amount:    500,
+		Currency:  util.USD,
+		Status:    util.MoneyRequestPending,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	rules := []risk.Rule{
+		{Name: "round", Type: risk.RoundAmountRule, Decision: util.RiskDeny, Multiple: 100},
+	}
+
+	testCases := []struct {
+		name       string
+		url        string
+		body       gin.H
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "ScheduledTransfer",
+			url:  "/v1/scheduled-transfers",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          500,
+				"currency":        util.USD,
+				"execute_at":      time.Now().Add(time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "StandingOrder",
+			url:  "/v1/standing-orders",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          500,
+				"currency":        util.USD,
+				"frequency":       util.WeeklyFrequency,
+				"start_at":        time.Now().Add(time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "TransferBatch",
+			url:  "/v1/transfer-batches",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"currency":        util.USD,
+				"legs": []gin.H{
+					{"to_account_id": toAccount.ID, "amount": 15},
+					{"to_account_id": toAccount.ID + 1, "amount": 500},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "AcceptMoneyRequest",
+			url:  fmt.Sprintf("/v1/money-requests/%d/accept", moneyRequest.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).
+					Return(moneyRequest, nil)
+
+				store.EXPECT().
+					GetRecipientAccount(gomock.Any(), gomock.Eq(db.GetRecipientAccountParams{
+						Username: user.Username,
+						Currency: util.USD,
+					})).
+					Times(1).
+					Return(fromAccount, nil)
+
+				store.EXPECT().
+					GetRecipientAccount(gomock.Any(), gomock.Eq(db.GetRecipientAccountParams{
+						Username: recipient.Username,
+						Currency: util.USD,
+					})).
+					Times(1).
+					Return(toAccount, nil)
+
+				store.EXPECT().AcceptMoneyRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			store.EXPECT().
+				CreateRiskDecision(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ interface{}, arg db.CreateRiskDecisionParams) (db.RiskDecision, error) {
+					require.Equal(t, user.Username, arg.Username)
+					require.Equal(t, int64(fromAccount.ID), arg.FromAccountID)
+					require.Equal(t, int64(500), arg.Amount)
+					require.Equal(t, util.RiskDeny, arg.Decision)
+
+					return db.RiskDecision{ID: 9, Decision: arg.Decision}, nil
+				})
+
+			server := newTestServer(t, store)
+
+			engine, err := risk.NewEngine(store, rules)
+			require.NoError(t, err)
+
+			server.riskEvaluator = engine
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+			var got map[string]interface{}
+			err = json.Unmarshal(recorder.Body.Bytes(), &got)
+			require.NoError(t, err)
+			require.Equal(t, util.RiskDeny, got["decision"])
+			require.Equal(t, float64(9), got["riskDecisionID"])
+		})
+	}
+}
+
+func TestListRiskDecisions(t *testing.T) {
+	decisions := []db.RiskDecision{
+		randomRiskDecision("alice", util.RiskDeny),
+		randomRiskDecision("alice", util.RiskDeny),
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "Banker",
+			query: "page_id=1&page_size=5&username=alice&decision=deny",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListRiskDecisionsParams{
+					Username: "alice",
+					Decision: util.RiskDeny,
+					Limit:    5,
+					Offset:   0,
+				}
+
+				store.EXPECT().ListRiskDecisions(gomock.Any(), gomock.Eq(arg)).Times(1).Return(decisions, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []db.RiskDecision
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, decisions, got)
+			},
+		},
+		{
+			name:  "InvalidDecision",
+			query: "page_id=1&page_size=5&decision=block",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListRiskDecisions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "DepositorForbidden",
+			query: "page_id=1&page_size=5",
+			role:  util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListRiskDecisions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "page_id=1&page_size=5",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListRiskDecisions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.RiskDecision{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/risk-decisions?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "analyst", tc.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetRiskDecision(t *testing.T) {
+	decision := randomRiskDecision("alice", util.RiskReview)
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Admin",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetRiskDecision(gomock.Any(), gomock.Eq(decision.ID)).Times(1).Return(decision, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.RiskDecision
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, decision, got)
+			},
+		},
+		{
+			name: "NotFound",
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetRiskDecision(gomock.Any(), gomock.Eq(decision.ID)).
+					Times(1).
+					Return(db.RiskDecision{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "TellerForbidden",
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetRiskDecision(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/risk-decisions/%d", decision.ID)
+
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "analyst", tc.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/risk"
+	"github.com/jwambugu/go-simple-bank-class/token"
+	"net/http"
+	"time"
+)
+
+type (
+	createScheduledTransferRequest struct {
+		FromAccountID int64     `json:"from_account_id" binding:"required,min=1"`
+		ToAccountID   int64     `json:"to_account_id" binding:"required,min=1"`
+		Amount        int64     `json:"amount" binding:"required,gt=0"`
+		Currency      string    `json:"currency" binding:"required,currency"`
+		ExecuteAt     time.Time `json:"execute_at" binding:"required"`
+	}
+
+	getScheduledTransferRequest struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+
+	listScheduledTransfersRequest struct {
+		PageID   int32 `form:"page_id" binding:"required,min=1"`
+		PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	}
+
+	scheduledTransferAttemptResponse struct {
+		ID         int64     `json:"id"`
+		TransferID *int64    `json:"transferID"`
+		Error      string    `json:"error"`
+		CreatedAt  time.Time `json:"createdAt"`
+	}
+
+	scheduledTransferResponse struct {
+		ID              int64                              `json:"id"`
+		FromAccountID   int64                              `json:"fromAccountID"`
+		ToAccountID     int64                              `json:"toAccountID"`
+		Amount          int64                              `json:"amount"`
+		Status          string                             `json:"status"`
+		ExecuteAt       time.Time                          `json:"executeAt"`
+		NextAttemptAt   time.Time                          `json:"nextAttemptAt"`
+		AttemptCount    int32                              `json:"attemptCount"`
+		TransferID      *int64                             `json:"transferID"`
+		CreatedAt       time.Time                          `json:"createdAt"`
+		StandingOrderID *int64                             `json:"standingOrderID"`
+		Attempts        []scheduledTransferAttemptResponse `json:"attempts,omitempty"`
+	}
+)
+
+// nullableInt64 returns nil for a NULL value so that it is serialized as null
+func nullableInt64(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+
+	return &n.Int64
+}
+
+func newScheduledTransferResponse(scheduledTransfer db.ScheduledTransfer) scheduledTransferResponse {
+	return scheduledTransferResponse{
+		ID:              scheduledTransfer.ID,
+		FromAccountID:   scheduledTransfer.FromAccountID,
+		ToAccountID:     scheduledTransfer.ToAccountID,
+		Amount:          scheduledTransfer.Amount,
+		Status:          scheduledTransfer.Status,
+		ExecuteAt:       scheduledTransfer.ExecuteAt,
+		NextAttemptAt:   scheduledTransfer.NextAttemptAt,
+		AttemptCount:    scheduledTransfer.AttemptCount,
+		TransferID:      nullableInt64(scheduledTransfer.TransferID),
+		CreatedAt:       scheduledTransfer.CreatedAt,
+		StandingOrderID: nullableInt64(scheduledTransfer.StandingOrderID),
+	}
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.ExecuteAt.After(time.Now()) {
+		err := errors.New("execute_at must be in the future")
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Check if the sender account is valid
+	fromAccount, isValid := server.isValidAccount(ctx, req.FromAccountID, req.Currency)
+
+	if !isValid {
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account does not belong to the authenticated user")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if _, exists := server.accountExists(ctx, req.ToAccountID); !exists {
+		return
+	}
+
+	// Scheduled transfers are made without another user looking at them, so large transfers must be approved instead
+	if !server.isBelowApprovalThreshold(ctx, req.Amount, "scheduled") {
+		return
+	}
+
+	// The rules run again when the transfer is made, a transfer they would not allow now is not scheduled at all
+	isAllowed := server.assessTransfer(ctx, risk.Transfer{
+		Username:      authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      fromAccount.Currency,
+	})
+
+	if !isAllowed {
+		return
+	}
+
+	arg := db.CreateScheduledTransferParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		ExecuteAt:     req.ExecuteAt,
+	}
+
+	scheduledTransfer, err := server.store.CreateScheduledTransfer(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduledTransfer))
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.ListOwnerScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	scheduledTransfers, err := server.store.ListOwnerScheduledTransfers(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]scheduledTransferResponse, len(scheduledTransfers))
+
+	for i, scheduledTransfer := range scheduledTransfers {
+		rsp[i] = newScheduledTransferResponse(scheduledTransfer)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) scheduledTransferExists(ctx *gin.Context, id int64) (db.ScheduledTransfer, bool) {
+	scheduledTransfer, err := server.store.GetScheduledTransfer(ctx, id)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return scheduledTransfer, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return scheduledTransfer, false
+	}
+
+	return scheduledTransfer, true
+}
+
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var req getScheduledTransferRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, exists := server.scheduledTransferExists(ctx, req.ID)
+
+	if !exists {
+		return
+	}
+
+	// Scheduled transfers are visible to whoever can view the sender account
+	if _, ok := server.viewableAccount(ctx, scheduledTransfer.FromAccountID); !ok {
+		return
+	}
+
+	attempts, err := server.store.ListScheduledTransferAttempts(ctx, scheduledTransfer.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := newScheduledTransferResponse(scheduledTransfer)
+	rsp.Attempts = make([]scheduledTransferAttemptResponse, len(attempts))
+
+	for i, attempt := range attempts {
+		rsp.Attempts[i] = scheduledTransferAttemptResponse{
+			ID:         attempt.ID,
+			TransferID: nullableInt64(attempt.TransferID),
+			Error:      attempt.Error,
+			CreatedAt:  attempt.CreatedAt,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	var req getScheduledTransferRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, exists := server.scheduledTransferExists(ctx, req.ID)
+
+	if !exists {
+		return
+	}
+
+	fromAccount, exists := server.accountExists(ctx, scheduledTransfer.FromAccountID)
+
+	if !exists {
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("scheduled transfer does not belong to the authenticated user")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, err := server.store.CancelScheduledTransfer(ctx, req.ID)
+
+	if err != nil {
+		// Transfers the worker has already completed or failed can no longer be cancelled. A processing transfer that
+		// is cancelled is not made, the worker checks it still holds the claim before moving the money.
+		if errors.Is(err, sql.ErrNoRows) {
+			err := errors.New("only pending or processing scheduled transfers can be cancelled")
+
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduledTransfer))
+}
@@ -5,57 +5,185 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/jwambugu/go-simple-bank-class/cache"
 	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/risk"
 	"github.com/jwambugu/go-simple-bank-class/token"
 	"github.com/jwambugu/go-simple-bank-class/util"
 )
 
+// Supported values of the TOKEN_FORMAT config
+const (
+	tokenFormatPaseto = "paseto"
+	tokenFormatJWT    = "jwt"
+)
+
 // Server serves all HTTP requests for the banking service
 type Server struct {
-	store      db.Store
-	router     *gin.Engine
-	tokenMaker token.Maker
-	config     util.Config
+	store         db.Store
+	router        *gin.Engine
+	tokenMaker    token.Maker
+	revocations   *tokenRevocations
+	riskEvaluator RiskEvaluator
+	config        util.Config
 }
 
 func (server *Server) setupRouter() {
 	router := gin.Default()
 
+	router.GET("/.well-known/jwks.json", server.getJWKS)
+
 	v1 := router.Group("/v1")
 
-	authRoutes := v1.Group("/").Use(authMiddleware(server.tokenMaker))
+	authRoutes := v1.Group("/").Use(authMiddleware(server.tokenMaker, server.revocations))
 	auth := v1.Group("/auth")
 
 	auth.POST("login", server.loginUser)
+	auth.POST("refresh", server.renewAccessToken)
+	authRoutes.POST("/auth/logout", server.logoutUser)
 	authRoutes.GET("/accounts", server.getAccounts)
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/accounts/:id", server.getAccountByID)
+	authRoutes.GET("/accounts/:id/entries", server.listAccountEntries)
+	authRoutes.GET("/accounts/:id/statement", server.getAccountStatement)
+	authRoutes.GET("/accounts/:id/co-owners", server.listAccountCoOwners)
+	authRoutes.POST("/accounts/:id/co-owners", server.addAccountCoOwner)
+	authRoutes.DELETE("/accounts/:id/co-owners/:username", server.removeAccountCoOwner)
+	authRoutes.POST("/accounts/:id/deposits", requirePermission(permissionHandleCash), server.depositCash)
+	authRoutes.POST("/accounts/:id/withdrawals", requirePermission(permissionHandleCash), server.withdrawCash)
+
+	authRoutes.GET("/settlement-accounts", requirePermission(permissionHandleCash), server.listSettlementAccounts)
 
+	authRoutes.GET("/transfers", server.listTransfers)
 	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.POST("/transfers/:id/reverse", requirePermission(permissionReverseTransfers), server.reverseTransfer)
+	authRoutes.POST("/transfers/:id/post", server.postTransfer)
+	authRoutes.POST("/transfers/:id/void", server.voidTransfer)
+	authRoutes.POST("/pending-transfers", server.createPendingTransfer)
+
+	authRoutes.GET("/risk-decisions", requirePermission(permissionViewRiskDecisions), server.listRiskDecisions)
+	authRoutes.GET("/risk-decisions/:id", requirePermission(permissionViewRiskDecisions), server.getRiskDecision)
+
+	authRoutes.GET("/transfer-approvals", server.listTransferApprovals)
+	authRoutes.GET("/transfer-approvals/:id", server.getTransferApproval)
+	authRoutes.POST("/transfer-approvals/:id/approve", server.approveTransfer)
+	authRoutes.POST("/transfer-approvals/:id/reject", server.rejectTransfer)
+
+	authRoutes.POST("/transfer-batches", server.createTransferBatch)
+
+	authRoutes.GET("/scheduled-transfers", server.listScheduledTransfers)
+	authRoutes.POST("/scheduled-transfers", server.createScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers/:id", server.getScheduledTransfer)
+	authRoutes.DELETE("/scheduled-transfers/:id", server.cancelScheduledTransfer)
+
+	authRoutes.GET("/standing-orders", server.listStandingOrders)
+	authRoutes.POST("/standing-orders", server.createStandingOrder)
+	authRoutes.GET("/standing-orders/:id", server.getStandingOrder)
+	authRoutes.POST("/standing-orders/:id/pause", server.pauseStandingOrder)
+	authRoutes.POST("/standing-orders/:id/resume", server.resumeStandingOrder)
+	authRoutes.DELETE("/standing-orders/:id", server.cancelStandingOrder)
+
+	authRoutes.GET("/money-requests", server.listMoneyRequests)
+	authRoutes.POST("/money-requests", server.createMoneyRequest)
+	authRoutes.GET("/money-requests/:id", server.getMoneyRequest)
+	authRoutes.POST("/money-requests/:id/accept", server.acceptMoneyRequest)
+	authRoutes.POST("/money-requests/:id/decline", server.declineMoneyRequest)
+	authRoutes.DELETE("/money-requests/:id", server.cancelMoneyRequest)
+
+	authRoutes.GET("/exchange-rates", server.listExchangeRates)
+	authRoutes.PUT("/exchange-rates/:from_currency/:to_currency", requirePermission(permissionManageExchangeRates),
+		server.upsertExchangeRate)
+	authRoutes.DELETE("/exchange-rates/:from_currency/:to_currency", requirePermission(permissionManageExchangeRates),
+		server.deleteExchangeRate)
+
+	authRoutes.GET("/fee-schedules", server.listFeeSchedules)
+	authRoutes.PUT("/fee-schedules/:currency", requirePermission(permissionManageFees), server.upsertFeeSchedule)
+	authRoutes.DELETE("/fee-schedules/:currency", requirePermission(permissionManageFees), server.deleteFeeSchedule)
+
+	authRoutes.GET("/transfer-limits", requirePermission(permissionManageTransferLimits), server.listTransferLimits)
+	authRoutes.PUT("/transfer-limits/:scope/:subject/:currency", requirePermission(permissionManageTransferLimits),
+		server.upsertTransferLimit)
+	authRoutes.DELETE("/transfer-limits/:scope/:subject/:currency", requirePermission(permissionManageTransferLimits),
+		server.deleteTransferLimit)
 
 	v1.POST("/users", server.createUser)
+	authRoutes.GET("/users/:username", server.getUser)
+	authRoutes.PATCH("/users/:username/role", requirePermission(permissionManageUsers), server.updateUserRole)
+	authRoutes.POST("/users/:username/revoke-tokens", server.revokeUserTokens)
 
 	server.router = router
 }
 
+// newTokenMaker creates the token maker selected by the config.
+// Tokens are signed with the symmetric key unless a signing key file is configured, which only PASETO supports.
+func newTokenMaker(config util.Config) (token.Maker, error) {
+	if config.TokenSigningKeyFile == "" {
+		if config.TokenFormat != "" && config.TokenFormat != tokenFormatPaseto {
+			return nil, fmt.Errorf("token format %q requires a signing key file", config.TokenFormat)
+		}
+
+		return token.NewPasetoMaker(config.TokenSymmetricKey)
+	}
+
+	keyring, err := token.LoadKeyring(config.TokenSigningKeyFile, config.TokenVerificationKeyFiles)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.TokenFormat {
+	case "", tokenFormatPaseto:
+		return token.NewPasetoPublicMaker(keyring)
+	case tokenFormatJWT:
+		return token.NewJWTPublicMaker(keyring)
+	}
+
+	return nil, fmt.Errorf("unsupported token format: %q", config.TokenFormat)
+}
+
 // NewServer creates a new HTTP server and set up routing.
 func NewServer(config util.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	tokenMaker, err := newTokenMaker(config)
 
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	riskEvaluator, err := risk.NewEngineFromFile(store, config.RiskRulesFile)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create risk evaluator: %w", err)
+	}
+
 	server := &Server{
-		store:      store,
-		tokenMaker: tokenMaker,
-		config:     config,
+		store:         store,
+		tokenMaker:    tokenMaker,
+		revocations:   newTokenRevocations(store, cache.NewMemoryCache(), config.RevocationCacheTTL),
+		riskEvaluator: riskEvaluator,
+		config:        config,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		if err := v.RegisterValidation("currency", validCurrency); err != nil {
 			return nil, fmt.Errorf("failed to register the currency validator: %v", err)
 		}
+
+		if err := v.RegisterValidation("role", validRole); err != nil {
+			return nil, fmt.Errorf("failed to register the role validator: %v", err)
+		}
+
+		if err := v.RegisterValidation("exchange_rate", validExchangeRate); err != nil {
+			return nil, fmt.Errorf("failed to register the exchange rate validator: %v", err)
+		}
+
+		if err := v.RegisterValidation("frequency", validFrequency); err != nil {
+			return nil, fmt.Errorf("failed to register the frequency validator: %v", err)
+		}
+
+		if err := v.RegisterValidation("percentage", validPercentage); err != nil {
+			return nil, fmt.Errorf("failed to register the percentage validator: %v", err)
+		}
 	}
 
 	server.setupRouter()
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewTokenMakerWithoutSigningKey(t *testing.T) {
+	testCases := []struct {
+		name        string
+		tokenFormat string
+		isValid     bool
+	}{
+		{
+			name:        "DefaultFormat",
+			tokenFormat: "",
+			isValid:     true,
+		},
+		{
+			name:        "Paseto",
+			tokenFormat: tokenFormatPaseto,
+			isValid:     true,
+		},
+		{
+			// JWTs are only signed with asymmetric keys, the symmetric key must not be used instead
+			name:        "JWT",
+			tokenFormat: tokenFormatJWT,
+		},
+		{
+			name:        "UnsupportedFormat",
+			tokenFormat: "unsupported",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := newTokenMaker(util.Config{
+				TokenFormat:       tc.tokenFormat,
+				TokenSymmetricKey: util.RandomString(32),
+			})
+
+			if tc.isValid {
+				require.NoError(t, err)
+				require.NotNil(t, maker)
+				return
+			}
+
+			require.Error(t, err)
+			require.Nil(t, maker)
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/risk"
+	"github.com/jwambugu/go-simple-bank-class/token"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"net/http"
+	"time"
+)
+
+type (
+	createStandingOrderRequest struct {
+		FromAccountID int64     `json:"from_account_id" binding:"required,min=1"`
+		ToAccountID   int64     `json:"to_account_id" binding:"required,min=1"`
+		Amount        int64     `json:"amount" binding:"required,gt=0"`
+		Currency      string    `json:"currency" binding:"required,currency"`
+		Frequency     string    `json:"frequency" binding:"required,frequency"`
+		DayOfMonth    int32     `json:"day_of_month" binding:"omitempty,min=1,max=31"`
+		StartAt       time.Time `json:"start_at" binding:"required"`
+		EndAt         time.Time `json:"end_at" binding:"omitempty,gtfield=StartAt"`
+		MaxRuns       int32     `json:"max_runs" binding:"omitempty,min=1"`
+	}
+
+	getStandingOrderRequest struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+
+	listStandingOrdersRequest struct {
+		PageID   int32 `form:"page_id" binding:"required,min=1"`
+		PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	}
+
+	standingOrderResponse struct {
+		ID            int64      `json:"id"`
+		FromAccountID int64      `json:"fromAccountID"`
+		ToAccountID   int64      `json:"toAccountID"`
+		Amount        int64      `json:"amount"`
+		Frequency     string     `json:"frequency"`
+		DayOfMonth    int32      `json:"dayOfMonth"`
+		Status        string     `json:"status"`
+		NextRunAt     time.Time  `json:"nextRunAt"`
+		EndAt         *time.Time `json:"endAt"`
+		MaxRuns       *int32     `json:"maxRuns"`
+		RunCount      int32      `json:"runCount"`
+		CreatedAt     time.Time  `json:"createdAt"`
+	}
+)
+
+func newStandingOrderResponse(order db.StandingOrder) standingOrderResponse {
+	rsp := standingOrderResponse{
+		ID:            order.ID,
+		FromAccountID: order.FromAccountID,
+		ToAccountID:   order.ToAccountID,
+		Amount:        order.Amount,
+		Frequency:     order.Frequency,
+		DayOfMonth:    order.DayOfMonth,
+		Status:        order.Status,
+		NextRunAt:     order.NextRunAt,
+		RunCount:      order.RunCount,
+		CreatedAt:     order.CreatedAt,
+	}
+
+	if order.EndAt.Valid {
+		rsp.EndAt = &order.EndAt.Time
+	}
+
+	if order.MaxRuns.Valid {
+		rsp.MaxRuns = &order.MaxRuns.Int32
+	}
+
+	return rsp
+}
+
+func (server *Server) createStandingOrder(ctx *gin.Context) {
+	var req createStandingOrderRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Only monthly orders run on a given day of the month
+	if (req.Frequency == util.MonthlyFrequency) != (req.DayOfMonth != 0) {
+		err := errors.New("day_of_month must be set for monthly orders only")
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.StartAt.After(time.Now()) {
+		err := errors.New("start_at must be in the future")
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Check if the sender account is valid
+	fromAccount, isValid := server.isValidAccount(ctx, req.FromAccountID, req.Currency)
+
+	if !isValid {
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account does not belong to the authenticated user")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if _, exists := server.accountExists(ctx, req.ToAccountID); !exists {
+		return
+	}
+
+	// The transfers of standing orders are made without another user looking at them, so large transfers must be
+	// approved instead
+	if !server.isBelowApprovalThreshold(ctx, req.Amount, "made by a standing order") {
+		return
+	}
+
+	// The rules run again for every transfer of the standing order when it is made
+	isAllowed := server.assessTransfer(ctx, risk.Transfer{
+		Username:      authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      fromAccount.Currency,
+	})
+
+	if !isAllowed {
+		return
+	}
+
+	arg := db.CreateStandingOrderParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Frequency:     req.Frequency,
+		DayOfMonth:    req.DayOfMonth,
+		NextRunAt:     util.FirstStandingOrderRun(req.Frequency, int(req.DayOfMonth), req.StartAt),
+		EndAt:         sql.NullTime{Time: req.EndAt, Valid: !req.EndAt.IsZero()},
+		MaxRuns:       sql.NullInt32{Int32: req.MaxRuns, Valid: req.MaxRuns != 0},
+	}
+
+	order, err := server.store.CreateStandingOrder(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newStandingOrderResponse(order))
+}
+
+func (server *Server) listStandingOrders(ctx *gin.Context) {
+	var req listStandingOrdersRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.ListOwnerStandingOrdersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	orders, err := server.store.ListOwnerStandingOrders(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]standingOrderResponse, len(orders))
+
+	for i, order := range orders {
+		rsp[i] = newStandingOrderResponse(order)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) standingOrderExists(ctx *gin.Context, id int64) (db.StandingOrder, bool) {
+	order, err := server.store.GetStandingOrder(ctx, id)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return order, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return order, false
+	}
+
+	return order, true
+}
+
+// ownedStandingOrder returns the standing order if it was set up by the authenticated user
+func (server *Server) ownedStandingOrder(ctx *gin.Context) (db.StandingOrder, bool) {
+	var req getStandingOrderRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.StandingOrder{}, false
+	}
+
+	order, exists := server.standingOrderExists(ctx, req.ID)
+
+	if !exists {
+		return order, false
+	}
+
+	fromAccount, exists := server.accountExists(ctx, order.FromAccountID)
+
+	if !exists {
+		return order, false
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("standing order does not belong to the authenticated user")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return order, false
+	}
+
+	return order, true
+}
+
+func (server *Server) getStandingOrder(ctx *gin.Context) {
+	var req getStandingOrderRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	order, exists := server.standingOrderExists(ctx, req.ID)
+
+	if !exists {
+		return
+	}
+
+	// Standing orders are visible to whoever can view the sender account
+	if _, ok := server.viewableAccount(ctx, order.FromAccountID); !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newStandingOrderResponse(order))
+}
+
+// respondStandingOrderStatusChange writes the standing order after a status change, a missing row means the order
+// was not in a status the change applies to
+func respondStandingOrderStatusChange(ctx *gin.Context, order db.StandingOrder, err error, msg string) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(errors.New(msg)))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newStandingOrderResponse(order))
+}
+
+func (server *Server) pauseStandingOrder(ctx *gin.Context) {
+	order, ok := server.ownedStandingOrder(ctx)
+
+	if !ok {
+		return
+	}
+
+	order, err := server.store.PauseStandingOrder(ctx, order.ID)
+	respondStandingOrderStatusChange(ctx, order, err, "only active standing orders can be paused")
+}
+
+func (server *Server) resumeStandingOrder(ctx *gin.Context) {
+	order, ok := server.ownedStandingOrder(ctx)
+
+	if !ok {
+		return
+	}
+
+	// Runs missed while the order was paused are skipped
+	now := time.Now()
+	nextRunAt := order.NextRunAt
+
+	for nextRunAt.Before(now) {
+		nextRunAt = util.NextStandingOrderRun(order.Frequency, int(order.DayOfMonth), nextRunAt)
+	}
+
+	arg := db.ResumeStandingOrderParams{
+		ID:        order.ID,
+		NextRunAt: nextRunAt,
+	}
+
+	order, err := server.store.ResumeStandingOrder(ctx, arg)
+	respondStandingOrderStatusChange(ctx, order, err, "only paused standing orders can be resumed")
+}
+
+func (server *Server) cancelStandingOrder(ctx *gin.Context) {
+	order, ok := server.ownedStandingOrder(ctx)
+
+	if !ok {
+		return
+	}
+
+	order, err := server.store.CancelStandingOrderTx(ctx, order.ID)
+	respondStandingOrderStatusChange(ctx, order, err, "only active or paused standing orders can be cancelled")
+}
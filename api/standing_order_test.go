@@ -0,0 +1,422 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func createRandomStandingOrder(fromAccount, toAccount db.Account) db.StandingOrder {
+	return db.StandingOrder{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: int64(fromAccount.ID),
+		ToAccountID:   int64(toAccount.ID),
+		Amount:        util.RandomMoney(),
+		Frequency:     util.WeeklyFrequency,
+		Status:        util.StandingOrderActive,
+		NextRunAt:     time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+	}
+}
+
+func TestCreateStandingOrder(t *testing.T) {
+	user, _ := randomUser(t)
+	otherUser, _ := randomUser(t)
+
+	fromAccount := createRandomAccount(user.Username)
+	toAccount := createRandomAccount(otherUser.Username)
+
+	startAt := time.Date(time.Now().Year()+1, time.March, 10, 9, 0, 0, 0, time.UTC)
+	endAt := startAt.AddDate(1, 0, 0)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Monthly",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          100,
+				"currency":        fromAccount.Currency,
+				"frequency":       util.MonthlyFrequency,
+				"day_of_month":    5,
+				"start_at":        startAt,
+				"end_at":          endAt,
+				"max_runs":        6,
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateStandingOrderParams{
+					FromAccountID: int64(fromAccount.ID),
+					ToAccountID:   int64(toAccount.ID),
+					Amount:        100,
+					Frequency:     util.MonthlyFrequency,
+					DayOfMonth:    5,
+					NextRunAt:     time.Date(startAt.Year(), time.April, 5, 9, 0, 0, 0, time.UTC),
+					EndAt:         sql.NullTime{Time: endAt, Valid: true},
+					MaxRuns:       sql.NullInt32{Int32: 6, Valid: true},
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					CreateStandingOrder(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.StandingOrder{ID: 1, EndAt: arg.EndAt, MaxRuns: arg.MaxRuns}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got standingOrderResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, int32(6), *got.MaxRuns)
+				require.True(t, endAt.Equal(*got.EndAt))
+			},
+		},
+		{
+			name: "Daily",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          100,
+				"currency":        fromAccount.Currency,
+				"frequency":       util.DailyFrequency,
+				"start_at":        startAt,
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateStandingOrderParams{
+					FromAccountID: int64(fromAccount.ID),
+					ToAccountID:   int64(toAccount.ID),
+					Amount:        100,
+					Frequency:     util.DailyFrequency,
+					NextRunAt:     startAt,
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(fromAccount, nil)
+				store.EXPECT().
+					CreateStandingOrder(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.StandingOrder{ID: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got standingOrderResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Nil(t, got.EndAt)
+				require.Nil(t, got.MaxRuns)
+			},
+		},
+		{
+			name: "AboveApprovalThreshold",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          1001,
+				"currency":        fromAccount.Currency,
+				"frequency":       util.DailyFrequency,
+				"start_at":        startAt,
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(fromAccount, nil)
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "MonthlyWithoutDayOfMonth",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          100,
+				"currency":        fromAccount.Currency,
+				"frequency":       util.MonthlyFrequency,
+				"start_at":        startAt,
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "WeeklyWithDayOfMonth",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          100,
+				"currency":        fromAccount.Currency,
+				"frequency":       util.WeeklyFrequency,
+				"day_of_month":    5,
+				"start_at":        startAt,
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidFrequency",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          100,
+				"currency":        fromAccount.Currency,
+				"frequency":       "yearly",
+				"start_at":        startAt,
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "EndBeforeStart",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          100,
+				"currency":        fromAccount.Currency,
+				"frequency":       util.DailyFrequency,
+				"start_at":        startAt,
+				"end_at":          startAt.Add(-time.Hour),
+			},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          100,
+				"currency":        fromAccount.Currency,
+				"frequency":       util.DailyFrequency,
+				"start_at":        startAt,
+			},
+			username: otherUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().CreateStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			server.config.TransferApprovalThreshold = 1000
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/v1/standing-orders", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateStandingOrderStatus(t *testing.T) {
+	user, _ := randomUser(t)
+	otherUser, _ := randomUser(t)
+
+	fromAccount := createRandomAccount(user.Username)
+	toAccount := createRandomAccount(otherUser.Username)
+	order := createRandomStandingOrder(fromAccount, toAccount)
+
+	pausedOrder := order
+	pausedOrder.Status = util.StandingOrderPaused
+	pausedOrder.NextRunAt = time.Now().AddDate(0, 0, -10)
+
+	testCases := []struct {
+		name          string
+		method        string
+		action        string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Pause",
+			method:   http.MethodPost,
+			action:   "/pause",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().PauseStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(pausedOrder, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "PauseNotActive",
+			method:   http.MethodPost,
+			action:   "/pause",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(pausedOrder, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					PauseStandingOrder(gomock.Any(), gomock.Eq(order.ID)).
+					Times(1).
+					Return(db.StandingOrder{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "ResumeSkipsMissedRuns",
+			method:   http.MethodPost,
+			action:   "/resume",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(pausedOrder, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					ResumeStandingOrder(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ResumeStandingOrderParams) (db.StandingOrder, error) {
+						// The next weekly run after now
+						require.True(t, arg.NextRunAt.After(time.Now()))
+						require.True(t, arg.NextRunAt.Before(time.Now().AddDate(0, 0, 7)))
+
+						return order, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Cancel",
+			method:   http.MethodDelete,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				cancelledOrder := order
+				cancelledOrder.Status = util.StandingOrderCancelled
+
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					CancelStandingOrderTx(gomock.Any(), gomock.Eq(order.ID)).
+					Times(1).
+					Return(cancelledOrder, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got standingOrderResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, util.StandingOrderCancelled, got.Status)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			method:   http.MethodDelete,
+			username: otherUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).Times(1).Return(order, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().CancelStandingOrderTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			method:   http.MethodPost,
+			action:   "/pause",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetStandingOrder(gomock.Any(), gomock.Eq(order.ID)).
+					Times(1).
+					Return(db.StandingOrder{}, sql.ErrNoRows)
+				store.EXPECT().PauseStandingOrder(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/standing-orders/%d%s", order.ID, tc.action)
+
+			request, err := http.NewRequest(tc.method, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
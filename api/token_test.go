@@ -0,0 +1,423 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/token"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRenewAccessToken(t *testing.T) {
+	user, _ := randomUser(t)
+	user.Role = util.BankerRole
+
+	// createRefreshToken issues a refresh token for the user and the session it would have been stored with
+	createRefreshToken := func(t *testing.T, tokenMaker token.Maker, duration time.Duration) (string, db.Session) {
+		refreshToken, payload, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, token.RefreshToken, duration)
+		require.NoError(t, err)
+
+		session := db.Session{
+			ID:           payload.ID,
+			Username:     user.Username,
+			RefreshToken: refreshToken,
+			ExpiresAt:    payload.ExpiresAt,
+		}
+
+		return refreshToken, session
+	}
+
+	testCases := []struct {
+		name          string
+		buildBody     func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "StatusOK",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				refreshToken, session := createRefreshToken(t, tokenMaker, time.Hour)
+
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
+				return gin.H{"refresh_token": refreshToken}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response renewAccessTokenResponse
+
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.NotEmpty(t, response.AccessToken)
+				require.NotZero(t, response.AccessTokenExpiresAt)
+			},
+		},
+		{
+			name: "UserError",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				refreshToken, session := createRefreshToken(t, tokenMaker, time.Hour)
+
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+
+				return gin.H{"refresh_token": refreshToken}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidRefreshToken",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+
+				return gin.H{"refresh_token": "invalid"}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "AccessTokenProvided",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				accessToken, _, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, token.AccessToken, time.Hour)
+				require.NoError(t, err)
+
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+
+				return gin.H{"refresh_token": accessToken}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredRefreshToken",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				refreshToken, _ := createRefreshToken(t, tokenMaker, -time.Minute)
+
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+
+				return gin.H{"refresh_token": refreshToken}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "SessionNotFound",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				refreshToken, session := createRefreshToken(t, tokenMaker, time.Hour)
+
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+
+				return gin.H{"refresh_token": refreshToken}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				refreshToken, session := createRefreshToken(t, tokenMaker, time.Hour)
+				session.IsBlocked = true
+
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				return gin.H{"refresh_token": refreshToken}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MismatchedSessionToken",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				refreshToken, session := createRefreshToken(t, tokenMaker, time.Hour)
+				session.RefreshToken = "another-token"
+
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				return gin.H{"refresh_token": refreshToken}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiredSession",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				refreshToken, session := createRefreshToken(t, tokenMaker, time.Hour)
+				session.ExpiresAt = time.Now().Add(-time.Minute)
+
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+
+				return gin.H{"refresh_token": refreshToken}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "StatusBadRequest",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+
+				return gin.H{}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			requestBody, err := json.Marshal(tc.buildBody(t, server.tokenMaker, store))
+			require.NoError(t, err)
+
+			url := "/v1/auth/refresh"
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(requestBody))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLogoutUser(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildBody     func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "StatusNoContent",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateRevokedToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateRevokedTokenParams) (db.RevokedToken, error) {
+						require.Equal(t, user.Username, arg.Username)
+						return db.RevokedToken{ID: arg.ID, Username: arg.Username}, nil
+					})
+
+				return nil
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "BlocksSession",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				refreshToken, payload, err := tokenMaker.CreateToken(user.Username, util.DepositorRole, token.RefreshToken, time.Hour)
+				require.NoError(t, err)
+
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(db.Session{ID: payload.ID, IsBlocked: true}, nil)
+
+				store.EXPECT().CreateRevokedToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RevokedToken{}, nil)
+
+				return gin.H{"refresh_token": refreshToken}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "RefreshTokenOfAnotherUser",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				refreshToken, _, err := tokenMaker.CreateToken("another", util.DepositorRole, token.RefreshToken, time.Hour)
+				require.NoError(t, err)
+
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateRevokedToken(gomock.Any(), gomock.Any()).Times(0)
+
+				return gin.H{"refresh_token": refreshToken}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RevokeTokenError",
+			buildBody: func(t *testing.T, tokenMaker token.Maker, store *mockdb.MockStore) gin.H {
+				store.EXPECT().CreateRevokedToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RevokedToken{}, sql.ErrConnDone)
+
+				return nil
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var requestBody []byte
+
+			if body := tc.buildBody(t, server.tokenMaker, store); body != nil {
+				var err error
+
+				requestBody, err = json.Marshal(body)
+				require.NoError(t, err)
+			}
+
+			url := "/v1/auth/logout"
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(requestBody))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLoggedOutTokenIsRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	// The token is checked against the store only once, the logout is then served from the cache
+	store.EXPECT().IsTokenRevoked(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+	store.EXPECT().CreateRevokedToken(gomock.Any(), gomock.Any()).Times(1).Return(db.RevokedToken{}, nil)
+
+	server := newTestServer(t, store)
+
+	accessToken, _, err := server.tokenMaker.CreateToken(util.RandomOwner(), util.DepositorRole, token.AccessToken, time.Minute)
+	require.NoError(t, err)
+
+	for _, expectedCode := range []int{http.StatusNoContent, http.StatusUnauthorized} {
+		recorder := httptest.NewRecorder()
+
+		request, err := http.NewRequest(http.MethodPost, "/v1/auth/logout", nil)
+		require.NoError(t, err)
+
+		request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+accessToken)
+
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, expectedCode, recorder.Code)
+	}
+}
+
+func TestGetJWKS(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signingKey, err := token.NewSigningKey(privateKey)
+	require.NoError(t, err)
+
+	keyring, err := token.NewKeyring(signingKey)
+	require.NoError(t, err)
+
+	publicMaker, err := token.NewPasetoPublicMaker(keyring)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		tokenMaker    token.Maker
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "StatusOK",
+			tokenMaker: publicMaker,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var jwks token.JWKS
+
+				err := json.Unmarshal(recorder.Body.Bytes(), &jwks)
+				require.NoError(t, err)
+				require.Len(t, jwks.Keys, 1)
+				require.Equal(t, signingKey.ID, jwks.Keys[0].KeyID)
+			},
+		},
+		{
+			name: "SymmetricKey",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+
+			if tc.tokenMaker != nil {
+				server.tokenMaker = tc.tokenMaker
+			}
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -1,23 +1,119 @@
 package api
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/risk"
 	"github.com/jwambugu/go-simple-bank-class/token"
+	"math"
 	"net/http"
+	"time"
 )
 
-type createTransferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+
+	// maxTransferMetadataSize is the size in bytes of the largest compacted metadata object a transfer can carry
+	maxTransferMetadataSize = 4096
+)
+
+// emptyTransferMetadata matches every transfer when it is used as the metadata filter
+var emptyTransferMetadata = json.RawMessage("{}")
+
+// errRecipientNotFound is returned whether the recipient does not exist or has no account in the currency, so that
+// it cannot be used to find out which accounts a user has
+var errRecipientNotFound = errors.New("recipient not found")
+
+// maxTransferTime is the upper bound of the date range filter when the client does not set one
+var maxTransferTime = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+type (
+	// createTransferRequest addresses the receiver either by account ID or by the username or email of its owner,
+	// in which case the account holding to_currency receives the money
+	createTransferRequest struct {
+		FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+		ToAccountID   int64  `json:"to_account_id" binding:"omitempty,min=1"`
+		ToUsername    string `json:"to_username,omitempty" binding:"omitempty,alphanum"`
+		ToEmail       string `json:"to_email,omitempty" binding:"omitempty,email"`
+		ToCurrency    string `json:"to_currency,omitempty" binding:"omitempty,currency"`
+		Amount        int64  `json:"amount" binding:"required,gt=0"`
+		Currency      string `json:"currency" binding:"required,currency"`
+		Memo          string `json:"memo,omitempty" binding:"max=140"`
+		Reference     string `json:"reference,omitempty" binding:"max=64"`
+		// Metadata must be a JSON object, it is checked by transferMetadata
+		Metadata json.RawMessage `json:"metadata,omitempty"`
+	}
+
+	getTransferRequest struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+
+	reverseTransferRequest struct {
+		Reason string `json:"reason" binding:"required,max=255"`
+	}
+
+	// sentTransferResponse leaves out the account and the entry of the receiver, which the sender does not own
+	sentTransferResponse struct {
+		Transfer    db.Transfer     `json:"transfer"`
+		FromAccount db.Account      `json:"from_account"`
+		FromEntry   db.Entry        `json:"from_entry"`
+		Fee         db.FeeBreakdown `json:"fee"`
+	}
+
+	listTransfersRequest struct {
+		Owner     string    `form:"owner" binding:"omitempty,alphanum"`
+		AccountID int64     `form:"account_id" binding:"omitempty,min=1"`
+		Direction string    `form:"direction" binding:"omitempty,oneof=in out"`
+		From      time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+		To        time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty,gtfield=From"`
+		MinAmount int64     `form:"min_amount" binding:"omitempty,min=1"`
+		MaxAmount int64     `form:"max_amount" binding:"omitempty,min=1,gtefield=MinAmount"`
+		Search    string    `form:"search" binding:"max=140"`
+		Metadata  string    `form:"metadata"`
+		PageID    int32     `form:"page_id" binding:"required,min=1"`
+		PageSize  int32     `form:"page_size" binding:"required,min=5,max=10"`
+	}
+)
+
+// transferMetadata checks that the metadata is a JSON object no larger than maxTransferMetadataSize and returns it
+// compacted. Missing and null metadata are returned as nil.
+func transferMetadata(metadata json.RawMessage) (json.RawMessage, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+
+	var object map[string]json.RawMessage
+
+	if err := json.Unmarshal(metadata, &object); err != nil {
+		return nil, errors.New("metadata must be a JSON object")
+	}
+
+	if object == nil {
+		return nil, nil
+	}
+
+	var compacted bytes.Buffer
+
+	if err := json.Compact(&compacted, metadata); err != nil {
+		return nil, err
+	}
+
+	if compacted.Len() > maxTransferMetadataSize {
+		return nil, fmt.Errorf("metadata must not be larger than %d bytes", maxTransferMetadataSize)
+	}
+
+	return compacted.Bytes(), nil
 }
 
-func (server *Server) isValidAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+func (server *Server) accountExists(ctx *gin.Context, accountID int64) (db.Account, bool) {
 	// Find the account using the account id
 	account, err := server.store.GetAccount(ctx, int32(accountID))
 
@@ -31,6 +127,57 @@ func (server *Server) isValidAccount(ctx *gin.Context, accountID int64, currency
 		return account, false
 	}
 
+	return account, true
+}
+
+// recipientAccount finds the account of the user addressed by username or email. It holds to_currency, or the
+// currency of the transfer if the client did not set one.
+func (server *Server) recipientAccount(ctx *gin.Context, req createTransferRequest) (db.Account, bool) {
+	currency := req.ToCurrency
+
+	if currency == "" {
+		currency = req.Currency
+	}
+
+	account, err := server.store.GetRecipientAccount(ctx, db.GetRecipientAccountParams{
+		Username: req.ToUsername,
+		Email:    req.ToEmail,
+		Currency: currency,
+	})
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(errRecipientNotFound))
+			return account, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
+
+// countRecipients returns how many ways of addressing the receiver the request uses
+func countRecipients(req createTransferRequest) int {
+	count := 0
+
+	for _, isSet := range []bool{req.ToAccountID != 0, req.ToUsername != "", req.ToEmail != ""} {
+		if isSet {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (server *Server) isValidAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+	account, exists := server.accountExists(ctx, accountID)
+
+	if !exists {
+		return account, false
+	}
+
 	if account.Currency != currency {
 		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
@@ -49,6 +196,34 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
+	if countRecipients(req) != 1 {
+		err := errors.New("exactly one of to_account_id, to_username or to_email is required")
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.ToCurrency != "" && req.ToAccountID != 0 {
+		err := errors.New("to_currency can only be used with to_username or to_email")
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	metadata, err := transferMetadata(req.Metadata)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if len(ctx.GetHeader(idempotencyKeyHeader)) > maxIdempotencyKeyLength {
+		err := fmt.Errorf("idempotency key must not be longer than %d characters", maxIdempotencyKeyLength)
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	// Check if the sender account is valid
 	fromAccount, isValid := server.isValidAccount(ctx, req.FromAccountID, req.Currency)
 
@@ -66,26 +241,334 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	// Check if the receiver account is valid
-	_, isValid = server.isValidAccount(ctx, req.ToAccountID, req.Currency)
+	// Check if the receiver account exists, the amount is converted if it holds a different currency
+	var toAccount db.Account
 
-	if !isValid {
-		return
+	if req.ToAccountID == 0 {
+		var found bool
+
+		if toAccount, found = server.recipientAccount(ctx, req); !found {
+			return
+		}
+	} else {
+		var exists bool
+
+		if toAccount, exists = server.accountExists(ctx, req.ToAccountID); !exists {
+			return
+		}
 	}
 
+	toAccountID := int64(toAccount.ID)
+
 	arg := db.TransferTxParams{
 		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
+		ToAccountID:   toAccountID,
 		Amount:        req.Amount,
+		Memo:          req.Memo,
+		Reference:     req.Reference,
+		Metadata:      metadata,
 	}
 
-	// Create a new transfer
-	transferTxResult, err := server.store.TransferTx(ctx, arg)
+	idempotencyKey := ctx.GetHeader(idempotencyKeyHeader)
+
+	idempotentArg := db.IdempotentTransferTxParams{
+		TransferTxParams: arg,
+		Username:         authPayload.Username,
+		IdempotencyKey:   idempotencyKey,
+		RequestHash:      hashTransferRequest(req),
+	}
+
+	// A retry of a transfer already made is replayed before the risk rules run, they would count the transfer again
+	// and could deny the retry of a transfer that went through
+	if idempotencyKey != "" {
+		transferTxResult, err := server.store.ReplayTransfer(ctx, idempotentArg)
+
+		if err == nil {
+			ctx.JSON(http.StatusOK, newTransferResponse(transferTxResult, toAccount, authPayload.Username))
+			return
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			transferErrorResponse(ctx, err)
+			return
+		}
+
+		// So is the retry of a transfer waiting for approval, its money is already held
+		approvalResult, err := server.store.ReplayTransferApproval(ctx, db.RequestTransferApprovalTxParams{
+			TransferTxParams: arg,
+			RequestedBy:      authPayload.Username,
+			IdempotencyKey:   idempotencyKey,
+			RequestHash:      idempotentArg.RequestHash,
+		})
+
+		if err == nil {
+			ctx.JSON(http.StatusAccepted, newRequestTransferApprovalResponse(approvalResult))
+			return
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			transferErrorResponse(ctx, err)
+			return
+		}
+	}
+
+	// The risk rules only run once the transfer is known to be valid, so that the decisions recorded are about real
+	// transfers
+	isAllowed := server.assessTransfer(ctx, risk.Transfer{
+		Username:      authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        req.Amount,
+		Currency:      fromAccount.Currency,
+	})
+
+	if !isAllowed {
+		return
+	}
+
+	// Large transfers are only made once another user approves them
+	if threshold := server.config.TransferApprovalThreshold; threshold > 0 && req.Amount > threshold {
+		server.requestTransferApproval(ctx, arg, authPayload.Username, idempotentArg.RequestHash)
+		return
+	}
+
+	var transferTxResult db.TransferTxResult
+
+	// Create a new transfer, at most once per idempotency key if the client sent one
+	if idempotencyKey != "" {
+		transferTxResult, err = server.store.IdempotentTransferTx(ctx, idempotentArg)
+	} else {
+		transferTxResult, err = server.store.TransferTx(ctx, arg)
+	}
+
+	if err != nil {
+		transferErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTransferResponse(transferTxResult, toAccount, authPayload.Username))
+}
+
+// newTransferResponse returns the whole result of the transfer if the user owns the receiver account, otherwise the
+// account and the entry of the receiver are left out so that their balance cannot be found out by sending them money
+func newTransferResponse(result db.TransferTxResult, toAccount db.Account, username string) interface{} {
+	if toAccount.Owner == username {
+		return result
+	}
+
+	return sentTransferResponse{
+		Transfer:    result.Transfer,
+		FromAccount: result.FromAccount,
+		FromEntry:   result.FromEntry,
+		Fee:         result.Fee,
+	}
+}
+
+// transferErrorResponse responds with the status matching the reason the store could not make a transfer
+func transferErrorResponse(ctx *gin.Context, err error) {
+	var limitErr *db.TransferLimitError
+
+	if errors.As(err, &limitErr) {
+		ctx.JSON(http.StatusUnprocessableEntity, transferLimitErrorResponse(limitErr))
+		return
+	}
+
+	if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrExchangeRateNotFound) ||
+		errors.Is(err, db.ErrConvertedAmountTooSmall) {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	if errors.Is(err, db.ErrIdempotencyKeyReused) {
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
+
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, req.ID)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if !hasPermission(authPayload.Role, permissionViewAnyAccount) {
+		// Only the owners of the accounts on either side of the transfer can view it
+		isOwner, err := server.ownsAnyAccount(ctx, authPayload.Username, transfer.FromAccountID, transfer.ToAccountID)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if !isOwner {
+			err := errors.New("transfer does not belong to the authenticated user")
+
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var uri getTransferRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req reverseTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	result, err := server.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: uri.ID,
+		Reason:     req.Reason,
+		ReversedBy: authPayload.Username,
+	})
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrTransferAlreadyReversed) || errors.Is(err, db.ErrCannotReverseReversal) ||
+			errors.Is(err, db.ErrTransferNotPosted) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// ownsAnyAccount returns true if the user owns at least one of the accounts
+func (server *Server) ownsAnyAccount(ctx *gin.Context, username string, accountIDs ...int64) (bool, error) {
+	for _, accountID := range accountIDs {
+		account, err := server.store.GetAccount(ctx, int32(accountID))
+
+		if err != nil {
+			return false, err
+		}
+
+		if account.Owner == username {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var req listTransfersRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// Users list the transfers of their own accounts unless they are allowed to view the accounts of other customers
+	owner := authPayload.Username
+
+	if req.Owner != "" && req.Owner != authPayload.Username {
+		if !hasPermission(authPayload.Role, permissionViewAnyAccount) {
+			err := errors.New("cannot list the transfers of another user")
+
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		owner = req.Owner
+	}
+
+	metadata, err := transferMetadata(json.RawMessage(req.Metadata))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.ListOwnerTransfersParams{
+		Owner:       owner,
+		Direction:   req.Direction,
+		AccountID:   req.AccountID,
+		CreatedFrom: req.From,
+		CreatedTo:   req.To,
+		MinAmount:   req.MinAmount,
+		MaxAmount:   req.MaxAmount,
+		Search:      req.Search,
+		Metadata:    metadata,
+		Limit:       req.PageSize,
+		Offset:      (req.PageID - 1) * req.PageSize,
+	}
+
+	// Filters left empty by the client match every transfer
+	if arg.CreatedTo.IsZero() {
+		arg.CreatedTo = maxTransferTime
+	}
+
+	if arg.MaxAmount == 0 {
+		arg.MaxAmount = math.MaxInt64
+	}
+
+	if arg.Metadata == nil {
+		arg.Metadata = emptyTransferMetadata
+	}
+
+	transfers, err := server.store.ListOwnerTransfers(ctx, arg)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, transferTxResult)
+	ctx.JSON(http.StatusOK, transfers)
+}
+
+// hashTransferRequest returns a hash identifying the request, retries of the same transfer have the same hash
+func hashTransferRequest(req createTransferRequest) string {
+	// Marshalling the bound request ignores formatting and field order differences of the raw body
+	data, _ := json.Marshal(req)
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
 }
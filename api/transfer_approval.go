@@ -0,0 +1,293 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/token"
+	"net/http"
+	"time"
+)
+
+// transferApprovalExpiry is how long the money of a transfer awaiting approval stays held
+const transferApprovalExpiry = 24 * time.Hour
+
+type (
+	getTransferApprovalRequest struct {
+		ID int64 `uri:"id" binding:"required,min=1"`
+	}
+
+	listTransferApprovalsRequest struct {
+		Status   string `form:"status" binding:"omitempty,oneof=pending approved rejected expired"`
+		PageID   int32  `form:"page_id" binding:"required,min=1"`
+		PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+	}
+
+	rejectTransferRequest struct {
+		Reason string `json:"reason" binding:"required,max=255"`
+	}
+
+	// transferApprovalResponse leaves out the idempotency key and the request hash, they are only used for retries
+	transferApprovalResponse struct {
+		ID            int64           `json:"id"`
+		FromAccountID int64           `json:"fromAccountID"`
+		ToAccountID   int64           `json:"toAccountID"`
+		Amount        int64           `json:"amount"`
+		Memo          string          `json:"memo"`
+		Reference     string          `json:"reference"`
+		Metadata      json.RawMessage `json:"metadata"`
+		RequestedBy   string          `json:"requestedBy"`
+		Status        string          `json:"status"`
+		DecidedBy     *string         `json:"decidedBy"`
+		Reason        string          `json:"reason"`
+		TransferID    *int64          `json:"transferID"`
+		ExpiresAt     time.Time       `json:"expiresAt"`
+		CreatedAt     time.Time       `json:"createdAt"`
+	}
+
+	requestTransferApprovalResponse struct {
+		Approval    transferApprovalResponse `json:"approval"`
+		FromAccount db.Account               `json:"fromAccount"`
+	}
+
+	approveTransferResponse struct {
+		db.TransferTxResult
+		Approval transferApprovalResponse `json:"approval"`
+	}
+)
+
+// nullableString returns nil for a NULL value so that it is serialized as null
+func nullableString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+
+	return &s.String
+}
+
+func newTransferApprovalResponse(approval db.TransferApproval) transferApprovalResponse {
+	return transferApprovalResponse{
+		ID:            approval.ID,
+		FromAccountID: approval.FromAccountID,
+		ToAccountID:   approval.ToAccountID,
+		Amount:        approval.Amount,
+		Memo:          approval.Memo,
+		Reference:     approval.Reference,
+		Metadata:      approval.Metadata,
+		RequestedBy:   approval.RequestedBy,
+		Status:        approval.Status,
+		DecidedBy:     nullableString(approval.DecidedBy),
+		Reason:        approval.Reason,
+		TransferID:    nullableInt64(approval.TransferID),
+		ExpiresAt:     approval.ExpiresAt,
+		CreatedAt:     approval.CreatedAt,
+	}
+}
+
+// isBelowApprovalThreshold responds with an error if the amount is above the approval threshold. It is used for the
+// transfers which cannot wait for an approval, kind says how they are made.
+func (server *Server) isBelowApprovalThreshold(ctx *gin.Context, amount int64, kind string) bool {
+	if threshold := server.config.TransferApprovalThreshold; threshold > 0 && amount > threshold {
+		err := fmt.Errorf("transfers of more than %d must be approved, they cannot be %s", threshold, kind)
+
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// requestTransferApproval holds the money of the transfer until a user allowed to approve transfers decides on it
+func (server *Server) requestTransferApproval(ctx *gin.Context, arg db.TransferTxParams, username, requestHash string) {
+	result, err := server.store.RequestTransferApprovalTx(ctx, db.RequestTransferApprovalTxParams{
+		TransferTxParams: arg,
+		RequestedBy:      username,
+		IdempotencyKey:   ctx.GetHeader(idempotencyKeyHeader),
+		RequestHash:      requestHash,
+		ExpiresAt:        time.Now().Add(transferApprovalExpiry),
+	})
+
+	if err != nil {
+		transferErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, newRequestTransferApprovalResponse(result))
+}
+
+func newRequestTransferApprovalResponse(result db.RequestTransferApprovalTxResult) requestTransferApprovalResponse {
+	return requestTransferApprovalResponse{
+		Approval:    newTransferApprovalResponse(result.Approval),
+		FromAccount: result.FromAccount,
+	}
+}
+
+func (server *Server) listTransferApprovals(ctx *gin.Context) {
+	var req listTransferApprovalsRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.ListTransferApprovalsParams{
+		Status: req.Status,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	// Users who cannot approve transfers only see the approvals they requested and those of the accounts they co-own
+	if !hasPermission(authPayload.Role, permissionApproveTransfers) {
+		arg.Username = authPayload.Username
+	}
+
+	approvals, err := server.store.ListTransferApprovals(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]transferApprovalResponse, len(approvals))
+
+	for i, approval := range approvals {
+		rsp[i] = newTransferApprovalResponse(approval)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) getTransferApproval(ctx *gin.Context) {
+	var req getTransferApprovalRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	approval, err := server.store.GetTransferApproval(ctx, req.ID)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if approval.RequestedBy != authPayload.Username && !hasPermission(authPayload.Role, permissionApproveTransfers) {
+		// Co-owners of the sender account see the approvals they may decide
+		_, err = server.store.GetAccountCoOwner(ctx, db.GetAccountCoOwnerParams{
+			AccountID: approval.FromAccountID,
+			Username:  authPayload.Username,
+		})
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				err := errors.New("transfer approval does not belong to the authenticated user")
+
+				ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, newTransferApprovalResponse(approval))
+}
+
+// transferApprovalErrorResponse responds with the status matching the reason the store could not decide an approval
+func transferApprovalErrorResponse(ctx *gin.Context, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+
+	if errors.Is(err, db.ErrTransferApprovalNotPending) || errors.Is(err, db.ErrTransferApprovalExpired) {
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	if errors.Is(err, db.ErrApproverIsRequester) || errors.Is(err, db.ErrApproverNotCoOwner) ||
+		errors.Is(err, db.ErrCashOnOwnAccount) {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	transferErrorResponse(ctx, err)
+}
+
+func (server *Server) approveTransfer(ctx *gin.Context) {
+	var uri getTransferApprovalRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// Users who cannot approve transfers may still decide the transfers of the accounts they co-own
+	result, err := server.store.ApproveTransferTx(ctx, db.DecideTransferTxParams{
+		ID:           uri.ID,
+		DecidedBy:    authPayload.Username,
+		CanDecideAny: hasPermission(authPayload.Role, permissionApproveTransfers),
+	})
+
+	if err != nil {
+		transferApprovalErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, approveTransferResponse{
+		TransferTxResult: result.TransferTxResult,
+		Approval:         newTransferApprovalResponse(result.Approval),
+	})
+}
+
+func (server *Server) rejectTransfer(ctx *gin.Context) {
+	var uri getTransferApprovalRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req rejectTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	approval, err := server.store.RejectTransferTx(ctx, db.DecideTransferTxParams{
+		ID:           uri.ID,
+		DecidedBy:    authPayload.Username,
+		Reason:       req.Reason,
+		CanDecideAny: hasPermission(authPayload.Role, permissionApproveTransfers),
+	})
+
+	if err != nil {
+		transferApprovalErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTransferApprovalResponse(approval))
+}
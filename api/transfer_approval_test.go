@@ -0,0 +1,652 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func createRandomTransferApproval(requestedBy string) db.TransferApproval {
+	return db.TransferApproval{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: util.RandomInt(1, 1000),
+		ToAccountID:   util.RandomInt(1, 1000),
+		Amount:        util.RandomMoney(),
+		Metadata:      json.RawMessage("{}"),
+		RequestedBy:   requestedBy,
+		Status:        util.TransferApprovalPending,
+		ExpiresAt:     time.Now().Add(transferApprovalExpiry).Truncate(time.Second).UTC(),
+	}
+}
+
+func TestCreateTransferApproval(t *testing.T) {
+	user, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+
+	fromAccount := createRandomAccount(user.Username)
+	toAccount := createRandomAccount(recipient.Username)
+
+	fromAccount.Currency = util.USD
+	toAccount.Currency = util.USD
+
+	testCases := []struct {
+		name          string
+		amount        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "BelowThreshold",
+			amount: 1000,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "AboveThreshold",
+			amount: 1001,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RequestTransferApprovalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.RequestTransferApprovalTxParams) (db.RequestTransferApprovalTxResult, error) {
+						require.Equal(t, int64(fromAccount.ID), arg.FromAccountID)
+						require.Equal(t, int64(toAccount.ID), arg.ToAccountID)
+						require.Equal(t, int64(1001), arg.Amount)
+						require.Equal(t, user.Username, arg.RequestedBy)
+						require.NotEmpty(t, arg.RequestHash)
+						require.WithinDuration(t, time.Now().Add(transferApprovalExpiry), arg.ExpiresAt, time.Minute)
+
+						approval := createRandomTransferApproval(arg.RequestedBy)
+						approval.Amount = arg.Amount
+
+						return db.RequestTransferApprovalTxResult{Approval: approval, FromAccount: fromAccount}, nil
+					})
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var got map[string]map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, util.TransferApprovalPending, got["approval"]["status"])
+				require.Nil(t, got["approval"]["decidedBy"])
+				require.NotContains(t, got["approval"], "idempotencyKey")
+			},
+		},
+		{
+			name:   "InsufficientFunds",
+			amount: 5000,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RequestTransferApprovalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RequestTransferApprovalTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			server.config.TransferApprovalThreshold = 1000
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          tc.amount,
+				"currency":        util.USD,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/v1/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListTransferApprovals(t *testing.T) {
+	approvals := []db.TransferApproval{
+		createRandomTransferApproval("alice"),
+		createRandomTransferApproval("alice"),
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "DepositorSeesOwnApprovals",
+			query: "page_id=1&page_size=5&status=pending",
+			role:  util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListTransferApprovalsParams{
+					Username: "alice",
+					Status:   util.TransferApprovalPending,
+					Limit:    5,
+					Offset:   0,
+				}
+
+				store.EXPECT().ListTransferApprovals(gomock.Any(), gomock.Eq(arg)).Times(1).Return(approvals, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []transferApprovalResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got, len(approvals))
+				require.Equal(t, newTransferApprovalResponse(approvals[0]), got[0])
+			},
+		},
+		{
+			name:  "BankerSeesAllApprovals",
+			query: "page_id=2&page_size=5",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListTransferApprovalsParams{
+					Limit:  5,
+					Offset: 5,
+				}
+
+				store.EXPECT().ListTransferApprovals(gomock.Any(), gomock.Eq(arg)).Times(1).Return(approvals, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidStatus",
+			query: "page_id=1&page_size=5&status=held",
+			role:  util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransferApprovals(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "page_id=1&page_size=5",
+			role:  util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListTransferApprovals(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.TransferApproval{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/transfer-approvals?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "alice", tc.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetTransferApproval(t *testing.T) {
+	approval := createRandomTransferApproval("alice")
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Requester",
+			username: "alice",
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(approval.ID)).Times(1).Return(approval, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got transferApprovalResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, newTransferApprovalResponse(approval), got)
+			},
+		},
+		{
+			name:     "Banker",
+			username: "officer",
+			role:     util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(approval.ID)).Times(1).Return(approval, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "CoOwner",
+			username: "bob",
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(approval.ID)).Times(1).Return(approval, nil)
+
+				arg := db.GetAccountCoOwnerParams{
+					AccountID: approval.FromAccountID,
+					Username:  "bob",
+				}
+
+				store.EXPECT().
+					GetAccountCoOwner(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.AccountCoOwner{AccountID: arg.AccountID, Username: arg.Username}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			username: "mallory",
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferApproval(gomock.Any(), gomock.Eq(approval.ID)).Times(1).Return(approval, nil)
+				store.EXPECT().
+					GetAccountCoOwner(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountCoOwner{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: "alice",
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransferApproval(gomock.Any(), gomock.Eq(approval.ID)).
+					Times(1).
+					Return(db.TransferApproval{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/transfer-approvals/%d", approval.ID)
+
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestApproveTransfer(t *testing.T) {
+	approval := createRandomTransferApproval("alice")
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DecideTransferTxParams{
+					ID:           approval.ID,
+					DecidedBy:    "officer",
+					CanDecideAny: true,
+				}
+
+				approved := approval
+				approved.Status = util.TransferApprovalApproved
+				approved.DecidedBy = sql.NullString{String: "officer", Valid: true}
+				approved.TransferID = sql.NullInt64{Int64: 9, Valid: true}
+
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ApproveTransferTxResult{
+						TransferTxResult: db.TransferTxResult{Transfer: db.Transfer{ID: 9}},
+						Approval:         approved,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got approveTransferResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, int64(9), got.Transfer.ID)
+				require.Equal(t, util.TransferApprovalApproved, got.Approval.Status)
+				require.Equal(t, "officer", *got.Approval.DecidedBy)
+				require.Equal(t, int64(9), *got.Approval.TransferID)
+			},
+		},
+		{
+			name: "CoOwner",
+			role: util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				// Depositors may only decide the transfers of the accounts they co-own, the store checks it
+				arg := db.DecideTransferTxParams{
+					ID:        approval.ID,
+					DecidedBy: "officer",
+				}
+
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ApproveTransferTxResult{Approval: approval}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "DepositorNotCoOwner",
+			role: util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveTransferTxResult{}, db.ErrApproverNotCoOwner)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "ApproverIsRequester",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveTransferTxResult{}, db.ErrApproverIsRequester)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "ApproverOwnsDepositAccount",
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveTransferTxResult{}, db.ErrCashOnOwnAccount)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NotPending",
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveTransferTxResult{}, db.ErrTransferApprovalNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Expired",
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveTransferTxResult{}, db.ErrTransferApprovalExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveTransferTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/transfer-approvals/%d/approve", approval.ID)
+
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "officer", tc.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRejectTransfer(t *testing.T) {
+	approval := createRandomTransferApproval("alice")
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"reason": "Unknown recipient"},
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DecideTransferTxParams{
+					ID:           approval.ID,
+					DecidedBy:    "officer",
+					Reason:       "Unknown recipient",
+					CanDecideAny: true,
+				}
+
+				rejected := approval
+				rejected.Status = util.TransferApprovalRejected
+				rejected.Reason = arg.Reason
+
+				store.EXPECT().RejectTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(rejected, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got transferApprovalResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, util.TransferApprovalRejected, got.Status)
+				require.Equal(t, "Unknown recipient", got.Reason)
+			},
+		},
+		{
+			name: "MissingReason",
+			body: gin.H{},
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().RejectTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TellerNotCoOwner",
+			body: gin.H{"reason": "Unknown recipient"},
+			role: util.TellerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DecideTransferTxParams{
+					ID:        approval.ID,
+					DecidedBy: "officer",
+					Reason:    "Unknown recipient",
+				}
+
+				store.EXPECT().
+					RejectTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferApproval{}, db.ErrApproverNotCoOwner)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NotPending",
+			body: gin.H{"reason": "Unknown recipient"},
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RejectTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferApproval{}, db.ErrTransferApprovalNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/v1/transfer-approvals/%d/reject", approval.ID)
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "officer", tc.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/risk"
+	"github.com/jwambugu/go-simple-bank-class/token"
+	"net/http"
+)
+
+type (
+	transferBatchLegRequest struct {
+		ToAccountID int64 `json:"to_account_id" binding:"required,min=1"`
+		Amount      int64 `json:"amount" binding:"required,gt=0"`
+	}
+
+	createTransferBatchRequest struct {
+		FromAccountID int64                     `json:"from_account_id" binding:"required,min=1"`
+		Currency      string                    `json:"currency" binding:"required,currency"`
+		Legs          []transferBatchLegRequest `json:"legs" binding:"required,min=1,max=500,dive"`
+	}
+)
+
+func (server *Server) createTransferBatch(ctx *gin.Context) {
+	var req createTransferBatchRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.BulkTransferTxParams{
+		FromAccountID: req.FromAccountID,
+		Legs:          make([]db.BulkTransferLeg, len(req.Legs)),
+	}
+
+	var total int64
+
+	for i, leg := range req.Legs {
+		if leg.ToAccountID == req.FromAccountID {
+			err := fmt.Errorf("leg %d: cannot transfer to the from account", i)
+
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		arg.Legs[i] = db.BulkTransferLeg{
+			ToAccountID: leg.ToAccountID,
+			Amount:      leg.Amount,
+		}
+
+		total += leg.Amount
+	}
+
+	// Check if the sender account is valid
+	fromAccount, isValid := server.isValidAccount(ctx, req.FromAccountID, req.Currency)
+
+	if !isValid {
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account does not belong to the authenticated user")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// The whole batch leaves the sender account at once, so it is compared to the threshold rather than each leg on its
+	// own which would let a large transfer be split up to get around it
+	if !server.isBelowApprovalThreshold(ctx, total, "batched") {
+		return
+	}
+
+	// Every leg is assessed on its own, the batch is not made if the rules do not allow one of them
+	for _, leg := range req.Legs {
+		isAllowed := server.assessTransfer(ctx, risk.Transfer{
+			Username:      authPayload.Username,
+			FromAccountID: req.FromAccountID,
+			ToAccountID:   leg.ToAccountID,
+			Amount:        leg.Amount,
+			Currency:      fromAccount.Currency,
+		})
+
+		if !isAllowed {
+			return
+		}
+	}
+
+	// The receivers are checked by the transaction, errors about a single leg tell which one failed
+	result, err := server.store.BulkTransferTx(ctx, arg)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		var limitErr *db.TransferLimitError
+
+		if errors.As(err, &limitErr) {
+			ctx.JSON(http.StatusUnprocessableEntity, transferLimitErrorResponse(limitErr))
+			return
+		}
+
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrExchangeRateNotFound) ||
+			errors.Is(err, db.ErrConvertedAmountTooSmall) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
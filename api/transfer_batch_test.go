@@ -0,0 +1,238 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreateTransferBatch(t *testing.T) {
+	user, _ := randomUser(t)
+	otherUser, _ := randomUser(t)
+
+	fromAccount := createRandomAccount(user.Username)
+	fromAccount.Currency = util.USD
+
+	accountOne := createRandomAccount(otherUser.Username)
+	accountTwo := createRandomAccount(otherUser.Username)
+
+	// The receivers must differ from the sender for the batch to be valid
+	accountOne.ID = fromAccount.ID + 1
+	accountTwo.ID = fromAccount.ID + 2
+
+	legs := []gin.H{
+		{"to_account_id": accountOne.ID, "amount": 100},
+		{"to_account_id": accountTwo.ID, "amount": 200},
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "StatusOK",
+			body:     gin.H{"from_account_id": fromAccount.ID, "currency": util.USD, "legs": legs},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+
+				arg := db.BulkTransferTxParams{
+					FromAccountID: int64(fromAccount.ID),
+					Legs: []db.BulkTransferLeg{
+						{ToAccountID: int64(accountOne.ID), Amount: 100},
+						{ToAccountID: int64(accountTwo.ID), Amount: 200},
+					},
+				}
+
+				result := db.BulkTransferTxResult{
+					Batch: db.TransferBatch{ID: 1, FromAccountID: int64(fromAccount.ID), TotalAmount: 300},
+					Legs:  make([]db.BulkTransferLegResult, 2),
+				}
+
+				store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.BulkTransferTxResult
+
+				err := json.Unmarshal(recorder.Body.Bytes(), &result)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), result.Batch.ID)
+				require.Len(t, result.Legs, 2)
+			},
+		},
+		{
+			name:     "NoLegs",
+			body:     gin.H{"from_account_id": fromAccount.ID, "currency": util.USD, "legs": []gin.H{}},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AboveApprovalThreshold",
+			body: gin.H{"from_account_id": fromAccount.ID, "currency": util.USD, "legs": []gin.H{
+				{"to_account_id": accountOne.ID, "amount": 600},
+				{"to_account_id": accountTwo.ID, "amount": 600},
+			}},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				// Each leg is below the threshold, the batch as a whole is not
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidLeg",
+			body: gin.H{"from_account_id": fromAccount.ID, "currency": util.USD, "legs": []gin.H{
+				{"to_account_id": accountOne.ID, "amount": -1},
+			}},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "LegToFromAccount",
+			body: gin.H{"from_account_id": fromAccount.ID, "currency": util.USD, "legs": []gin.H{
+				{"to_account_id": fromAccount.ID, "amount": 100},
+			}},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "CurrencyMismatch",
+			body:     gin.H{"from_account_id": fromAccount.ID, "currency": util.EUR, "legs": legs},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			body:     gin.H{"from_account_id": fromAccount.ID, "currency": util.USD, "legs": legs},
+			username: otherUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "ReceiverNotFound",
+			body:     gin.H{"from_account_id": fromAccount.ID, "currency": util.USD, "legs": legs},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.BulkTransferTxResult{}, &db.LegError{Index: 1, Err: sql.ErrNoRows})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "leg 1")
+			},
+		},
+		{
+			name:     "InsufficientFunds",
+			body:     gin.H{"from_account_id": fromAccount.ID, "currency": util.USD, "legs": legs},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.BulkTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "ExchangeRateNotFound",
+			body:     gin.H{"from_account_id": fromAccount.ID, "currency": util.USD, "legs": legs},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.BulkTransferTxResult{}, &db.LegError{Index: 0, Err: db.ErrExchangeRateNotFound})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalServerError",
+			body:     gin.H{"from_account_id": fromAccount.ID, "currency": util.USD, "legs": legs},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().BulkTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.BulkTransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			server.config.TransferApprovalThreshold = 1000
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/v1/transfer-batches", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
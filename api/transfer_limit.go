@@ -0,0 +1,124 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"net/http"
+)
+
+type (
+	transferLimitURIRequest struct {
+		Scope    string `uri:"scope" binding:"required,oneof=user role"`
+		Subject  string `uri:"subject" binding:"required,alphanum"`
+		Currency string `uri:"currency" binding:"required,currency"`
+	}
+
+	upsertTransferLimitRequest struct {
+		PerTransaction int64 `json:"per_transaction" binding:"min=0"`
+		Daily          int64 `json:"daily" binding:"min=0"`
+		Monthly        int64 `json:"monthly" binding:"min=0"`
+	}
+)
+
+// transferLimitErrorResponse tells the client which limit the transfer exceeded and how much they can still transfer
+func transferLimitErrorResponse(err *db.TransferLimitError) gin.H {
+	return gin.H{
+		"error":              err.Error(),
+		"limit":              err.Period,
+		"currency":           err.Currency,
+		"remainingAllowance": err.Remaining,
+	}
+}
+
+func (server *Server) listTransferLimits(ctx *gin.Context) {
+	limits, err := server.store.ListTransferLimits(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limits)
+}
+
+func (server *Server) upsertTransferLimit(ctx *gin.Context) {
+	var uri transferLimitURIRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req upsertTransferLimitRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Role limits apply to every user of the role, which must exist like the user of a user limit
+	if uri.Scope == util.TransferLimitRoleScope {
+		if !util.IsSupportedRole(uri.Subject) {
+			err := errors.New("unsupported role")
+
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	} else if _, err := server.store.GetUser(ctx, uri.Subject); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.UpsertTransferLimitParams{
+		Scope:          uri.Scope,
+		Subject:        uri.Subject,
+		Currency:       uri.Currency,
+		PerTransaction: req.PerTransaction,
+		Daily:          req.Daily,
+		Monthly:        req.Monthly,
+	}
+
+	limit, err := server.store.UpsertTransferLimit(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limit)
+}
+
+func (server *Server) deleteTransferLimit(ctx *gin.Context) {
+	var uri transferLimitURIRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.DeleteTransferLimitParams{
+		Scope:    uri.Scope,
+		Subject:  uri.Subject,
+		Currency: uri.Currency,
+	}
+
+	if _, err := server.store.DeleteTransferLimit(ctx, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,346 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func randomTransferLimit(scope, subject string) db.TransferLimit {
+	return db.TransferLimit{
+		Scope:          scope,
+		Subject:        subject,
+		Currency:       util.USD,
+		PerTransaction: 100,
+		Daily:          1000,
+		Monthly:        10000,
+		UpdatedAt:      time.Now(),
+	}
+}
+
+func TestListTransferLimits(t *testing.T) {
+	limits := []db.TransferLimit{randomTransferLimit(util.TransferLimitRoleScope, util.DepositorRole)}
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "StatusOK",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransferLimits(gomock.Any()).Times(1).Return(limits, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotLimits []db.TransferLimit
+
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotLimits)
+				require.NoError(t, err)
+				require.Len(t, gotLimits, 1)
+				require.Equal(t, limits[0].Daily, gotLimits[0].Daily)
+			},
+		},
+		{
+			name: "Forbidden",
+			role: util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransferLimits(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InternalServerError",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransferLimits(gomock.Any()).Times(1).Return([]db.TransferLimit{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/transfer-limits", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpsertTransferLimit(t *testing.T) {
+	user, _ := randomUser(t)
+	userLimit := randomTransferLimit(util.TransferLimitUserScope, user.Username)
+	roleLimit := randomTransferLimit(util.TransferLimitRoleScope, util.DepositorRole)
+
+	body := gin.H{"per_transaction": 100, "daily": 1000, "monthly": 10000}
+
+	testCases := []struct {
+		name          string
+		scope         string
+		subject       string
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "UserLimit",
+			scope:   userLimit.Scope,
+			subject: userLimit.Subject,
+			body:    body,
+			role:    util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+
+				arg := db.UpsertTransferLimitParams{
+					Scope:          userLimit.Scope,
+					Subject:        userLimit.Subject,
+					Currency:       userLimit.Currency,
+					PerTransaction: userLimit.PerTransaction,
+					Daily:          userLimit.Daily,
+					Monthly:        userLimit.Monthly,
+				}
+
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Eq(arg)).Times(1).Return(userLimit, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "RoleLimit",
+			scope:   roleLimit.Scope,
+			subject: roleLimit.Subject,
+			body:    gin.H{"daily": 1000},
+			role:    util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+
+				arg := db.UpsertTransferLimitParams{
+					Scope:    roleLimit.Scope,
+					Subject:  roleLimit.Subject,
+					Currency: roleLimit.Currency,
+					Daily:    1000,
+				}
+
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Eq(arg)).Times(1).Return(roleLimit, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "Forbidden",
+			scope:   userLimit.Scope,
+			subject: userLimit.Subject,
+			body:    body,
+			role:    util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:    "UnsupportedScope",
+			scope:   "tier",
+			subject: userLimit.Subject,
+			body:    body,
+			role:    util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "UnsupportedRole",
+			scope:   util.TransferLimitRoleScope,
+			subject: "manager",
+			body:    body,
+			role:    util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "NegativeLimit",
+			scope:   userLimit.Scope,
+			subject: userLimit.Subject,
+			body:    gin.H{"daily": -1},
+			role:    util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "UserNotFound",
+			scope:   userLimit.Scope,
+			subject: userLimit.Subject,
+			body:    body,
+			role:    util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:    "InternalServerError",
+			scope:   roleLimit.Scope,
+			subject: roleLimit.Subject,
+			body:    body,
+			role:    util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferLimit{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/v1/transfer-limits/%s/%s/%s", testCase.scope, testCase.subject, util.USD)
+
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteTransferLimit(t *testing.T) {
+	limit := randomTransferLimit(util.TransferLimitRoleScope, util.DepositorRole)
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "StatusNoContent",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DeleteTransferLimitParams{
+					Scope:    limit.Scope,
+					Subject:  limit.Subject,
+					Currency: limit.Currency,
+				}
+
+				store.EXPECT().DeleteTransferLimit(gomock.Any(), gomock.Eq(arg)).Times(1).Return(limit, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "Forbidden",
+			role: util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteTransferLimit(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferLimit{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/transfer-limits/%s/%s/%s", limit.Scope, limit.Subject, limit.Currency)
+
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", testCase.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			testCase.checkResponse(t, recorder)
+		})
+	}
+}
@@ -2,8 +2,10 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
@@ -11,6 +13,7 @@ import (
 	"github.com/jwambugu/go-simple-bank-class/token"
 	"github.com/jwambugu/go-simple-bank-class/util"
 	"github.com/stretchr/testify/require"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -48,7 +51,7 @@ func TestCreateTransfer(t *testing.T) {
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
@@ -68,6 +71,126 @@ func TestCreateTransfer(t *testing.T) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "WithMemoReferenceAndMetadata",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+				"memo":            "Rent for March",
+				"reference":       "INV-2021-03",
+				"metadata":        gin.H{"invoice": "INV-2021-03", "lines": []int{1, 2}},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: int64(fromAccount.ID),
+					ToAccountID:   int64(toAccount.ID),
+					Amount:        int64(amountToTransfer),
+					Memo:          "Rent for March",
+					Reference:     "INV-2021-03",
+					Metadata:      json.RawMessage(`{"invoice":"INV-2021-03","lines":[1,2]}`),
+				}
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NullMetadata",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+				"metadata":        nil,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(fromAccount, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.Nil(t, arg.Metadata)
+
+						return db.TransferTxResult{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MetadataNotAnObject",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+				"metadata":        "INV-2021-03",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MetadataTooLarge",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+				"metadata":        gin.H{"notes": util.RandomString(maxTransferMetadataSize)},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MemoTooLong",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+				"memo":            util.RandomString(141),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "GetAccountError",
 			body: gin.H{
@@ -77,7 +200,7 @@ func TestCreateTransfer(t *testing.T) {
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
@@ -97,7 +220,7 @@ func TestCreateTransfer(t *testing.T) {
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).
@@ -114,44 +237,83 @@ func TestCreateTransfer(t *testing.T) {
 			},
 		},
 		{
-			name: "StatusBadRequest",
-			body: gin.H{},
+			name: "InsufficientFunds",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
-				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).
+					Return(fromAccount, nil)
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).
+					Return(toAccount, nil)
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
 			},
 		},
 		{
-			name: "FromAccountNotFound",
+			name: "ToUsername",
 			body: gin.H{
 				"from_account_id": fromAccount.ID,
-				"to_account_id":   toAccount.ID,
+				"to_username":     toAccountUser.Username,
 				"amount":          amountToTransfer,
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).
-					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
 
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(0)
-				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				recipient := db.GetRecipientAccountParams{
+					Username: toAccountUser.Username,
+					Currency: util.USD,
+				}
+
+				store.EXPECT().GetRecipientAccount(gomock.Any(), gomock.Eq(recipient)).Times(1).Return(toAccount, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: int64(fromAccount.ID),
+					ToAccountID:   int64(toAccount.ID),
+					Amount:        int64(amountToTransfer),
+				}
+
+				result := db.TransferTxResult{
+					Transfer:    db.Transfer{ID: 1, FromAccountID: arg.FromAccountID, ToAccountID: arg.ToAccountID},
+					FromAccount: fromAccount,
+					ToAccount:   toAccount,
+					FromEntry:   db.Entry{ID: 1, AccountID: arg.FromAccountID, Amount: -arg.Amount},
+					ToEntry:     db.Entry{ID: 2, AccountID: arg.ToAccountID, Amount: arg.Amount},
+				}
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// The sender does not get to see the balance of the receiver
+				var response map[string]json.RawMessage
+
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Contains(t, response, "transfer")
+				require.Contains(t, response, "from_account")
+				require.NotContains(t, response, "to_account")
+				require.NotContains(t, response, "to_entry")
 			},
 		},
 		{
-			name: "ToAccountNotFound",
+			name: "ToOwnAccount",
 			body: gin.H{
 				"from_account_id": fromAccount.ID,
 				"to_account_id":   toAccount.ID,
@@ -159,36 +321,102 @@ func TestCreateTransfer(t *testing.T) {
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				ownAccount := toAccount
+				ownAccount.Owner = fromAccountUser.Username
+
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(ownAccount.ID)).Times(1).Return(ownAccount, nil)
 
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).
-					Return(db.Account{}, sql.ErrNoRows)
+				result := db.TransferTxResult{
+					FromAccount: fromAccount,
+					ToAccount:   ownAccount,
+				}
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response db.TransferTxResult
+
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, toAccount.ID, response.ToAccount.ID)
+			},
+		},
+		{
+			name: "ToEmailInAnotherCurrency",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_email":        mockAccountUser.Email,
+				"to_currency":     util.EUR,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+
+				recipient := db.GetRecipientAccountParams{
+					Email:    mockAccountUser.Email,
+					Currency: util.EUR,
+				}
+
+				store.EXPECT().GetRecipientAccount(gomock.Any(), gomock.Eq(recipient)).Times(1).Return(mockAccount, nil)
 
+				arg := db.TransferTxParams{
+					FromAccountID: int64(fromAccount.ID),
+					ToAccountID:   int64(mockAccount.ID),
+					Amount:        int64(amountToTransfer),
+				}
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RecipientNotFound",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_username":     toAccountUser.Username,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetRecipientAccount(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errRecipientNotFound.Error())
 			},
 		},
 		{
-			name: "FromAccountCurrencyMismatch",
+			name: "MultipleRecipients",
 			body: gin.H{
-				"from_account_id": mockAccount.ID,
+				"from_account_id": fromAccount.ID,
 				"to_account_id":   toAccount.ID,
+				"to_username":     toAccountUser.Username,
 				"amount":          amountToTransfer,
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(mockAccount.ID)).Times(1).Return(mockAccount, nil)
-
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(0)
-
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -196,44 +424,396 @@ func TestCreateTransfer(t *testing.T) {
 			},
 		},
 		{
-			name: "ToAccountCurrencyMismatch",
+			name: "NoRecipient",
 			body: gin.H{
 				"from_account_id": fromAccount.ID,
-				"to_account_id":   mockAccount.ID,
 				"amount":          amountToTransfer,
 				"currency":        util.USD,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(mockAccount.ID)).Times(1).Return(mockAccount, nil)
-
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
-	}
+		{
+			name: "ToCurrencyWithAccountID",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"to_currency":     util.EUR,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TransferLimitExceeded",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).
+					Return(fromAccount, nil)
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).
+					Return(toAccount, nil)
 
-			store := mockdb.NewMockStore(ctrl)
+				limitErr := &db.TransferLimitError{
+					Period:    util.DailyLimit,
+					Currency:  util.USD,
+					Limit:     100,
+					Remaining: 5,
+				}
 
-			tc.buildStubs(store)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{}, limitErr)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
 
-			server := newTestServer(t, store)
-			recorder := httptest.NewRecorder()
+				var body struct {
+					Limit              string `json:"limit"`
+					RemainingAllowance int64  `json:"remainingAllowance"`
+				}
 
-			url := "/v1/transfers"
+				err := json.Unmarshal(recorder.Body.Bytes(), &body)
+				require.NoError(t, err)
+				require.Equal(t, util.DailyLimit, body.Limit)
+				require.Equal(t, int64(5), body.RemainingAllowance)
+			},
+		},
+		{
+			name: "IdempotentTransfer",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+				request.Header.Set(idempotencyKeyHeader, "transfer-key")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
 
-			requestBody, err := json.Marshal(tc.body)
-			require.NoError(t, err)
+				arg := db.IdempotentTransferTxParams{
+					TransferTxParams: db.TransferTxParams{
+						FromAccountID: int64(fromAccount.ID),
+						ToAccountID:   int64(toAccount.ID),
+						Amount:        int64(amountToTransfer),
+					},
+					Username:       fromAccountUser.Username,
+					IdempotencyKey: "transfer-key",
+					RequestHash: hashTransferRequest(createTransferRequest{
+						FromAccountID: int64(fromAccount.ID),
+						ToAccountID:   int64(toAccount.ID),
+						Amount:        int64(amountToTransfer),
+						Currency:      util.USD,
+					}),
+				}
+
+				store.EXPECT().ReplayTransfer(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.TransferTxResult{}, sql.ErrNoRows)
+
+				approvalArg := db.RequestTransferApprovalTxParams{
+					TransferTxParams: arg.TransferTxParams,
+					RequestedBy:      arg.Username,
+					IdempotencyKey:   arg.IdempotencyKey,
+					RequestHash:      arg.RequestHash,
+				}
+
+				store.EXPECT().ReplayTransferApproval(gomock.Any(), gomock.Eq(approvalArg)).Times(1).
+					Return(db.RequestTransferApprovalTxResult{}, sql.ErrNoRows)
+
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "IdempotencyKeyReused",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+				request.Header.Set(idempotencyKeyHeader, "transfer-key")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+
+				store.EXPECT().ReplayTransfer(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{}, db.ErrIdempotencyKeyReused)
+
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "ApprovalIdempotencyKeyReused",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+				request.Header.Set(idempotencyKeyHeader, "transfer-key")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+
+				store.EXPECT().ReplayTransfer(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{}, sql.ErrNoRows)
+
+				// The key was used to request the approval of a different transfer
+				store.EXPECT().ReplayTransferApproval(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.RequestTransferApprovalTxResult{}, db.ErrIdempotencyKeyReused)
+
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "IdempotentRetryReplayed",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+				request.Header.Set(idempotencyKeyHeader, "transfer-key")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+
+				store.EXPECT().ReplayTransfer(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{Transfer: db.Transfer{ID: 7}}, nil)
+
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got struct {
+					Transfer db.Transfer `json:"transfer"`
+				}
+
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, int64(7), got.Transfer.ID)
+			},
+		},
+		{
+			name: "IdempotencyKeyTooLong",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+				request.Header.Set(idempotencyKeyHeader, util.RandomString(maxIdempotencyKeyLength+1))
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().IdempotentTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "StatusBadRequest",
+			body: gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "FromAccountNotFound",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "ToAccountNotFound",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "FromAccountCurrencyMismatch",
+			body: gin.H{
+				"from_account_id": mockAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(mockAccount.ID)).Times(1).Return(mockAccount, nil)
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(0)
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "CrossCurrencyTransfer",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   mockAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(mockAccount.ID)).Times(1).Return(mockAccount, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: int64(fromAccount.ID),
+					ToAccountID:   int64(mockAccount.ID),
+					Amount:        int64(amountToTransfer),
+				}
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ExchangeRateNotFound",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   mockAccount.ID,
+				"amount":          amountToTransfer,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(mockAccount.ID)).Times(1).Return(mockAccount, nil)
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{}, db.ErrExchangeRateNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/v1/transfers"
+
+			requestBody, err := json.Marshal(tc.body)
+			require.NoError(t, err)
 
 			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(requestBody))
 			require.NoError(t, err)
@@ -248,6 +828,660 @@ func TestCreateTransfer(t *testing.T) {
 
 }
 
+func createRandomTransfer(fromAccount, toAccount db.Account) db.Transfer {
+	amount := util.RandomMoney()
+
+	return db.Transfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: int64(fromAccount.ID),
+		ToAccountID:   int64(toAccount.ID),
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  "1",
+		CreatedAt:     time.Now(),
+	}
+}
+
+func requireBodyMatchTransfers(t *testing.T, body *bytes.Buffer, transfers []db.ListOwnerTransfersRow) {
+	var gotTransfers []db.ListOwnerTransfersRow
+
+	err := json.Unmarshal(body.Bytes(), &gotTransfers)
+	require.NoError(t, err)
+	require.Len(t, gotTransfers, len(transfers))
+
+	for i := range transfers {
+		require.Equal(t, transfers[i].ID, gotTransfers[i].ID)
+		require.Equal(t, transfers[i].Amount, gotTransfers[i].Amount)
+		require.Equal(t, transfers[i].ReversedByTransferID, gotTransfers[i].ReversedByTransferID)
+	}
+}
+
+func TestGetTransfer(t *testing.T) {
+	fromAccountUser, _ := randomUser(t)
+	toAccountUser, _ := randomUser(t)
+
+	fromAccount := createRandomAccount(fromAccountUser.Username)
+	toAccount := createRandomAccount(toAccountUser.Username)
+	created := createRandomTransfer(fromAccount, toAccount)
+
+	transfer := db.GetTransferRow{
+		ID:                   created.ID,
+		FromAccountID:        created.FromAccountID,
+		ToAccountID:          created.ToAccountID,
+		Amount:               created.Amount,
+		CreatedAt:            created.CreatedAt,
+		ToAmount:             created.ToAmount,
+		ExchangeRate:         created.ExchangeRate,
+		ReversedByTransferID: util.RandomInt(1, 1000),
+	}
+
+	testCases := []struct {
+		name          string
+		transferID    int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "Sender",
+			transferID: transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotTransfer db.GetTransferRow
+
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotTransfer)
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, gotTransfer.ID)
+				require.Equal(t, transfer.ReversedByTransferID, gotTransfer.ReversedByTransferID)
+				require.Zero(t, gotTransfer.ReversalOfTransferID)
+			},
+		},
+		{
+			name:       "Receiver",
+			transferID: transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, toAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "UnauthorizedUser",
+			transferID: transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized", util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(fromAccount, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "BankerViewsAnyTransfer",
+			transferID: transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "NotFound",
+			transferID: transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(db.GetTransferRow{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "GetAccountError",
+			transferID: transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:       "InvalidID",
+			transferID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, fromAccountUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/transfers/%d", tc.transferID)
+
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListTransfers(t *testing.T) {
+	user, _ := randomUser(t)
+	otherUser, _ := randomUser(t)
+
+	account := createRandomAccount(user.Username)
+	otherAccount := createRandomAccount(otherUser.Username)
+
+	n := 5
+	transfers := make([]db.ListOwnerTransfersRow, n)
+
+	for i := 0; i < n; i++ {
+		transfer := createRandomTransfer(account, otherAccount)
+
+		transfers[i] = db.ListOwnerTransfersRow{
+			ID:            transfer.ID,
+			FromAccountID: transfer.FromAccountID,
+			ToAccountID:   transfer.ToAccountID,
+			Amount:        transfer.Amount,
+			CreatedAt:     transfer.CreatedAt,
+			ToAmount:      transfer.ToAmount,
+			ExchangeRate:  transfer.ExchangeRate,
+		}
+	}
+
+	from := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		query         map[string]string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "StatusOK",
+			query: map[string]string{
+				"page_id":   "1",
+				"page_size": fmt.Sprintf("%d", n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListOwnerTransfersParams{
+					Owner:     user.Username,
+					CreatedTo: maxTransferTime,
+					MaxAmount: math.MaxInt64,
+					Metadata:  emptyTransferMetadata,
+					Limit:     int32(n),
+					Offset:    0,
+				}
+
+				store.EXPECT().ListOwnerTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTransfers(t, recorder.Body, transfers)
+			},
+		},
+		{
+			name: "WithFilters",
+			query: map[string]string{
+				"account_id": fmt.Sprintf("%d", account.ID),
+				"direction":  "out",
+				"from":       from.Format(time.RFC3339),
+				"to":         to.Format(time.RFC3339),
+				"min_amount": "10",
+				"max_amount": "100",
+				"search":     "Rent",
+				"metadata":   `{"invoice": "INV-1"}`,
+				"page_id":    "2",
+				"page_size":  fmt.Sprintf("%d", n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListOwnerTransfersParams{
+					Owner:       user.Username,
+					Direction:   "out",
+					AccountID:   int64(account.ID),
+					CreatedFrom: from,
+					CreatedTo:   to,
+					MinAmount:   10,
+					MaxAmount:   100,
+					Search:      "Rent",
+					Metadata:    json.RawMessage(`{"invoice":"INV-1"}`),
+					Limit:       int32(n),
+					Offset:      int32(n),
+				}
+
+				store.EXPECT().ListOwnerTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BankerListsCustomerTransfers",
+			query: map[string]string{
+				"owner":     user.Username,
+				"page_id":   "1",
+				"page_size": fmt.Sprintf("%d", n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "banker", util.BankerRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOwnerTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ListOwnerTransfersParams) ([]db.ListOwnerTransfersRow,
+						error) {
+						require.Equal(t, user.Username, arg.Owner)
+						return transfers, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "DepositorListsAnotherOwner",
+			query: map[string]string{
+				"owner":     otherUser.Username,
+				"page_id":   "1",
+				"page_size": fmt.Sprintf("%d", n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOwnerTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDirection",
+			query: map[string]string{
+				"direction": "sideways",
+				"page_id":   "1",
+				"page_size": fmt.Sprintf("%d", n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOwnerTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDateRange",
+			query: map[string]string{
+				"from":      to.Format(time.RFC3339),
+				"to":        from.Format(time.RFC3339),
+				"page_id":   "1",
+				"page_size": fmt.Sprintf("%d", n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOwnerTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MetadataNotAnObject",
+			query: map[string]string{
+				"metadata":  `["INV-1"]`,
+				"page_id":   "1",
+				"page_size": fmt.Sprintf("%d", n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOwnerTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmountRange",
+			query: map[string]string{
+				"min_amount": "100",
+				"max_amount": "10",
+				"page_id":    "1",
+				"page_size":  fmt.Sprintf("%d", n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOwnerTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalServerError",
+			query: map[string]string{
+				"page_id":   "1",
+				"page_size": fmt.Sprintf("%d", n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOwnerTransfers(gomock.Any(), gomock.Any()).Times(1).
+					Return([]db.ListOwnerTransfersRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/transfers", nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+
+			for key, value := range tc.query {
+				q.Add(key, value)
+			}
+
+			request.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReverseTransfer(t *testing.T) {
+	fromAccount := createRandomAccount(util.RandomOwner())
+	toAccount := createRandomAccount(util.RandomOwner())
+	transfer := createRandomTransfer(fromAccount, toAccount)
+	reason := "sent to the wrong account"
+
+	testCases := []struct {
+		name          string
+		transferID    int64
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "StatusOK",
+			transferID: transfer.ID,
+			body:       gin.H{"reason": reason},
+			role:       util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ReverseTransferTxParams{
+					TransferID: transfer.ID,
+					Reason:     reason,
+					ReversedBy: "admin",
+				}
+
+				result := db.ReverseTransferTxResult{
+					Reversal: db.TransferReversal{
+						TransferID:         transfer.ID,
+						ReversalTransferID: transfer.ID + 1,
+						Reason:             reason,
+						ReversedBy:         "admin",
+					},
+				}
+
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.ReverseTransferTxResult
+
+				err := json.Unmarshal(recorder.Body.Bytes(), &result)
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, result.Reversal.TransferID)
+				require.Equal(t, transfer.ID+1, result.Reversal.ReversalTransferID)
+			},
+		},
+		{
+			name:       "Forbidden",
+			transferID: transfer.ID,
+			body:       gin.H{"reason": reason},
+			role:       util.BankerRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:       "MissingReason",
+			transferID: transfer.ID,
+			body:       gin.H{},
+			role:       util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "InvalidID",
+			transferID: 0,
+			body:       gin.H{"reason": reason},
+			role:       util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "NotFound",
+			transferID: transfer.ID,
+			body:       gin.H{"reason": reason},
+			role:       util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReverseTransferTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "AlreadyReversed",
+			transferID: transfer.ID,
+			body:       gin.H{"reason": reason},
+			role:       util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrTransferAlreadyReversed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:       "ReversalOfReversal",
+			transferID: transfer.ID,
+			body:       gin.H{"reason": reason},
+			role:       util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrCannotReverseReversal)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:       "NotPosted",
+			transferID: transfer.ID,
+			body:       gin.H{"reason": reason},
+			role:       util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrTransferNotPosted)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:       "InsufficientFunds",
+			transferID: transfer.ID,
+			body:       gin.H{"reason": reason},
+			role:       util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:       "InternalServerError",
+			transferID: transfer.ID,
+			body:       gin.H{"reason": reason},
+			role:       util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReverseTransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubTokenNotRevoked(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/v1/transfers/%d/reverse", tc.transferID)
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", tc.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestHashTransferRequest(t *testing.T) {
+	req := createTransferRequest{
+		FromAccountID: util.RandomInt(1, 1000),
+		ToAccountID:   util.RandomInt(1, 1000),
+		Amount:        util.RandomMoney(),
+		Currency:      util.RandomCurrency(),
+	}
+
+	require.Equal(t, hashTransferRequest(req), hashTransferRequest(req))
+
+	changed := req
+	changed.Amount++
+
+	require.NotEqual(t, hashTransferRequest(req), hashTransferRequest(changed))
+}
+
 //```
 //I remember when I saw your picture just after December
 //You were smiling in the photo rocking that black romper
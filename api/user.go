@@ -4,7 +4,9 @@ import (
 	"database/sql"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/token"
 	"github.com/jwambugu/go-simple-bank-class/util"
 	"github.com/lib/pq"
 	"net/http"
@@ -23,6 +25,7 @@ type (
 		Username          string    `json:"username"`
 		FullName          string    `json:"fullName"`
 		Email             string    `json:"email"`
+		Role              string    `json:"role"`
 		PasswordChangedAt time.Time `json:"passwordChangedAt"`
 		CreatedAt         time.Time `json:"createdAt"`
 	}
@@ -32,9 +35,21 @@ type (
 		Password string `json:"password" binding:"required,min=6"`
 	}
 
+	usernameURIRequest struct {
+		Username string `uri:"username" binding:"required,alphanum"`
+	}
+
+	updateUserRoleRequest struct {
+		Role string `json:"role" binding:"required,role"`
+	}
+
 	loginUserResponse struct {
-		AccessToken string       `json:"access_token"`
-		User        userResponse `json:"user"`
+		SessionID             uuid.UUID    `json:"session_id"`
+		AccessToken           string       `json:"access_token"`
+		AccessTokenExpiresAt  time.Time    `json:"access_token_expires_at"`
+		RefreshToken          string       `json:"refresh_token"`
+		RefreshTokenExpiresAt time.Time    `json:"refresh_token_expires_at"`
+		User                  userResponse `json:"user"`
 	}
 )
 
@@ -43,6 +58,7 @@ func newUserResponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Role:              user.Role,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
@@ -122,7 +138,41 @@ func (server *Server) loginUser(ctx *gin.Context) {
 	}
 
 	// Attempt to generate an access token
-	accessToken, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		token.AccessToken,
+		server.config.AccessTokenDuration,
+	)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Generate a longer-lived refresh token which is used to renew the access token
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		user.Role,
+		token.RefreshToken,
+		server.config.RefreshTokenDuration,
+	)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Persist the session so that the refresh token can be blocked server-side
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    ctx.Request.UserAgent(),
+		ClientIp:     ctx.ClientIP(),
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiresAt,
+	})
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -130,9 +180,114 @@ func (server *Server) loginUser(ctx *gin.Context) {
 	}
 
 	response := loginUserResponse{
-		AccessToken: accessToken,
-		User:        newUserResponse(user),
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiresAt,
+		User:                  newUserResponse(user),
 	}
 
 	ctx.JSON(http.StatusOK, response)
 }
+
+func (server *Server) revokeUserTokens(ctx *gin.Context) {
+	var req usernameURIRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if req.Username != authPayload.Username && !hasPermission(authPayload.Role, permissionManageUsers) {
+		err := errors.New("cannot revoke the tokens of another user")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// Revoke all the access tokens and block all the sessions of the user
+	if _, err := server.store.RevokeUserTokensTx(ctx, req.Username); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (server *Server) getUser(ctx *gin.Context) {
+	var req usernameURIRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Get the auth user
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if req.Username != authPayload.Username && !hasPermission(authPayload.Role, permissionManageUsers) {
+		err := errors.New("cannot view another user")
+
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, req.Username)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+func (server *Server) updateUserRole(ctx *gin.Context) {
+	var uri usernameURIRequest
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateUserRoleRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.UpdateUserRoleParams{
+		Role:     req.Role,
+		Username: uri.Username,
+	}
+
+	// The tokens already issued carry the old role, they are revoked so the user has to log in again
+	user, err := server.store.UpdateUserRoleTx(ctx, arg)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
@@ -3,11 +3,55 @@ package api
 import (
 	"github.com/go-playground/validator/v10"
 	"github.com/jwambugu/go-simple-bank-class/util"
+	"math/big"
+	"regexp"
 )
 
+// exchangeRateRegex matches a decimal number in the format accepted by postgres numeric columns
+var exchangeRateRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// maxPercentage is the highest percentage a fee can charge
+var maxPercentage = big.NewRat(100, 1)
+
 var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
 	if currency, ok := fieldLevel.Field().Interface().(string); ok {
 		return util.IsSupportedCurrency(currency)
 	}
 	return false
 }
+
+var validRole validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if role, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsSupportedRole(role)
+	}
+	return false
+}
+
+var validFrequency validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if frequency, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsSupportedFrequency(frequency)
+	}
+	return false
+}
+
+var validExchangeRate validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	rate, ok := fieldLevel.Field().Interface().(string)
+
+	if !ok || !exchangeRateRegex.MatchString(rate) {
+		return false
+	}
+
+	r, ok := new(big.Rat).SetString(rate)
+	return ok && r.Sign() > 0
+}
+
+var validPercentage validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	percentage, ok := fieldLevel.Field().Interface().(string)
+
+	if !ok || !exchangeRateRegex.MatchString(percentage) {
+		return false
+	}
+
+	p, ok := new(big.Rat).SetString(percentage)
+	return ok && p.Cmp(maxPercentage) <= 0
+}
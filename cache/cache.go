@@ -0,0 +1,12 @@
+package cache
+
+import "time"
+
+// Cache is an interface for storing values for a limited period of time
+type Cache interface {
+	// Get returns the value stored for the key if it exists and has not expired
+	Get(key string) (interface{}, bool)
+
+	// Set stores the value for the key for the specified duration
+	Set(key string, value interface{}, ttl time.Duration)
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often expired items are removed from a MemoryCache
+const sweepInterval = time.Minute
+
+type item struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// MemoryCache is an in-memory Cache local to the running process
+type MemoryCache struct {
+	mu        sync.RWMutex
+	items     map[string]item
+	lastSweep time.Time
+}
+
+// Get returns the value stored for the key if it exists and has not expired
+func (c *MemoryCache) Get(key string) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	i, ok := c.items[key]
+
+	if !ok || time.Now().After(i.expiresAt) {
+		return nil, false
+	}
+
+	return i.value, true
+}
+
+// Set stores the value for the key for the specified duration
+func (c *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	// Remove expired items every now and then so the cache does not grow unbounded
+	if now.Sub(c.lastSweep) > sweepInterval {
+		for k, i := range c.items {
+			if now.After(i.expiresAt) {
+				delete(c.items, k)
+			}
+		}
+
+		c.lastSweep = now
+	}
+
+	c.items[key] = item{
+		value:     value,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+// NewMemoryCache creates a new MemoryCache
+func NewMemoryCache() Cache {
+	return &MemoryCache{
+		items:     make(map[string]item),
+		lastSweep: time.Now(),
+	}
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache()
+
+	key := util.RandomString(6)
+	value := util.RandomString(6)
+
+	got, ok := c.Get(key)
+	require.False(t, ok)
+	require.Nil(t, got)
+
+	c.Set(key, value, time.Minute)
+
+	got, ok = c.Get(key)
+	require.True(t, ok)
+	require.Equal(t, value, got)
+
+	// Overwriting a key replaces its value
+	c.Set(key, true, time.Minute)
+
+	got, ok = c.Get(key)
+	require.True(t, ok)
+	require.Equal(t, true, got)
+}
+
+func TestMemoryCacheExpiredItem(t *testing.T) {
+	c := NewMemoryCache()
+
+	key := util.RandomString(6)
+	c.Set(key, util.RandomString(6), -time.Second)
+
+	got, ok := c.Get(key)
+	require.False(t, ok)
+	require.Nil(t, got)
+}
@@ -0,0 +1,4 @@
+ALTER TABLE IF EXISTS "users"
+    DROP COLUMN IF EXISTS "tokens_revoked_at";
+
+DROP TABLE IF EXISTS "revoked_tokens";
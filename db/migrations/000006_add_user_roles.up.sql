@@ -0,0 +1,5 @@
+ALTER TABLE "users"
+    ADD COLUMN "role" varchar NOT NULL DEFAULT 'depositor';
+
+ALTER TABLE "users"
+    ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('depositor', 'banker', 'admin'));
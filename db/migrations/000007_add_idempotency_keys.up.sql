@@ -0,0 +1,16 @@
+CREATE TABLE "idempotency_keys"
+(
+    "username"        varchar     NOT NULL,
+    "idempotency_key" varchar     NOT NULL,
+    "request_hash"    varchar     NOT NULL,
+    "response"        jsonb       NOT NULL,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("username", "idempotency_key")
+);
+
+ALTER TABLE "idempotency_keys"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'hash of the request the key was first used with';
+
+COMMENT ON COLUMN "idempotency_keys"."response" IS 'the serialized result replayed for retries of the request';
@@ -0,0 +1,27 @@
+CREATE TABLE "exchange_rates"
+(
+    "from_currency" varchar     NOT NULL,
+    "to_currency"   varchar     NOT NULL,
+    "rate"          numeric     NOT NULL CHECK ("rate" > 0),
+    "updated_at"    timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("from_currency", "to_currency"),
+    CHECK ("from_currency" <> "to_currency")
+);
+
+ALTER TABLE "transfers"
+    ADD COLUMN "to_amount" bigint;
+
+UPDATE "transfers"
+SET "to_amount" = "amount";
+
+ALTER TABLE "transfers"
+    ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers"
+    ADD COLUMN "exchange_rate" numeric NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN "exchange_rates"."rate" IS 'units of to_currency for one unit of from_currency';
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'the amount credited in the receiver''s currency';
+
+COMMENT ON COLUMN "transfers"."exchange_rate" IS 'the rate applied to convert amount into to_amount';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "scheduled_transfer_attempts";
+
+DROP TABLE IF EXISTS "scheduled_transfers";
@@ -0,0 +1,53 @@
+CREATE TABLE "scheduled_transfers"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL CHECK ("amount" > 0),
+    "status"          varchar     NOT NULL DEFAULT 'pending',
+    "execute_at"      timestamptz NOT NULL,
+    "next_attempt_at" timestamptz NOT NULL,
+    "attempt_count"   integer     NOT NULL DEFAULT 0,
+    "transfer_id"     bigint,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfer_attempts"
+(
+    "id"                    bigserial PRIMARY KEY,
+    "scheduled_transfer_id" bigint      NOT NULL,
+    "transfer_id"           bigint,
+    "error"                 varchar     NOT NULL DEFAULT '',
+    "created_at"            timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_attempts"
+    ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_attempts"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "scheduled_transfers" ("from_account_id");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "next_attempt_at");
+
+CREATE INDEX ON "scheduled_transfer_attempts" ("scheduled_transfer_id");
+
+COMMENT ON COLUMN "scheduled_transfers"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'pending, processing, completed, failed or cancelled';
+
+COMMENT ON COLUMN "scheduled_transfers"."next_attempt_at" IS 'when the worker may pick the transfer up, moved forward after a failed attempt';
+
+COMMENT ON COLUMN "scheduled_transfers"."transfer_id" IS 'the transfer created by the successful attempt';
+
+COMMENT ON COLUMN "scheduled_transfer_attempts"."error" IS 'why the attempt failed, empty if it succeeded';
@@ -0,0 +1,4 @@
+ALTER TABLE IF EXISTS "scheduled_transfers"
+    DROP COLUMN IF EXISTS "standing_order_id";
+
+DROP TABLE IF EXISTS "standing_orders";
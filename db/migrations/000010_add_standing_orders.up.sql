@@ -0,0 +1,47 @@
+CREATE TABLE "standing_orders"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL CHECK ("amount" > 0),
+    "frequency"       varchar     NOT NULL,
+    "day_of_month"    integer     NOT NULL DEFAULT 0 CHECK ("day_of_month" BETWEEN 0 AND 31),
+    "status"          varchar     NOT NULL DEFAULT 'active',
+    "next_run_at"     timestamptz NOT NULL,
+    "end_at"          timestamptz,
+    "max_runs"        integer CHECK ("max_runs" > 0),
+    "run_count"       integer     NOT NULL DEFAULT 0,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "standing_orders"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "standing_orders"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers"
+    ADD COLUMN "standing_order_id" bigint;
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("standing_order_id") REFERENCES "standing_orders" ("id");
+
+CREATE INDEX ON "standing_orders" ("from_account_id");
+
+CREATE INDEX ON "standing_orders" ("status", "next_run_at");
+
+CREATE INDEX ON "scheduled_transfers" ("standing_order_id");
+
+COMMENT ON COLUMN "standing_orders"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "standing_orders"."frequency" IS 'daily, weekly or monthly';
+
+COMMENT ON COLUMN "standing_orders"."day_of_month" IS 'the day monthly orders run on, the last day of shorter months is used instead';
+
+COMMENT ON COLUMN "standing_orders"."status" IS 'active, paused, completed or cancelled';
+
+COMMENT ON COLUMN "standing_orders"."end_at" IS 'no transfer is made after this time';
+
+COMMENT ON COLUMN "standing_orders"."max_runs" IS 'the order completes after this many transfers';
+
+COMMENT ON COLUMN "scheduled_transfers"."standing_order_id" IS 'the standing order the transfer was generated by';
@@ -0,0 +1,23 @@
+CREATE TABLE "transfer_reversals"
+(
+    "transfer_id"          bigint PRIMARY KEY,
+    "reversal_transfer_id" bigint      NOT NULL UNIQUE,
+    "reason"               varchar     NOT NULL,
+    "reversed_by"          varchar     NOT NULL,
+    "created_at"           timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "transfer_reversals"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_reversals"
+    ADD FOREIGN KEY ("reversal_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_reversals"
+    ADD FOREIGN KEY ("reversed_by") REFERENCES "users" ("username");
+
+COMMENT ON COLUMN "transfer_reversals"."transfer_id" IS 'the transfer that was reversed, it can only be reversed once';
+
+COMMENT ON COLUMN "transfer_reversals"."reversal_transfer_id" IS 'the transfer moving the money back';
+
+COMMENT ON COLUMN "transfer_reversals"."reversed_by" IS 'the username of the admin who reversed the transfer';
@@ -0,0 +1,30 @@
+CREATE TABLE "transfer_batches"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "total_amount"    bigint      NOT NULL,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_batch_legs"
+(
+    "batch_id"    bigint NOT NULL,
+    "leg_index"   int    NOT NULL,
+    "transfer_id" bigint NOT NULL UNIQUE,
+    PRIMARY KEY ("batch_id", "leg_index")
+);
+
+ALTER TABLE "transfer_batches"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_batch_legs"
+    ADD FOREIGN KEY ("batch_id") REFERENCES "transfer_batches" ("id");
+
+ALTER TABLE "transfer_batch_legs"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "transfer_batches" ("from_account_id");
+
+COMMENT ON COLUMN "transfer_batches"."total_amount" IS 'the sum of the legs in the currency of the from account';
+
+COMMENT ON COLUMN "transfer_batch_legs"."leg_index" IS 'the position of the leg in the request, starting at 0';
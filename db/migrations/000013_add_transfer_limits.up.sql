@@ -0,0 +1,23 @@
+CREATE TABLE "transfer_limits"
+(
+    "scope"           varchar     NOT NULL CHECK ("scope" IN ('user', 'role')),
+    "subject"         varchar     NOT NULL,
+    "currency"        varchar     NOT NULL,
+    "per_transaction" bigint      NOT NULL CHECK ("per_transaction" >= 0),
+    "daily"           bigint      NOT NULL CHECK ("daily" >= 0),
+    "monthly"         bigint      NOT NULL CHECK ("monthly" >= 0),
+    "updated_at"      timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("scope", "subject", "currency")
+);
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+COMMENT ON COLUMN "transfer_limits"."scope" IS 'user limits apply to a single username and take precedence over role limits';
+
+COMMENT ON COLUMN "transfer_limits"."subject" IS 'the username or the role the limit applies to';
+
+COMMENT ON COLUMN "transfer_limits"."per_transaction" IS 'zero means no limit';
+
+COMMENT ON COLUMN "transfer_limits"."daily" IS 'zero means no limit';
+
+COMMENT ON COLUMN "transfer_limits"."monthly" IS 'zero means no limit';
@@ -0,0 +1,36 @@
+CREATE TABLE "money_requests"
+(
+    "id"          bigserial PRIMARY KEY,
+    "requester"   varchar     NOT NULL,
+    "payer"       varchar     NOT NULL,
+    "amount"      bigint      NOT NULL CHECK ("amount" > 0),
+    "currency"    varchar     NOT NULL,
+    "status"      varchar     NOT NULL DEFAULT 'pending',
+    "expires_at"  timestamptz NOT NULL,
+    "transfer_id" bigint,
+    "created_at"  timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("requester" <> "payer")
+);
+
+ALTER TABLE "money_requests"
+    ADD FOREIGN KEY ("requester") REFERENCES "users" ("username");
+
+ALTER TABLE "money_requests"
+    ADD FOREIGN KEY ("payer") REFERENCES "users" ("username");
+
+ALTER TABLE "money_requests"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "money_requests" ("requester");
+
+CREATE INDEX ON "money_requests" ("payer");
+
+CREATE INDEX ON "money_requests" ("status", "expires_at");
+
+COMMENT ON COLUMN "money_requests"."requester" IS 'the user asking to be paid';
+
+COMMENT ON COLUMN "money_requests"."payer" IS 'the user asked to pay';
+
+COMMENT ON COLUMN "money_requests"."status" IS 'pending, accepted, declined, cancelled or expired';
+
+COMMENT ON COLUMN "money_requests"."transfer_id" IS 'the transfer made when the payer accepted the request';
@@ -0,0 +1,14 @@
+ALTER TABLE "transfers"
+    ADD COLUMN "memo" varchar NOT NULL DEFAULT '' CHECK (char_length("memo") <= 140);
+
+ALTER TABLE "transfers"
+    ADD COLUMN "reference" varchar NOT NULL DEFAULT '' CHECK (char_length("reference") <= 64);
+
+ALTER TABLE "transfers"
+    ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}' CHECK (jsonb_typeof("metadata") = 'object');
+
+COMMENT ON COLUMN "transfers"."memo" IS 'a note from the sender shown to both parties';
+
+COMMENT ON COLUMN "transfers"."reference" IS 'an identifier of the transfer in the systems of the sender';
+
+COMMENT ON COLUMN "transfers"."metadata" IS 'a JSON object of up to 4KB attached by the sender';
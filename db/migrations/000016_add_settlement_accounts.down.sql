@@ -0,0 +1,27 @@
+DROP TABLE IF EXISTS "settlement_accounts";
+
+-- The settlement accounts are only removed if no money ever moved through them, their entries belong to the ledger of
+-- the customers on the other side. Accounts that are kept are picked up again by the up migration.
+DELETE
+FROM "accounts"
+WHERE "owner" = 'bank_settlement'
+  AND NOT EXISTS(SELECT 1 FROM "entries" WHERE "entries"."account_id" = "accounts"."id")
+  AND NOT EXISTS(SELECT 1
+                 FROM "transfers"
+                 WHERE "transfers"."from_account_id" = "accounts"."id"
+                    OR "transfers"."to_account_id" = "accounts"."id");
+
+DELETE
+FROM "users"
+WHERE "username" = 'bank_settlement'
+  AND NOT EXISTS(SELECT 1 FROM "accounts" WHERE "accounts"."owner" = 'bank_settlement');
+
+UPDATE "users"
+SET "role" = 'depositor'
+WHERE "role" = 'teller';
+
+ALTER TABLE IF EXISTS "users"
+    DROP CONSTRAINT IF EXISTS "users_role_check";
+
+ALTER TABLE IF EXISTS "users"
+    ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('depositor', 'banker', 'admin'));
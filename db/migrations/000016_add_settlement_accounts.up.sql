@@ -0,0 +1,33 @@
+ALTER TABLE "users"
+    DROP CONSTRAINT "users_role_check";
+
+ALTER TABLE "users"
+    ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('depositor', 'teller', 'banker', 'admin'));
+
+CREATE TABLE "settlement_accounts"
+(
+    "currency"   varchar PRIMARY KEY,
+    "account_id" bigint NOT NULL UNIQUE
+);
+
+ALTER TABLE "settlement_accounts"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+-- The bank owns the settlement accounts. The username cannot be registered through the API and the user has no
+-- password, so nobody can log in as the bank.
+INSERT INTO "users" ("username", "full_name", "hashed_password", "email")
+VALUES ('bank_settlement', 'Bank settlement', '', 'settlement@bank.internal')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('bank_settlement', 0, 'USD'),
+       ('bank_settlement', 0, 'EUR'),
+       ('bank_settlement', 0, 'CAD')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "settlement_accounts" ("currency", "account_id")
+SELECT "currency", "id"
+FROM "accounts"
+WHERE "owner" = 'bank_settlement';
+
+COMMENT ON COLUMN "settlement_accounts"."account_id" IS 'the account on the other side of the deposits and withdrawals in the currency';
@@ -0,0 +1,31 @@
+CREATE TABLE "risk_decisions"
+(
+    "id"              bigserial PRIMARY KEY,
+    "username"        varchar     NOT NULL,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "currency"        varchar     NOT NULL,
+    "decision"        varchar     NOT NULL CHECK ("decision" IN ('allow', 'review', 'deny')),
+    "rule_hits"       jsonb       NOT NULL CHECK (jsonb_typeof("rule_hits") = 'array'),
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "risk_decisions"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "risk_decisions"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "risk_decisions"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "risk_decisions" ("username");
+
+CREATE INDEX ON "risk_decisions" ("decision", "created_at");
+
+COMMENT ON COLUMN "risk_decisions"."username" IS 'the user who requested the transfer';
+
+COMMENT ON COLUMN "risk_decisions"."decision" IS 'allow, review or deny, only allowed transfers are made';
+
+COMMENT ON COLUMN "risk_decisions"."rule_hits" IS 'the rules that matched the transfer and why';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "transfer_approvals";
+
+ALTER TABLE "accounts"
+    DROP COLUMN IF EXISTS "held";
@@ -0,0 +1,54 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "held" bigint NOT NULL DEFAULT 0 CHECK ("held" >= 0);
+
+CREATE TABLE "transfer_approvals"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL CHECK ("amount" > 0),
+    "memo"            varchar     NOT NULL DEFAULT '',
+    "reference"       varchar     NOT NULL DEFAULT '',
+    "metadata"        jsonb       NOT NULL DEFAULT '{}',
+    "requested_by"    varchar     NOT NULL,
+    "idempotency_key" varchar     NOT NULL DEFAULT '',
+    "request_hash"    varchar     NOT NULL DEFAULT '',
+    "status"          varchar     NOT NULL DEFAULT 'pending',
+    "decided_by"      varchar,
+    "reason"          varchar     NOT NULL DEFAULT '',
+    "transfer_id"     bigint,
+    "expires_at"      timestamptz NOT NULL,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("decided_by" <> "requested_by")
+);
+
+ALTER TABLE "transfer_approvals"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_approvals"
+    ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_approvals"
+    ADD FOREIGN KEY ("decided_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_approvals"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE UNIQUE INDEX ON "transfer_approvals" ("requested_by", "idempotency_key") WHERE "idempotency_key" <> '';
+
+CREATE INDEX ON "transfer_approvals" ("status", "expires_at");
+
+COMMENT ON COLUMN "accounts"."held" IS 'money reserved for transfers awaiting approval, it cannot be spent';
+
+COMMENT ON COLUMN "transfer_approvals"."amount" IS 'held on the sender account until the transfer is approved, rejected or expires';
+
+COMMENT ON COLUMN "transfer_approvals"."idempotency_key" IS 'empty when the client did not send one';
+
+COMMENT ON COLUMN "transfer_approvals"."status" IS 'pending, approved, rejected or expired';
+
+COMMENT ON COLUMN "transfer_approvals"."decided_by" IS 'the officer who approved or rejected the transfer, never the requester';
+
+COMMENT ON COLUMN "transfer_approvals"."transfer_id" IS 'the transfer made once approved';
@@ -0,0 +1,21 @@
+DROP TABLE IF EXISTS "transfer_fees";
+
+DROP TABLE IF EXISTS "fee_schedules";
+
+DROP TABLE IF EXISTS "fee_accounts";
+
+-- The fee accounts are only removed if no money ever moved through them, their entries belong to the ledger of
+-- the customers on the other side. Accounts that are kept are picked up again by the up migration.
+DELETE
+FROM "accounts"
+WHERE "owner" = 'bank_fees'
+  AND NOT EXISTS(SELECT 1 FROM "entries" WHERE "entries"."account_id" = "accounts"."id")
+  AND NOT EXISTS(SELECT 1
+                 FROM "transfers"
+                 WHERE "transfers"."from_account_id" = "accounts"."id"
+                    OR "transfers"."to_account_id" = "accounts"."id");
+
+DELETE
+FROM "users"
+WHERE "username" = 'bank_fees'
+  AND NOT EXISTS(SELECT 1 FROM "accounts" WHERE "accounts"."owner" = 'bank_fees');
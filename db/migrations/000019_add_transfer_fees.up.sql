@@ -0,0 +1,68 @@
+CREATE TABLE "fee_accounts"
+(
+    "currency"   varchar PRIMARY KEY,
+    "account_id" bigint NOT NULL UNIQUE
+);
+
+ALTER TABLE "fee_accounts"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE TABLE "fee_schedules"
+(
+    "currency"                 varchar PRIMARY KEY,
+    "flat"                     bigint      NOT NULL DEFAULT 0 CHECK ("flat" >= 0),
+    "percentage"               numeric     NOT NULL DEFAULT 0 CHECK ("percentage" >= 0 AND "percentage" <= 100),
+    "min_fee"                  bigint      NOT NULL DEFAULT 0 CHECK ("min_fee" >= 0),
+    "max_fee"                  bigint      NOT NULL DEFAULT 0 CHECK ("max_fee" >= 0),
+    "free_transfers_per_month" integer     NOT NULL DEFAULT 0 CHECK ("free_transfers_per_month" >= 0),
+    "updated_at"               timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("max_fee" = 0 OR "max_fee" >= "min_fee")
+);
+
+ALTER TABLE "fee_schedules"
+    ADD FOREIGN KEY ("currency") REFERENCES "fee_accounts" ("currency");
+
+CREATE TABLE "transfer_fees"
+(
+    "transfer_id"       bigint PRIMARY KEY,
+    "fee_transfer_id"   bigint  NOT NULL UNIQUE,
+    "flat"              bigint  NOT NULL,
+    "percentage"        numeric NOT NULL,
+    "percentage_amount" bigint  NOT NULL,
+    "adjustment"        bigint  NOT NULL,
+    "total"             bigint  NOT NULL CHECK ("total" > 0)
+);
+
+ALTER TABLE "transfer_fees"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_fees"
+    ADD FOREIGN KEY ("fee_transfer_id") REFERENCES "transfers" ("id");
+
+-- The bank owns the fee accounts. Like the settlement user, nobody can log in as it.
+INSERT INTO "users" ("username", "full_name", "hashed_password", "email")
+VALUES ('bank_fees', 'Bank fees', '', 'fees@bank.internal')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('bank_fees', 0, 'USD'),
+       ('bank_fees', 0, 'EUR'),
+       ('bank_fees', 0, 'CAD')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "fee_accounts" ("currency", "account_id")
+SELECT "currency", "id"
+FROM "accounts"
+WHERE "owner" = 'bank_fees';
+
+COMMENT ON COLUMN "fee_accounts"."account_id" IS 'the account credited with the fees charged in the currency';
+
+COMMENT ON COLUMN "fee_schedules"."percentage" IS 'percent of the amount charged on top of the flat fee';
+
+COMMENT ON COLUMN "fee_schedules"."max_fee" IS 'zero means no maximum';
+
+COMMENT ON COLUMN "fee_schedules"."free_transfers_per_month" IS 'transfers each user makes in the currency without a fee every calendar month';
+
+COMMENT ON COLUMN "transfer_fees"."fee_transfer_id" IS 'the transfer moving the fee from the sender to the fee account';
+
+COMMENT ON COLUMN "transfer_fees"."adjustment" IS 'added to reach the minimum fee or subtracted to stay within the maximum fee';
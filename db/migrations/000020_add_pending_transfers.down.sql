@@ -0,0 +1,26 @@
+UPDATE "accounts"
+SET "held" = "held" - "pending"."total"
+FROM (SELECT "from_account_id", SUM("amount") AS "total"
+      FROM "transfers"
+      WHERE "status" = 'pending'
+      GROUP BY "from_account_id") AS "pending"
+WHERE "accounts"."id" = "pending"."from_account_id";
+
+DROP TABLE IF EXISTS "transfer_holds";
+
+DELETE
+FROM "transfers"
+WHERE "status" <> 'posted';
+
+ALTER TABLE "transfers"
+    DROP COLUMN IF EXISTS "posted_at";
+
+ALTER TABLE "transfers"
+    DROP COLUMN IF EXISTS "status";
+
+ALTER TABLE "accounts"
+    DROP COLUMN IF EXISTS "available_balance";
+
+COMMENT ON COLUMN "accounts"."balance" IS NULL;
+
+COMMENT ON COLUMN "accounts"."held" IS 'money reserved for transfers awaiting approval, it cannot be spent';
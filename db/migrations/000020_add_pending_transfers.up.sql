@@ -0,0 +1,40 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "available_balance" bigint GENERATED ALWAYS AS ("balance" - "held") STORED;
+
+ALTER TABLE "transfers"
+    ADD COLUMN "status" varchar NOT NULL DEFAULT 'posted' CHECK ("status" IN ('pending', 'posted', 'voided'));
+
+ALTER TABLE "transfers"
+    ADD COLUMN "posted_at" timestamptz DEFAULT (now());
+
+UPDATE "transfers"
+SET "posted_at" = "created_at";
+
+ALTER TABLE "transfers"
+    ADD CHECK (("status" = 'posted') = ("posted_at" IS NOT NULL));
+
+CREATE TABLE "transfer_holds"
+(
+    "transfer_id" bigint PRIMARY KEY,
+    "amount"      bigint      NOT NULL CHECK ("amount" > 0),
+    "expires_at"  timestamptz NOT NULL
+);
+
+ALTER TABLE "transfer_holds"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "transfer_holds" ("expires_at");
+
+COMMENT ON COLUMN "accounts"."balance" IS 'the ledger balance, it includes the money held';
+
+COMMENT ON COLUMN "accounts"."held" IS 'money reserved for transfers awaiting approval or posting, it cannot be spent';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'the ledger balance less the money held';
+
+COMMENT ON COLUMN "transfers"."status" IS 'pending transfers only hold the money of the sender until they are posted or voided';
+
+COMMENT ON COLUMN "transfers"."posted_at" IS 'when the money moved, the entries of the transfer are created at this time';
+
+COMMENT ON COLUMN "transfer_holds"."amount" IS 'the amount held when the transfer was created, it may be posted for less';
+
+COMMENT ON COLUMN "transfer_holds"."expires_at" IS 'the transfer is voided and the money released if it is still pending by then';
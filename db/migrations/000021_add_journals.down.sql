@@ -0,0 +1,31 @@
+DROP TRIGGER IF EXISTS "entries_journal_balanced" ON "entries";
+
+DROP FUNCTION IF EXISTS "check_journal_balanced"();
+
+DROP FUNCTION IF EXISTS "assert_journal_balanced"(bigint);
+
+DROP TABLE IF EXISTS "fx_accounts";
+
+-- The FX accounts are only removed if no money ever moved through them, their entries belong to the ledger of
+-- the customers on the other side. Accounts that are kept are picked up again by the up migration.
+DELETE
+FROM "accounts"
+WHERE "owner" = 'bank_fx'
+  AND NOT EXISTS(SELECT 1 FROM "entries" WHERE "entries"."account_id" = "accounts"."id")
+  AND NOT EXISTS(SELECT 1
+                 FROM "transfers"
+                 WHERE "transfers"."from_account_id" = "accounts"."id"
+                    OR "transfers"."to_account_id" = "accounts"."id");
+
+DELETE
+FROM "users"
+WHERE "username" = 'bank_fx'
+  AND NOT EXISTS(SELECT 1 FROM "accounts" WHERE "accounts"."owner" = 'bank_fx');
+
+ALTER TABLE "transfers"
+    DROP COLUMN IF EXISTS "journal_id";
+
+ALTER TABLE "entries"
+    DROP COLUMN IF EXISTS "journal_id";
+
+DROP TABLE IF EXISTS "journals";
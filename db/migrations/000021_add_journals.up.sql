@@ -0,0 +1,100 @@
+CREATE TABLE "journals"
+(
+    "id"         bigserial PRIMARY KEY,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "entries"
+    ADD COLUMN "journal_id" bigint;
+
+ALTER TABLE "entries"
+    ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+CREATE INDEX ON "entries" ("journal_id");
+
+ALTER TABLE "transfers"
+    ADD COLUMN "journal_id" bigint;
+
+ALTER TABLE "transfers"
+    ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+CREATE INDEX ON "transfers" ("journal_id");
+
+CREATE TABLE "fx_accounts"
+(
+    "currency"   varchar PRIMARY KEY,
+    "account_id" bigint NOT NULL UNIQUE
+);
+
+ALTER TABLE "fx_accounts"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+-- The bank owns the FX accounts. Like the settlement user, nobody can log in as it.
+INSERT INTO "users" ("username", "full_name", "hashed_password", "email")
+VALUES ('bank_fx', 'Bank FX', '', 'fx@bank.internal')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('bank_fx', 0, 'USD'),
+       ('bank_fx', 0, 'EUR'),
+       ('bank_fx', 0, 'CAD')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "fx_accounts" ("currency", "account_id")
+SELECT "currency", "id"
+FROM "accounts"
+WHERE "owner" = 'bank_fx';
+
+CREATE FUNCTION "assert_journal_balanced"("checked_journal_id" bigint) RETURNS void AS
+$$
+DECLARE
+    "unbalanced_currency" varchar;
+BEGIN
+    IF "checked_journal_id" IS NULL THEN
+        RETURN;
+    END IF;
+
+    SELECT "accounts"."currency"
+    INTO "unbalanced_currency"
+    FROM "entries"
+             JOIN "accounts" ON "accounts"."id" = "entries"."account_id"
+    WHERE "entries"."journal_id" = "checked_journal_id"
+    GROUP BY "accounts"."currency"
+    HAVING SUM("entries"."amount") <> 0
+    LIMIT 1;
+
+    IF "unbalanced_currency" IS NOT NULL THEN
+        RAISE EXCEPTION 'journal % does not balance in %', "checked_journal_id", "unbalanced_currency"
+            USING ERRCODE = 'check_violation';
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION "check_journal_balanced"() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM "assert_journal_balanced"(NEW."journal_id");
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM "assert_journal_balanced"(OLD."journal_id");
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- The postings of a journal are written one at a time, so they are only checked when the transaction commits
+CREATE CONSTRAINT TRIGGER "entries_journal_balanced"
+    AFTER INSERT OR UPDATE OR DELETE
+    ON "entries"
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+EXECUTE FUNCTION "check_journal_balanced"();
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'the entries of a journal sum to zero in each currency, it is NULL for entries booked before journals';
+
+COMMENT ON COLUMN "transfers"."journal_id" IS 'the journal moving the money, it is NULL until the transfer is posted and for transfers posted before journals';
+
+COMMENT ON COLUMN "fx_accounts"."account_id" IS 'the account on the other side of the money converted to or from the currency';
@@ -0,0 +1,12 @@
+ALTER TABLE "scheduled_transfers"
+    ADD COLUMN "claimed_at" timestamptz;
+
+-- Transfers left processing before claims were recorded are treated as claimed now, so they are picked up again once
+-- the claim times out
+UPDATE "scheduled_transfers"
+SET "claimed_at" = now()
+WHERE "status" = 'processing';
+
+CREATE INDEX ON "scheduled_transfers" ("status", "claimed_at");
+
+COMMENT ON COLUMN "scheduled_transfers"."claimed_at" IS 'when a worker last claimed the transfer, a processing transfer whose claim timed out is claimed again';
@@ -0,0 +1,17 @@
+CREATE TABLE "account_co_owners"
+(
+    "account_id" bigint      NOT NULL,
+    "username"   varchar     NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("account_id", "username")
+);
+
+ALTER TABLE "account_co_owners"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_co_owners"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE INDEX ON "account_co_owners" ("username");
+
+COMMENT ON COLUMN "account_co_owners"."username" IS 'a co-owner may approve or reject the large transfers of the account, never the owner of the account';
@@ -17,6 +17,14 @@ FROM accounts
 WHERE id = $1
 LIMIT 1 FOR NO KEY UPDATE;
 
+-- name: GetRecipientAccount :one
+SELECT accounts.*
+FROM accounts
+         JOIN users ON users.username = accounts.owner
+WHERE (users.username = sqlc.arg(username) OR users.email = sqlc.arg(email))
+  AND accounts.currency = sqlc.arg(currency)
+LIMIT 1;
+
 -- name: ListAccounts :many
 SELECT *
 FROM accounts
@@ -36,7 +44,19 @@ SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
+-- name: AddAccountHeld :one
+UPDATE accounts
+SET held = held + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: DeleteAccount :exec
 DELETE
 FROM accounts
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = sqlc.arg(overdraft_limit)
+WHERE id = sqlc.arg(id)
+RETURNING *;
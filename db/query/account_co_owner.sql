@@ -0,0 +1,25 @@
+-- name: CreateAccountCoOwner :one
+INSERT INTO account_co_owners (account_id,
+                               username)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetAccountCoOwner :one
+SELECT *
+FROM account_co_owners
+WHERE account_id = $1
+  AND username = $2
+LIMIT 1;
+
+-- name: ListAccountCoOwners :many
+SELECT *
+FROM account_co_owners
+WHERE account_id = $1
+ORDER BY username;
+
+-- name: DeleteAccountCoOwner :one
+DELETE
+FROM account_co_owners
+WHERE account_id = $1
+  AND username = $2
+RETURNING *;
\ No newline at end of file
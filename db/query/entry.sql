@@ -1,7 +1,8 @@
 -- name: CreateEntry :one
 INSERT INTO entries (account_id,
-                     amount)
-VALUES ($1, $2)
+                     amount,
+                     journal_id)
+VALUES ($1, $2, $3)
 RETURNING *;
 
 -- name: GetEntry :one
@@ -15,4 +16,24 @@ SELECT *
 FROM entries
 WHERE account_id = $1
 ORDER BY id
-LIMIT $2 OFFSET $3;
\ No newline at end of file
+LIMIT $2 OFFSET $3;
+
+-- name: ListJournalEntries :many
+SELECT *
+FROM entries
+WHERE journal_id = $1
+ORDER BY id;
+
+-- name: ListEntriesBetween :many
+SELECT *
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(created_from)
+  AND created_at < sqlc.arg(created_to)
+ORDER BY id;
+
+-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(created_from);
\ No newline at end of file
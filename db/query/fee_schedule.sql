@@ -0,0 +1,39 @@
+-- name: DeleteFeeSchedule :one
+DELETE
+FROM fee_schedules
+WHERE currency = $1
+RETURNING *;
+
+-- name: GetFeeAccount :one
+SELECT accounts.*
+FROM accounts
+         JOIN fee_accounts ON fee_accounts.account_id = accounts.id
+WHERE fee_accounts.currency = $1
+LIMIT 1;
+
+-- name: GetFeeSchedule :one
+SELECT *
+FROM fee_schedules
+WHERE currency = $1
+LIMIT 1;
+
+-- name: ListFeeSchedules :many
+SELECT *
+FROM fee_schedules
+ORDER BY currency;
+
+-- name: UpsertFeeSchedule :one
+INSERT INTO fee_schedules (currency,
+                           flat,
+                           percentage,
+                           min_fee,
+                           max_fee,
+                           free_transfers_per_month)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (currency) DO UPDATE SET flat                     = excluded.flat,
+                                     percentage               = excluded.percentage,
+                                     min_fee                  = excluded.min_fee,
+                                     max_fee                  = excluded.max_fee,
+                                     free_transfers_per_month = excluded.free_transfers_per_month,
+                                     updated_at               = now()
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- name: GetFxAccount :one
+SELECT accounts.*
+FROM accounts
+         JOIN fx_accounts ON fx_accounts.account_id = accounts.id
+WHERE fx_accounts.currency = $1
+LIMIT 1;
\ No newline at end of file
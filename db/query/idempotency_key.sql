@@ -0,0 +1,15 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (username,
+                              idempotency_key,
+                              request_hash,
+                              response)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (username, idempotency_key) DO NOTHING
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT *
+FROM idempotency_keys
+WHERE username = $1
+  AND idempotency_key = $2
+LIMIT 1;
\ No newline at end of file
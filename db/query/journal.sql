@@ -0,0 +1,9 @@
+-- name: CreateJournal :one
+INSERT INTO journals DEFAULT VALUES
+RETURNING *;
+
+-- name: GetJournal :one
+SELECT *
+FROM journals
+WHERE id = $1
+LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,49 @@
+-- name: CreateMoneyRequest :one
+INSERT INTO money_requests (requester,
+                            payer,
+                            amount,
+                            currency,
+                            expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetMoneyRequest :one
+SELECT *
+FROM money_requests
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetMoneyRequestForUpdate :one
+SELECT *
+FROM money_requests
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: ListMoneyRequests :many
+SELECT *
+FROM money_requests
+WHERE ((sqlc.arg(direction)::varchar = 'incoming' AND payer = sqlc.arg(username)) OR
+       (sqlc.arg(direction)::varchar = 'outgoing' AND requester = sqlc.arg(username)))
+  AND (sqlc.arg(status)::varchar = '' OR status = sqlc.arg(status))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CloseMoneyRequest :one
+UPDATE money_requests
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+  AND status = 'pending'
+RETURNING *;
+
+-- name: AcceptMoneyRequest :one
+UPDATE money_requests
+SET status      = 'accepted',
+    transfer_id = sqlc.arg(transfer_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ExpireMoneyRequests :exec
+UPDATE money_requests
+SET status = 'expired'
+WHERE status = 'pending'
+  AND expires_at <= now();
\ No newline at end of file
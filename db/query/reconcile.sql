@@ -0,0 +1,53 @@
+-- name: ReconcileAccounts :many
+SELECT accounts.id,
+       accounts.balance,
+       COALESCE(SUM(entries.amount), 0)::bigint AS entries_total
+FROM accounts
+         LEFT JOIN entries ON entries.account_id = accounts.id
+WHERE accounts.id > sqlc.arg(after_id)
+GROUP BY accounts.id
+ORDER BY accounts.id
+LIMIT sqlc.arg('limit');
+
+-- name: ReconcileEntries :many
+SELECT entries.id,
+       entries.account_id,
+       entries.amount,
+       CASE
+           WHEN entries.journal_id IS NOT NULL
+               THEN EXISTS(SELECT 1
+                           FROM transfers
+                           WHERE transfers.journal_id = entries.journal_id)
+           ELSE EXISTS(SELECT 1
+                       FROM transfers
+                       WHERE transfers.journal_id IS NULL
+                         AND transfers.posted_at = entries.created_at
+                         AND ((transfers.from_account_id = entries.account_id AND -transfers.amount = entries.amount)
+                           OR (transfers.to_account_id = entries.account_id AND transfers.to_amount = entries.amount)))
+           END AS has_transfer
+FROM entries
+WHERE entries.id > sqlc.arg(after_id)
+ORDER BY entries.id
+LIMIT sqlc.arg('limit');
+
+-- name: ReconcileTransfers :many
+SELECT transfers.id,
+       (SELECT COUNT(*)
+        FROM entries
+        WHERE entries.account_id = transfers.from_account_id
+          AND entries.amount = -transfers.amount
+          AND (entries.journal_id = transfers.journal_id
+            OR (transfers.journal_id IS NULL AND entries.journal_id IS NULL
+                AND entries.created_at = transfers.posted_at))) AS debit_entries,
+       (SELECT COUNT(*)
+        FROM entries
+        WHERE entries.account_id = transfers.to_account_id
+          AND entries.amount = transfers.to_amount
+          AND (entries.journal_id = transfers.journal_id
+            OR (transfers.journal_id IS NULL AND entries.journal_id IS NULL
+                AND entries.created_at = transfers.posted_at))) AS credit_entries
+FROM transfers
+WHERE transfers.id > sqlc.arg(after_id)
+  AND transfers.status = 'posted'
+ORDER BY transfers.id
+LIMIT sqlc.arg('limit');
\ No newline at end of file
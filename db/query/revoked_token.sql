@@ -0,0 +1,16 @@
+-- name: CreateRevokedToken :one
+INSERT INTO revoked_tokens (id,
+                            username,
+                            expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (id) DO UPDATE SET revoked_at = revoked_tokens.revoked_at
+RETURNING *;
+
+-- name: IsTokenRevoked :one
+SELECT EXISTS(SELECT 1
+              FROM revoked_tokens
+              WHERE revoked_tokens.id = sqlc.arg(id))
+           OR EXISTS(SELECT 1
+                     FROM users
+                     WHERE users.username = sqlc.arg(username)
+                       AND users.tokens_revoked_at > sqlc.arg(issued_at)) AS revoked;
\ No newline at end of file
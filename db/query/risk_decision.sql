@@ -0,0 +1,24 @@
+-- name: CreateRiskDecision :one
+INSERT INTO risk_decisions (username,
+                            from_account_id,
+                            to_account_id,
+                            amount,
+                            currency,
+                            decision,
+                            rule_hits)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetRiskDecision :one
+SELECT *
+FROM risk_decisions
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListRiskDecisions :many
+SELECT *
+FROM risk_decisions
+WHERE (sqlc.arg(username)::varchar = '' OR username = sqlc.arg(username))
+  AND (sqlc.arg(decision)::varchar = '' OR decision = sqlc.arg(decision))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
\ No newline at end of file
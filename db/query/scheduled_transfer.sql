@@ -0,0 +1,85 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (from_account_id,
+                                 to_account_id,
+                                 amount,
+                                 execute_at,
+                                 next_attempt_at)
+VALUES ($1, $2, $3, $4, $4)
+RETURNING *;
+
+-- name: CreateStandingOrderTransfer :one
+INSERT INTO scheduled_transfers (from_account_id,
+                                 to_account_id,
+                                 amount,
+                                 execute_at,
+                                 next_attempt_at,
+                                 standing_order_id)
+VALUES ($1, $2, $3, $4, $4, $5)
+RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT *
+FROM scheduled_transfers
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT *
+FROM scheduled_transfers
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: ListOwnerScheduledTransfers :many
+SELECT scheduled_transfers.*
+FROM scheduled_transfers
+         JOIN accounts ON accounts.id = scheduled_transfers.from_account_id
+WHERE accounts.owner = $1
+ORDER BY scheduled_transfers.id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CancelScheduledTransfer :one
+UPDATE scheduled_transfers
+SET status = 'cancelled'
+WHERE id = $1
+  AND status IN ('pending', 'processing')
+RETURNING *;
+
+-- name: CancelStandingOrderTransfers :exec
+UPDATE scheduled_transfers
+SET status = 'cancelled'
+WHERE standing_order_id = $1
+  AND status IN ('pending', 'processing');
+
+-- name: ClaimDueScheduledTransfers :many
+UPDATE scheduled_transfers
+SET status        = 'processing',
+    attempt_count = attempt_count + 1,
+    claimed_at    = now()
+WHERE id IN (SELECT id
+             FROM scheduled_transfers
+             WHERE (status = 'pending' AND next_attempt_at <= now())
+                OR (status = 'processing' AND claimed_at <= sqlc.arg(stale_before))
+             ORDER BY next_attempt_at
+             LIMIT sqlc.arg('limit') FOR UPDATE SKIP LOCKED)
+RETURNING *;
+
+-- name: UpdateScheduledTransferResult :one
+UPDATE scheduled_transfers
+SET status          = $2,
+    transfer_id     = $3,
+    next_attempt_at = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateScheduledTransferAttempt :one
+INSERT INTO scheduled_transfer_attempts (scheduled_transfer_id,
+                                         transfer_id,
+                                         error)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListScheduledTransferAttempts :many
+SELECT *
+FROM scheduled_transfer_attempts
+WHERE scheduled_transfer_id = $1
+ORDER BY id;
\ No newline at end of file
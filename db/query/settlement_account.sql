@@ -0,0 +1,12 @@
+-- name: GetSettlementAccount :one
+SELECT accounts.*
+FROM accounts
+         JOIN settlement_accounts ON settlement_accounts.account_id = accounts.id
+WHERE settlement_accounts.currency = $1
+LIMIT 1;
+
+-- name: ListSettlementAccounts :many
+SELECT accounts.*
+FROM accounts
+         JOIN settlement_accounts ON settlement_accounts.account_id = accounts.id
+ORDER BY accounts.id;
\ No newline at end of file
@@ -0,0 +1,63 @@
+-- name: CreateStandingOrder :one
+INSERT INTO standing_orders (from_account_id,
+                             to_account_id,
+                             amount,
+                             frequency,
+                             day_of_month,
+                             next_run_at,
+                             end_at,
+                             max_runs)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetStandingOrder :one
+SELECT *
+FROM standing_orders
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListOwnerStandingOrders :many
+SELECT standing_orders.*
+FROM standing_orders
+         JOIN accounts ON accounts.id = standing_orders.from_account_id
+WHERE accounts.owner = $1
+ORDER BY standing_orders.id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ClaimDueStandingOrders :many
+SELECT *
+FROM standing_orders
+WHERE status = 'active'
+  AND next_run_at <= now()
+ORDER BY next_run_at
+LIMIT $1 FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateStandingOrderRun :one
+UPDATE standing_orders
+SET next_run_at = $2,
+    run_count   = $3,
+    status      = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: PauseStandingOrder :one
+UPDATE standing_orders
+SET status = 'paused'
+WHERE id = $1
+  AND status = 'active'
+RETURNING *;
+
+-- name: ResumeStandingOrder :one
+UPDATE standing_orders
+SET status      = 'active',
+    next_run_at = $2
+WHERE id = $1
+  AND status = 'paused'
+RETURNING *;
+
+-- name: CancelStandingOrder :one
+UPDATE standing_orders
+SET status = 'cancelled'
+WHERE id = $1
+  AND status IN ('active', 'paused')
+RETURNING *;
\ No newline at end of file
@@ -1,15 +1,31 @@
 -- name: CreateTransfer :one
 INSERT INTO transfers (from_account_id,
                        to_account_id,
-                       amount)
-VALUES ($1, $2, $3)
+                       amount,
+                       to_amount,
+                       exchange_rate,
+                       memo,
+                       reference,
+                       metadata,
+                       journal_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING *;
 
 -- name: GetTransfer :one
+SELECT transfers.*,
+       COALESCE(reversed_by.reversal_transfer_id, 0)::bigint AS reversed_by_transfer_id,
+       COALESCE(reversal_of.transfer_id, 0)::bigint          AS reversal_of_transfer_id
+FROM transfers
+         LEFT JOIN transfer_reversals AS reversed_by ON reversed_by.transfer_id = transfers.id
+         LEFT JOIN transfer_reversals AS reversal_of ON reversal_of.reversal_transfer_id = transfers.id
+WHERE transfers.id = $1
+LIMIT 1;
+
+-- name: GetTransferForUpdate :one
 SELECT *
 FROM transfers
 WHERE id = $1
-LIMIT 1;
+LIMIT 1 FOR NO KEY UPDATE;
 
 -- name: ListTransfers :many
 SELECT *
@@ -17,4 +33,112 @@ FROM transfers
 WHERE from_account_id = $1
    OR to_account_id = $2
 ORDER BY id
-LIMIT $3 OFFSET $4;
\ No newline at end of file
+LIMIT $3 OFFSET $4;
+
+-- name: ListOwnerTransfers :many
+SELECT transfers.*,
+       COALESCE(reversed_by.reversal_transfer_id, 0)::bigint AS reversed_by_transfer_id,
+       COALESCE(reversal_of.transfer_id, 0)::bigint          AS reversal_of_transfer_id
+FROM transfers
+         JOIN accounts AS from_accounts ON from_accounts.id = transfers.from_account_id
+         JOIN accounts AS to_accounts ON to_accounts.id = transfers.to_account_id
+         LEFT JOIN transfer_reversals AS reversed_by ON reversed_by.transfer_id = transfers.id
+         LEFT JOIN transfer_reversals AS reversal_of ON reversal_of.reversal_transfer_id = transfers.id
+WHERE ((from_accounts.owner = sqlc.arg(owner) AND sqlc.arg(direction)::varchar <> 'in' AND
+        (sqlc.arg(account_id)::bigint = 0 OR transfers.from_account_id = sqlc.arg(account_id)))
+    OR (to_accounts.owner = sqlc.arg(owner) AND sqlc.arg(direction)::varchar <> 'out' AND
+        (sqlc.arg(account_id)::bigint = 0 OR transfers.to_account_id = sqlc.arg(account_id))))
+  AND transfers.created_at >= sqlc.arg(created_from)
+  AND transfers.created_at < sqlc.arg(created_to)
+  AND transfers.amount >= sqlc.arg(min_amount)
+  AND transfers.amount <= sqlc.arg(max_amount)
+  AND (sqlc.arg(search)::varchar = '' OR strpos(lower(transfers.memo), lower(sqlc.arg(search))) > 0 OR
+       strpos(lower(transfers.reference), lower(sqlc.arg(search))) > 0)
+  AND transfers.metadata @> sqlc.arg(metadata)::jsonb
+ORDER BY transfers.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: SumOutgoingTransfersSince :one
+SELECT COALESCE(SUM(transfers.amount), 0)::bigint AS total
+FROM transfers
+         JOIN accounts ON accounts.id = transfers.from_account_id
+WHERE accounts.owner = sqlc.arg(owner)
+  AND accounts.currency = sqlc.arg(currency)
+  AND transfers.created_at >= sqlc.arg(created_from)
+  AND transfers.status <> 'voided'
+  AND NOT EXISTS(SELECT 1 FROM transfer_fees WHERE transfer_fees.fee_transfer_id = transfers.id)
+  AND NOT EXISTS(SELECT 1 FROM transfer_reversals WHERE transfer_reversals.reversal_transfer_id = transfers.id);
+
+-- name: GetOutgoingTransferStatsSince :one
+SELECT COUNT(*) AS count,
+       COALESCE(SUM(transfers.amount), 0)::bigint AS total
+FROM transfers
+         JOIN accounts ON accounts.id = transfers.from_account_id
+WHERE accounts.owner = sqlc.arg(owner)
+  AND accounts.currency = sqlc.arg(currency)
+  AND transfers.created_at >= sqlc.arg(created_from)
+  AND transfers.status = 'posted'
+  AND NOT EXISTS(SELECT 1 FROM transfer_fees WHERE transfer_fees.fee_transfer_id = transfers.id)
+  AND NOT EXISTS(SELECT 1 FROM transfer_reversals WHERE transfer_reversals.reversal_transfer_id = transfers.id);
+
+-- name: CountTransfersToAccount :one
+SELECT COUNT(*)
+FROM transfers
+         JOIN accounts ON accounts.id = transfers.from_account_id
+WHERE accounts.owner = sqlc.arg(owner)
+  AND transfers.to_account_id = sqlc.arg(to_account_id);
+
+-- name: CreatePendingTransfer :one
+INSERT INTO transfers (from_account_id,
+                       to_account_id,
+                       amount,
+                       to_amount,
+                       exchange_rate,
+                       memo,
+                       reference,
+                       metadata,
+                       status,
+                       posted_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'pending', NULL)
+RETURNING *;
+
+-- name: PostPendingTransfer :one
+UPDATE transfers
+SET status     = 'posted',
+    amount     = sqlc.arg(amount),
+    to_amount  = sqlc.arg(to_amount),
+    journal_id = sqlc.arg(journal_id),
+    posted_at  = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: VoidPendingTransfer :one
+UPDATE transfers
+SET status = 'voided'
+WHERE id = $1
+RETURNING *;
+
+-- name: ListExpiredPendingTransfersForUpdate :many
+SELECT transfers.*
+FROM transfers
+         JOIN transfer_holds ON transfer_holds.transfer_id = transfers.id
+WHERE transfers.status = 'pending'
+  AND transfer_holds.expires_at <= now()
+ORDER BY transfers.id
+FOR NO KEY UPDATE OF transfers;
+
+-- name: ExpirePendingTransfers :exec
+WITH expired AS (
+    UPDATE transfers
+        SET status = 'voided'
+        FROM transfer_holds
+        WHERE transfer_holds.transfer_id = transfers.id
+            AND transfers.status = 'pending'
+            AND transfer_holds.expires_at <= now()
+        RETURNING transfers.from_account_id, transfers.amount)
+UPDATE accounts
+SET held = held - released.amount
+FROM (SELECT from_account_id, SUM(amount)::bigint AS amount
+      FROM expired
+      GROUP BY from_account_id) AS released
+WHERE accounts.id = released.from_account_id;
\ No newline at end of file
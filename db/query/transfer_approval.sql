@@ -0,0 +1,76 @@
+-- name: CreateTransferApproval :one
+INSERT INTO transfer_approvals (from_account_id,
+                                to_account_id,
+                                amount,
+                                memo,
+                                reference,
+                                metadata,
+                                requested_by,
+                                idempotency_key,
+                                request_hash,
+                                expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: GetTransferApproval :one
+SELECT *
+FROM transfer_approvals
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetTransferApprovalForUpdate :one
+SELECT *
+FROM transfer_approvals
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: GetTransferApprovalByIdempotencyKey :one
+SELECT *
+FROM transfer_approvals
+WHERE requested_by = $1
+  AND idempotency_key = $2
+LIMIT 1;
+
+-- name: ListTransferApprovals :many
+SELECT *
+FROM transfer_approvals
+WHERE (sqlc.arg(username)::varchar = '' OR requested_by = sqlc.arg(username) OR
+       EXISTS(SELECT 1
+              FROM account_co_owners
+              WHERE account_co_owners.account_id = transfer_approvals.from_account_id
+                AND account_co_owners.username = sqlc.arg(username)))
+  AND (sqlc.arg(status)::varchar = '' OR status = sqlc.arg(status))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: DecideTransferApproval :one
+UPDATE transfer_approvals
+SET status      = sqlc.arg(status),
+    decided_by  = sqlc.arg(decided_by),
+    reason      = sqlc.arg(reason),
+    transfer_id = sqlc.arg(transfer_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListExpiredTransferApprovalsForUpdate :many
+SELECT *
+FROM transfer_approvals
+WHERE status = 'pending'
+  AND expires_at <= now()
+ORDER BY id
+FOR NO KEY UPDATE;
+
+-- name: ExpireTransferApprovals :exec
+WITH expired AS (
+    UPDATE transfer_approvals
+        SET status = 'expired'
+        WHERE status = 'pending'
+            AND expires_at <= now()
+        RETURNING from_account_id, amount)
+UPDATE accounts
+SET held = held - released.amount
+FROM (SELECT from_account_id, SUM(amount)::bigint AS amount
+      FROM expired
+      GROUP BY from_account_id) AS released
+WHERE accounts.id = released.from_account_id;
\ No newline at end of file
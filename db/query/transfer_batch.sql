@@ -0,0 +1,24 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (from_account_id,
+                              total_amount)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetTransferBatch :one
+SELECT *
+FROM transfer_batches
+WHERE id = $1
+LIMIT 1;
+
+-- name: CreateTransferBatchLeg :one
+INSERT INTO transfer_batch_legs (batch_id,
+                                 leg_index,
+                                 transfer_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListTransferBatchLegs :many
+SELECT *
+FROM transfer_batch_legs
+WHERE batch_id = $1
+ORDER BY leg_index;
\ No newline at end of file
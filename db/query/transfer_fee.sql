@@ -0,0 +1,16 @@
+-- name: CreateTransferFee :one
+INSERT INTO transfer_fees (transfer_id,
+                           fee_transfer_id,
+                           flat,
+                           percentage,
+                           percentage_amount,
+                           adjustment,
+                           total)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetTransferFee :one
+SELECT *
+FROM transfer_fees
+WHERE transfer_id = $1
+LIMIT 1;
\ No newline at end of file
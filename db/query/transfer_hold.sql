@@ -0,0 +1,12 @@
+-- name: CreateTransferHold :one
+INSERT INTO transfer_holds (transfer_id,
+                            amount,
+                            expires_at)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetTransferHold :one
+SELECT *
+FROM transfer_holds
+WHERE transfer_id = $1
+LIMIT 1;
\ No newline at end of file
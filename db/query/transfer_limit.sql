@@ -0,0 +1,34 @@
+-- name: DeleteTransferLimit :one
+DELETE
+FROM transfer_limits
+WHERE scope = $1
+  AND subject = $2
+  AND currency = $3
+RETURNING *;
+
+-- name: GetEffectiveTransferLimit :one
+SELECT *
+FROM transfer_limits
+WHERE currency = sqlc.arg(currency)
+  AND ((scope = 'user' AND subject = sqlc.arg(username)) OR (scope = 'role' AND subject = sqlc.arg(role)))
+ORDER BY scope = 'user' DESC
+LIMIT 1;
+
+-- name: ListTransferLimits :many
+SELECT *
+FROM transfer_limits
+ORDER BY scope, subject, currency;
+
+-- name: UpsertTransferLimit :one
+INSERT INTO transfer_limits (scope,
+                             subject,
+                             currency,
+                             per_transaction,
+                             daily,
+                             monthly)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (scope, subject, currency) DO UPDATE SET per_transaction = excluded.per_transaction,
+                                                     daily           = excluded.daily,
+                                                     monthly         = excluded.monthly,
+                                                     updated_at      = now()
+RETURNING *;
\ No newline at end of file
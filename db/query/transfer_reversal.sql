@@ -0,0 +1,14 @@
+-- name: CreateTransferReversal :one
+INSERT INTO transfer_reversals (transfer_id,
+                                reversal_transfer_id,
+                                reason,
+                                reversed_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetTransferReversal :one
+SELECT *
+FROM transfer_reversals
+WHERE transfer_id = $1
+   OR reversal_transfer_id = $1
+LIMIT 1;
\ No newline at end of file
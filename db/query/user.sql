@@ -7,4 +7,22 @@ RETURNING *;
 SELECT *
 FROM users
 WHERE username = $1
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: GetUserForUpdate :one
+SELECT *
+FROM users
+WHERE username = $1
+LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: RevokeUserTokens :one
+UPDATE users
+SET tokens_revoked_at = now()
+WHERE username = $1
+RETURNING *;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = sqlc.arg(role)
+WHERE username = sqlc.arg(username)
+RETURNING *;
\ No newline at end of file
@@ -11,7 +11,7 @@ const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held, available_balance
 `
 
 type AddAccountBalanceParams struct {
@@ -28,6 +28,37 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Held,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const addAccountHeld = `-- name: AddAccountHeld :one
+UPDATE accounts
+SET held = held + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held, available_balance
+`
+
+type AddAccountHeldParams struct {
+	Amount int64 `json:"amount"`
+	ID     int32 `json:"id"`
+}
+
+func (q *Queries) AddAccountHeld(ctx context.Context, arg AddAccountHeldParams) (Account, error) {
+	row := q.queryRow(ctx, q.addAccountHeldStmt, addAccountHeld, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Held,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
@@ -37,7 +68,7 @@ INSERT INTO accounts (owner,
                       balance,
                       currency)
 VALUES ($1, $2, $3)
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held, available_balance
 `
 
 type CreateAccountParams struct {
@@ -55,6 +86,9 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Held,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
@@ -71,7 +105,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int32) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at
+SELECT id, owner, balance, currency, created_at, overdraft_limit, held, available_balance
 FROM accounts
 WHERE id = $1
 LIMIT 1
@@ -86,12 +120,15 @@ func (q *Queries) GetAccount(ctx context.Context, id int32) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Held,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at
+SELECT id, owner, balance, currency, created_at, overdraft_limit, held, available_balance
 FROM accounts
 WHERE id = $1
 LIMIT 1 FOR NO KEY UPDATE
@@ -106,12 +143,46 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int32) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Held,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const getRecipientAccount = `-- name: GetRecipientAccount :one
+SELECT accounts.id, accounts.owner, accounts.balance, accounts.currency, accounts.created_at, accounts.overdraft_limit, accounts.held, accounts.available_balance
+FROM accounts
+         JOIN users ON users.username = accounts.owner
+WHERE (users.username = $1 OR users.email = $2)
+  AND accounts.currency = $3
+LIMIT 1
+`
+
+type GetRecipientAccountParams struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetRecipientAccount(ctx context.Context, arg GetRecipientAccountParams) (Account, error) {
+	row := q.queryRow(ctx, q.getRecipientAccountStmt, getRecipientAccount, arg.Username, arg.Email, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Held,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at
+SELECT id, owner, balance, currency, created_at, overdraft_limit, held, available_balance
 FROM accounts
 WHERE owner = $1
 ORDER BY id
@@ -139,6 +210,9 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.Held,
+			&i.AvailableBalance,
 		); err != nil {
 			return nil, err
 		}
@@ -157,7 +231,7 @@ const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held, available_balance
 `
 
 type UpdateAccountParams struct {
@@ -174,6 +248,37 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Held,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const updateAccountOverdraftLimit = `-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, held, available_balance
+`
+
+type UpdateAccountOverdraftLimitParams struct {
+	OverdraftLimit int64 `json:"overdraftLimit"`
+	ID             int32 `json:"id"`
+}
+
+func (q *Queries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	row := q.queryRow(ctx, q.updateAccountOverdraftLimitStmt, updateAccountOverdraftLimit, arg.OverdraftLimit, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Held,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func createRandomAccountCoOwner(t *testing.T, account Account) AccountCoOwner {
+	user := createRandomUser(t)
+
+	arg := CreateAccountCoOwnerParams{
+		AccountID: int64(account.ID),
+		Username:  user.Username,
+	}
+
+	coOwner, err := testQueries.CreateAccountCoOwner(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.AccountID, coOwner.AccountID)
+	require.Equal(t, arg.Username, coOwner.Username)
+	require.NotZero(t, coOwner.CreatedAt)
+
+	return coOwner
+}
+
+func TestQueries_CreateAccountCoOwner(t *testing.T) {
+	account := createRandomAccount(t)
+	coOwner := createRandomAccountCoOwner(t, account)
+
+	// A user co-owns an account at most once
+	_, err := testQueries.CreateAccountCoOwner(context.Background(), CreateAccountCoOwnerParams{
+		AccountID: coOwner.AccountID,
+		Username:  coOwner.Username,
+	})
+	require.Error(t, err)
+
+	got, err := testQueries.GetAccountCoOwner(context.Background(), GetAccountCoOwnerParams{
+		AccountID: coOwner.AccountID,
+		Username:  coOwner.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, coOwner.Username, got.Username)
+}
+
+func TestQueries_ListAccountCoOwners(t *testing.T) {
+	account := createRandomAccount(t)
+
+	for i := 0; i < 2; i++ {
+		createRandomAccountCoOwner(t, account)
+	}
+
+	// Co-owners of other accounts are left out
+	createRandomAccountCoOwner(t, createRandomAccount(t))
+
+	coOwners, err := testQueries.ListAccountCoOwners(context.Background(), int64(account.ID))
+	require.NoError(t, err)
+	require.Len(t, coOwners, 2)
+	require.Less(t, coOwners[0].Username, coOwners[1].Username)
+}
+
+func TestQueries_DeleteAccountCoOwner(t *testing.T) {
+	account := createRandomAccount(t)
+	coOwner := createRandomAccountCoOwner(t, account)
+
+	arg := DeleteAccountCoOwnerParams{
+		AccountID: coOwner.AccountID,
+		Username:  coOwner.Username,
+	}
+
+	deleted, err := testQueries.DeleteAccountCoOwner(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, coOwner.Username, deleted.Username)
+
+	_, err = testQueries.DeleteAccountCoOwner(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
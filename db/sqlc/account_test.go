@@ -10,12 +10,20 @@ import (
 )
 
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountWithBalance(t, util.RandomMoney())
+}
+
+func createRandomAccountWithBalance(t *testing.T, balance int64) Account {
+	return createRandomAccountWithCurrency(t, balance, util.RandomCurrency())
+}
+
+func createRandomAccountWithCurrency(t *testing.T, balance int64, currency string) Account {
 	user := createRandomUser(t)
 
 	arg := CreateAccountParams{
 		Owner:    user.Username,
-		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Balance:  balance,
+		Currency: currency,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -27,6 +35,8 @@ func createRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
 
+	require.Zero(t, account.OverdraftLimit)
+
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
 
@@ -53,6 +63,34 @@ func TestQueries_GetAccount(t *testing.T) {
 	require.WithinDuration(t, newAccount.CreatedAt, fetchedAccount.CreatedAt, time.Second)
 }
 
+func TestQueries_GetRecipientAccount(t *testing.T) {
+	account := createRandomAccountWithCurrency(t, util.RandomMoney(), util.USD)
+
+	owner, err := testQueries.GetUser(context.Background(), account.Owner)
+	require.NoError(t, err)
+
+	// The owner can be addressed by username or by email
+	byUsername, err := testQueries.GetRecipientAccount(context.Background(), GetRecipientAccountParams{
+		Username: owner.Username,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, byUsername.ID)
+
+	byEmail, err := testQueries.GetRecipientAccount(context.Background(), GetRecipientAccountParams{
+		Email:    owner.Email,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, byEmail.ID)
+
+	_, err = testQueries.GetRecipientAccount(context.Background(), GetRecipientAccountParams{
+		Username: owner.Username,
+		Currency: util.EUR,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
 func TestQueries_UpdateAccount(t *testing.T) {
 	newAccount := createRandomAccount(t)
 
@@ -73,6 +111,24 @@ func TestQueries_UpdateAccount(t *testing.T) {
 	require.Equal(t, arg.Balance, updatedAccount.Balance)
 }
 
+func TestQueries_UpdateAccountOverdraftLimit(t *testing.T) {
+	newAccount := createRandomAccount(t)
+
+	arg := UpdateAccountOverdraftLimitParams{
+		ID:             newAccount.ID,
+		OverdraftLimit: util.RandomMoney(),
+	}
+
+	updatedAccount, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, updatedAccount)
+
+	require.Equal(t, newAccount.ID, updatedAccount.ID)
+	require.Equal(t, newAccount.Balance, updatedAccount.Balance)
+	require.Equal(t, arg.OverdraftLimit, updatedAccount.OverdraftLimit)
+}
+
 func TestQueries_DeleteAccount(t *testing.T) {
 	newAccount := createRandomAccount(t)
 
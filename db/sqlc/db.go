@@ -22,111 +22,738 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.acceptMoneyRequestStmt, err = db.PrepareContext(ctx, acceptMoneyRequest); err != nil {
+		return nil, fmt.Errorf("error preparing query AcceptMoneyRequest: %w", err)
+	}
 	if q.addAccountBalanceStmt, err = db.PrepareContext(ctx, addAccountBalance); err != nil {
 		return nil, fmt.Errorf("error preparing query AddAccountBalance: %w", err)
 	}
+	if q.addAccountHeldStmt, err = db.PrepareContext(ctx, addAccountHeld); err != nil {
+		return nil, fmt.Errorf("error preparing query AddAccountHeld: %w", err)
+	}
+	if q.blockSessionStmt, err = db.PrepareContext(ctx, blockSession); err != nil {
+		return nil, fmt.Errorf("error preparing query BlockSession: %w", err)
+	}
+	if q.blockUserSessionsStmt, err = db.PrepareContext(ctx, blockUserSessions); err != nil {
+		return nil, fmt.Errorf("error preparing query BlockUserSessions: %w", err)
+	}
+	if q.cancelScheduledTransferStmt, err = db.PrepareContext(ctx, cancelScheduledTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query CancelScheduledTransfer: %w", err)
+	}
+	if q.cancelStandingOrderStmt, err = db.PrepareContext(ctx, cancelStandingOrder); err != nil {
+		return nil, fmt.Errorf("error preparing query CancelStandingOrder: %w", err)
+	}
+	if q.cancelStandingOrderTransfersStmt, err = db.PrepareContext(ctx, cancelStandingOrderTransfers); err != nil {
+		return nil, fmt.Errorf("error preparing query CancelStandingOrderTransfers: %w", err)
+	}
+	if q.claimDueScheduledTransfersStmt, err = db.PrepareContext(ctx, claimDueScheduledTransfers); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimDueScheduledTransfers: %w", err)
+	}
+	if q.claimDueStandingOrdersStmt, err = db.PrepareContext(ctx, claimDueStandingOrders); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimDueStandingOrders: %w", err)
+	}
+	if q.closeMoneyRequestStmt, err = db.PrepareContext(ctx, closeMoneyRequest); err != nil {
+		return nil, fmt.Errorf("error preparing query CloseMoneyRequest: %w", err)
+	}
+	if q.countTransfersToAccountStmt, err = db.PrepareContext(ctx, countTransfersToAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query CountTransfersToAccount: %w", err)
+	}
 	if q.createAccountStmt, err = db.PrepareContext(ctx, createAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateAccount: %w", err)
 	}
+	if q.createAccountCoOwnerStmt, err = db.PrepareContext(ctx, createAccountCoOwner); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAccountCoOwner: %w", err)
+	}
 	if q.createEntryStmt, err = db.PrepareContext(ctx, createEntry); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateEntry: %w", err)
 	}
+	if q.createIdempotencyKeyStmt, err = db.PrepareContext(ctx, createIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateIdempotencyKey: %w", err)
+	}
+	if q.createJournalStmt, err = db.PrepareContext(ctx, createJournal); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateJournal: %w", err)
+	}
+	if q.createMoneyRequestStmt, err = db.PrepareContext(ctx, createMoneyRequest); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateMoneyRequest: %w", err)
+	}
+	if q.createPendingTransferStmt, err = db.PrepareContext(ctx, createPendingTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query CreatePendingTransfer: %w", err)
+	}
+	if q.createRevokedTokenStmt, err = db.PrepareContext(ctx, createRevokedToken); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateRevokedToken: %w", err)
+	}
+	if q.createRiskDecisionStmt, err = db.PrepareContext(ctx, createRiskDecision); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateRiskDecision: %w", err)
+	}
+	if q.createScheduledTransferStmt, err = db.PrepareContext(ctx, createScheduledTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateScheduledTransfer: %w", err)
+	}
+	if q.createScheduledTransferAttemptStmt, err = db.PrepareContext(ctx, createScheduledTransferAttempt); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateScheduledTransferAttempt: %w", err)
+	}
+	if q.createSessionStmt, err = db.PrepareContext(ctx, createSession); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateSession: %w", err)
+	}
+	if q.createStandingOrderStmt, err = db.PrepareContext(ctx, createStandingOrder); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateStandingOrder: %w", err)
+	}
+	if q.createStandingOrderTransferStmt, err = db.PrepareContext(ctx, createStandingOrderTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateStandingOrderTransfer: %w", err)
+	}
 	if q.createTransferStmt, err = db.PrepareContext(ctx, createTransfer); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateTransfer: %w", err)
 	}
+	if q.createTransferApprovalStmt, err = db.PrepareContext(ctx, createTransferApproval); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTransferApproval: %w", err)
+	}
+	if q.createTransferBatchStmt, err = db.PrepareContext(ctx, createTransferBatch); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTransferBatch: %w", err)
+	}
+	if q.createTransferBatchLegStmt, err = db.PrepareContext(ctx, createTransferBatchLeg); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTransferBatchLeg: %w", err)
+	}
+	if q.createTransferFeeStmt, err = db.PrepareContext(ctx, createTransferFee); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTransferFee: %w", err)
+	}
+	if q.createTransferHoldStmt, err = db.PrepareContext(ctx, createTransferHold); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTransferHold: %w", err)
+	}
+	if q.createTransferReversalStmt, err = db.PrepareContext(ctx, createTransferReversal); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTransferReversal: %w", err)
+	}
 	if q.createUserStmt, err = db.PrepareContext(ctx, createUser); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateUser: %w", err)
 	}
+	if q.decideTransferApprovalStmt, err = db.PrepareContext(ctx, decideTransferApproval); err != nil {
+		return nil, fmt.Errorf("error preparing query DecideTransferApproval: %w", err)
+	}
 	if q.deleteAccountStmt, err = db.PrepareContext(ctx, deleteAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteAccount: %w", err)
 	}
+	if q.deleteAccountCoOwnerStmt, err = db.PrepareContext(ctx, deleteAccountCoOwner); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteAccountCoOwner: %w", err)
+	}
+	if q.deleteExchangeRateStmt, err = db.PrepareContext(ctx, deleteExchangeRate); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExchangeRate: %w", err)
+	}
+	if q.deleteFeeScheduleStmt, err = db.PrepareContext(ctx, deleteFeeSchedule); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteFeeSchedule: %w", err)
+	}
+	if q.deleteTransferLimitStmt, err = db.PrepareContext(ctx, deleteTransferLimit); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteTransferLimit: %w", err)
+	}
+	if q.expireMoneyRequestsStmt, err = db.PrepareContext(ctx, expireMoneyRequests); err != nil {
+		return nil, fmt.Errorf("error preparing query ExpireMoneyRequests: %w", err)
+	}
+	if q.expirePendingTransfersStmt, err = db.PrepareContext(ctx, expirePendingTransfers); err != nil {
+		return nil, fmt.Errorf("error preparing query ExpirePendingTransfers: %w", err)
+	}
+	if q.expireTransferApprovalsStmt, err = db.PrepareContext(ctx, expireTransferApprovals); err != nil {
+		return nil, fmt.Errorf("error preparing query ExpireTransferApprovals: %w", err)
+	}
 	if q.getAccountStmt, err = db.PrepareContext(ctx, getAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAccount: %w", err)
 	}
+	if q.getAccountCoOwnerStmt, err = db.PrepareContext(ctx, getAccountCoOwner); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAccountCoOwner: %w", err)
+	}
 	if q.getAccountForUpdateStmt, err = db.PrepareContext(ctx, getAccountForUpdate); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAccountForUpdate: %w", err)
 	}
+	if q.getEffectiveTransferLimitStmt, err = db.PrepareContext(ctx, getEffectiveTransferLimit); err != nil {
+		return nil, fmt.Errorf("error preparing query GetEffectiveTransferLimit: %w", err)
+	}
 	if q.getEntryStmt, err = db.PrepareContext(ctx, getEntry); err != nil {
 		return nil, fmt.Errorf("error preparing query GetEntry: %w", err)
 	}
+	if q.getExchangeRateStmt, err = db.PrepareContext(ctx, getExchangeRate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetExchangeRate: %w", err)
+	}
+	if q.getFeeAccountStmt, err = db.PrepareContext(ctx, getFeeAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFeeAccount: %w", err)
+	}
+	if q.getFeeScheduleStmt, err = db.PrepareContext(ctx, getFeeSchedule); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFeeSchedule: %w", err)
+	}
+	if q.getFxAccountStmt, err = db.PrepareContext(ctx, getFxAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFxAccount: %w", err)
+	}
+	if q.getIdempotencyKeyStmt, err = db.PrepareContext(ctx, getIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query GetIdempotencyKey: %w", err)
+	}
+	if q.getJournalStmt, err = db.PrepareContext(ctx, getJournal); err != nil {
+		return nil, fmt.Errorf("error preparing query GetJournal: %w", err)
+	}
+	if q.getMoneyRequestStmt, err = db.PrepareContext(ctx, getMoneyRequest); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMoneyRequest: %w", err)
+	}
+	if q.getMoneyRequestForUpdateStmt, err = db.PrepareContext(ctx, getMoneyRequestForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMoneyRequestForUpdate: %w", err)
+	}
+	if q.getOutgoingTransferStatsSinceStmt, err = db.PrepareContext(ctx, getOutgoingTransferStatsSince); err != nil {
+		return nil, fmt.Errorf("error preparing query GetOutgoingTransferStatsSince: %w", err)
+	}
+	if q.getRecipientAccountStmt, err = db.PrepareContext(ctx, getRecipientAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetRecipientAccount: %w", err)
+	}
+	if q.getRiskDecisionStmt, err = db.PrepareContext(ctx, getRiskDecision); err != nil {
+		return nil, fmt.Errorf("error preparing query GetRiskDecision: %w", err)
+	}
+	if q.getScheduledTransferStmt, err = db.PrepareContext(ctx, getScheduledTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query GetScheduledTransfer: %w", err)
+	}
+	if q.getScheduledTransferForUpdateStmt, err = db.PrepareContext(ctx, getScheduledTransferForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetScheduledTransferForUpdate: %w", err)
+	}
+	if q.getSessionStmt, err = db.PrepareContext(ctx, getSession); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSession: %w", err)
+	}
+	if q.getSettlementAccountStmt, err = db.PrepareContext(ctx, getSettlementAccount); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSettlementAccount: %w", err)
+	}
+	if q.getStandingOrderStmt, err = db.PrepareContext(ctx, getStandingOrder); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStandingOrder: %w", err)
+	}
 	if q.getTransferStmt, err = db.PrepareContext(ctx, getTransfer); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTransfer: %w", err)
 	}
+	if q.getTransferApprovalStmt, err = db.PrepareContext(ctx, getTransferApproval); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransferApproval: %w", err)
+	}
+	if q.getTransferApprovalByIdempotencyKeyStmt, err = db.PrepareContext(ctx, getTransferApprovalByIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransferApprovalByIdempotencyKey: %w", err)
+	}
+	if q.getTransferApprovalForUpdateStmt, err = db.PrepareContext(ctx, getTransferApprovalForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransferApprovalForUpdate: %w", err)
+	}
+	if q.getTransferBatchStmt, err = db.PrepareContext(ctx, getTransferBatch); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransferBatch: %w", err)
+	}
+	if q.getTransferFeeStmt, err = db.PrepareContext(ctx, getTransferFee); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransferFee: %w", err)
+	}
+	if q.getTransferForUpdateStmt, err = db.PrepareContext(ctx, getTransferForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransferForUpdate: %w", err)
+	}
+	if q.getTransferHoldStmt, err = db.PrepareContext(ctx, getTransferHold); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransferHold: %w", err)
+	}
+	if q.getTransferReversalStmt, err = db.PrepareContext(ctx, getTransferReversal); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransferReversal: %w", err)
+	}
 	if q.getUserStmt, err = db.PrepareContext(ctx, getUser); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUser: %w", err)
 	}
+	if q.getUserForUpdateStmt, err = db.PrepareContext(ctx, getUserForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserForUpdate: %w", err)
+	}
+	if q.isTokenRevokedStmt, err = db.PrepareContext(ctx, isTokenRevoked); err != nil {
+		return nil, fmt.Errorf("error preparing query IsTokenRevoked: %w", err)
+	}
+	if q.listAccountCoOwnersStmt, err = db.PrepareContext(ctx, listAccountCoOwners); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAccountCoOwners: %w", err)
+	}
 	if q.listAccountsStmt, err = db.PrepareContext(ctx, listAccounts); err != nil {
 		return nil, fmt.Errorf("error preparing query ListAccounts: %w", err)
 	}
 	if q.listEntriesStmt, err = db.PrepareContext(ctx, listEntries); err != nil {
 		return nil, fmt.Errorf("error preparing query ListEntries: %w", err)
 	}
+	if q.listEntriesBetweenStmt, err = db.PrepareContext(ctx, listEntriesBetween); err != nil {
+		return nil, fmt.Errorf("error preparing query ListEntriesBetween: %w", err)
+	}
+	if q.listExchangeRatesStmt, err = db.PrepareContext(ctx, listExchangeRates); err != nil {
+		return nil, fmt.Errorf("error preparing query ListExchangeRates: %w", err)
+	}
+	if q.listExpiredPendingTransfersForUpdateStmt, err = db.PrepareContext(ctx, listExpiredPendingTransfersForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query ListExpiredPendingTransfersForUpdate: %w", err)
+	}
+	if q.listExpiredTransferApprovalsForUpdateStmt, err = db.PrepareContext(ctx, listExpiredTransferApprovalsForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query ListExpiredTransferApprovalsForUpdate: %w", err)
+	}
+	if q.listFeeSchedulesStmt, err = db.PrepareContext(ctx, listFeeSchedules); err != nil {
+		return nil, fmt.Errorf("error preparing query ListFeeSchedules: %w", err)
+	}
+	if q.listJournalEntriesStmt, err = db.PrepareContext(ctx, listJournalEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListJournalEntries: %w", err)
+	}
+	if q.listMoneyRequestsStmt, err = db.PrepareContext(ctx, listMoneyRequests); err != nil {
+		return nil, fmt.Errorf("error preparing query ListMoneyRequests: %w", err)
+	}
+	if q.listOwnerScheduledTransfersStmt, err = db.PrepareContext(ctx, listOwnerScheduledTransfers); err != nil {
+		return nil, fmt.Errorf("error preparing query ListOwnerScheduledTransfers: %w", err)
+	}
+	if q.listOwnerStandingOrdersStmt, err = db.PrepareContext(ctx, listOwnerStandingOrders); err != nil {
+		return nil, fmt.Errorf("error preparing query ListOwnerStandingOrders: %w", err)
+	}
+	if q.listOwnerTransfersStmt, err = db.PrepareContext(ctx, listOwnerTransfers); err != nil {
+		return nil, fmt.Errorf("error preparing query ListOwnerTransfers: %w", err)
+	}
+	if q.listRiskDecisionsStmt, err = db.PrepareContext(ctx, listRiskDecisions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListRiskDecisions: %w", err)
+	}
+	if q.listScheduledTransferAttemptsStmt, err = db.PrepareContext(ctx, listScheduledTransferAttempts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListScheduledTransferAttempts: %w", err)
+	}
+	if q.listSettlementAccountsStmt, err = db.PrepareContext(ctx, listSettlementAccounts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListSettlementAccounts: %w", err)
+	}
+	if q.listTransferApprovalsStmt, err = db.PrepareContext(ctx, listTransferApprovals); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTransferApprovals: %w", err)
+	}
+	if q.listTransferBatchLegsStmt, err = db.PrepareContext(ctx, listTransferBatchLegs); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTransferBatchLegs: %w", err)
+	}
+	if q.listTransferLimitsStmt, err = db.PrepareContext(ctx, listTransferLimits); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTransferLimits: %w", err)
+	}
 	if q.listTransfersStmt, err = db.PrepareContext(ctx, listTransfers); err != nil {
 		return nil, fmt.Errorf("error preparing query ListTransfers: %w", err)
 	}
+	if q.pauseStandingOrderStmt, err = db.PrepareContext(ctx, pauseStandingOrder); err != nil {
+		return nil, fmt.Errorf("error preparing query PauseStandingOrder: %w", err)
+	}
+	if q.postPendingTransferStmt, err = db.PrepareContext(ctx, postPendingTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query PostPendingTransfer: %w", err)
+	}
+	if q.reconcileAccountsStmt, err = db.PrepareContext(ctx, reconcileAccounts); err != nil {
+		return nil, fmt.Errorf("error preparing query ReconcileAccounts: %w", err)
+	}
+	if q.reconcileEntriesStmt, err = db.PrepareContext(ctx, reconcileEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query ReconcileEntries: %w", err)
+	}
+	if q.reconcileTransfersStmt, err = db.PrepareContext(ctx, reconcileTransfers); err != nil {
+		return nil, fmt.Errorf("error preparing query ReconcileTransfers: %w", err)
+	}
+	if q.resumeStandingOrderStmt, err = db.PrepareContext(ctx, resumeStandingOrder); err != nil {
+		return nil, fmt.Errorf("error preparing query ResumeStandingOrder: %w", err)
+	}
+	if q.revokeUserTokensStmt, err = db.PrepareContext(ctx, revokeUserTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query RevokeUserTokens: %w", err)
+	}
+	if q.sumEntriesSinceStmt, err = db.PrepareContext(ctx, sumEntriesSince); err != nil {
+		return nil, fmt.Errorf("error preparing query SumEntriesSince: %w", err)
+	}
+	if q.sumOutgoingTransfersSinceStmt, err = db.PrepareContext(ctx, sumOutgoingTransfersSince); err != nil {
+		return nil, fmt.Errorf("error preparing query SumOutgoingTransfersSince: %w", err)
+	}
 	if q.updateAccountStmt, err = db.PrepareContext(ctx, updateAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateAccount: %w", err)
 	}
+	if q.updateAccountOverdraftLimitStmt, err = db.PrepareContext(ctx, updateAccountOverdraftLimit); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateAccountOverdraftLimit: %w", err)
+	}
+	if q.updateScheduledTransferResultStmt, err = db.PrepareContext(ctx, updateScheduledTransferResult); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateScheduledTransferResult: %w", err)
+	}
+	if q.updateStandingOrderRunStmt, err = db.PrepareContext(ctx, updateStandingOrderRun); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateStandingOrderRun: %w", err)
+	}
+	if q.updateUserRoleStmt, err = db.PrepareContext(ctx, updateUserRole); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateUserRole: %w", err)
+	}
+	if q.upsertExchangeRateStmt, err = db.PrepareContext(ctx, upsertExchangeRate); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertExchangeRate: %w", err)
+	}
+	if q.upsertFeeScheduleStmt, err = db.PrepareContext(ctx, upsertFeeSchedule); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertFeeSchedule: %w", err)
+	}
+	if q.upsertTransferLimitStmt, err = db.PrepareContext(ctx, upsertTransferLimit); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertTransferLimit: %w", err)
+	}
+	if q.voidPendingTransferStmt, err = db.PrepareContext(ctx, voidPendingTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query VoidPendingTransfer: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.acceptMoneyRequestStmt != nil {
+		if cerr := q.acceptMoneyRequestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing acceptMoneyRequestStmt: %w", cerr)
+		}
+	}
 	if q.addAccountBalanceStmt != nil {
 		if cerr := q.addAccountBalanceStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing addAccountBalanceStmt: %w", cerr)
 		}
 	}
+	if q.addAccountHeldStmt != nil {
+		if cerr := q.addAccountHeldStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addAccountHeldStmt: %w", cerr)
+		}
+	}
+	if q.blockSessionStmt != nil {
+		if cerr := q.blockSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing blockSessionStmt: %w", cerr)
+		}
+	}
+	if q.blockUserSessionsStmt != nil {
+		if cerr := q.blockUserSessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing blockUserSessionsStmt: %w", cerr)
+		}
+	}
+	if q.cancelScheduledTransferStmt != nil {
+		if cerr := q.cancelScheduledTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing cancelScheduledTransferStmt: %w", cerr)
+		}
+	}
+	if q.cancelStandingOrderStmt != nil {
+		if cerr := q.cancelStandingOrderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing cancelStandingOrderStmt: %w", cerr)
+		}
+	}
+	if q.cancelStandingOrderTransfersStmt != nil {
+		if cerr := q.cancelStandingOrderTransfersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing cancelStandingOrderTransfersStmt: %w", cerr)
+		}
+	}
+	if q.claimDueScheduledTransfersStmt != nil {
+		if cerr := q.claimDueScheduledTransfersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimDueScheduledTransfersStmt: %w", cerr)
+		}
+	}
+	if q.claimDueStandingOrdersStmt != nil {
+		if cerr := q.claimDueStandingOrdersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimDueStandingOrdersStmt: %w", cerr)
+		}
+	}
+	if q.closeMoneyRequestStmt != nil {
+		if cerr := q.closeMoneyRequestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing closeMoneyRequestStmt: %w", cerr)
+		}
+	}
+	if q.countTransfersToAccountStmt != nil {
+		if cerr := q.countTransfersToAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countTransfersToAccountStmt: %w", cerr)
+		}
+	}
 	if q.createAccountStmt != nil {
 		if cerr := q.createAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createAccountStmt: %w", cerr)
 		}
 	}
+	if q.createAccountCoOwnerStmt != nil {
+		if cerr := q.createAccountCoOwnerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAccountCoOwnerStmt: %w", cerr)
+		}
+	}
 	if q.createEntryStmt != nil {
 		if cerr := q.createEntryStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createEntryStmt: %w", cerr)
 		}
 	}
+	if q.createIdempotencyKeyStmt != nil {
+		if cerr := q.createIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.createJournalStmt != nil {
+		if cerr := q.createJournalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createJournalStmt: %w", cerr)
+		}
+	}
+	if q.createMoneyRequestStmt != nil {
+		if cerr := q.createMoneyRequestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createMoneyRequestStmt: %w", cerr)
+		}
+	}
+	if q.createPendingTransferStmt != nil {
+		if cerr := q.createPendingTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createPendingTransferStmt: %w", cerr)
+		}
+	}
+	if q.createRevokedTokenStmt != nil {
+		if cerr := q.createRevokedTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createRevokedTokenStmt: %w", cerr)
+		}
+	}
+	if q.createRiskDecisionStmt != nil {
+		if cerr := q.createRiskDecisionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createRiskDecisionStmt: %w", cerr)
+		}
+	}
+	if q.createScheduledTransferStmt != nil {
+		if cerr := q.createScheduledTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createScheduledTransferStmt: %w", cerr)
+		}
+	}
+	if q.createScheduledTransferAttemptStmt != nil {
+		if cerr := q.createScheduledTransferAttemptStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createScheduledTransferAttemptStmt: %w", cerr)
+		}
+	}
+	if q.createSessionStmt != nil {
+		if cerr := q.createSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createSessionStmt: %w", cerr)
+		}
+	}
+	if q.createStandingOrderStmt != nil {
+		if cerr := q.createStandingOrderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createStandingOrderStmt: %w", cerr)
+		}
+	}
+	if q.createStandingOrderTransferStmt != nil {
+		if cerr := q.createStandingOrderTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createStandingOrderTransferStmt: %w", cerr)
+		}
+	}
 	if q.createTransferStmt != nil {
 		if cerr := q.createTransferStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createTransferStmt: %w", cerr)
 		}
 	}
+	if q.createTransferApprovalStmt != nil {
+		if cerr := q.createTransferApprovalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTransferApprovalStmt: %w", cerr)
+		}
+	}
+	if q.createTransferBatchStmt != nil {
+		if cerr := q.createTransferBatchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTransferBatchStmt: %w", cerr)
+		}
+	}
+	if q.createTransferBatchLegStmt != nil {
+		if cerr := q.createTransferBatchLegStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTransferBatchLegStmt: %w", cerr)
+		}
+	}
+	if q.createTransferFeeStmt != nil {
+		if cerr := q.createTransferFeeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTransferFeeStmt: %w", cerr)
+		}
+	}
+	if q.createTransferHoldStmt != nil {
+		if cerr := q.createTransferHoldStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTransferHoldStmt: %w", cerr)
+		}
+	}
+	if q.createTransferReversalStmt != nil {
+		if cerr := q.createTransferReversalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTransferReversalStmt: %w", cerr)
+		}
+	}
 	if q.createUserStmt != nil {
 		if cerr := q.createUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createUserStmt: %w", cerr)
 		}
 	}
+	if q.decideTransferApprovalStmt != nil {
+		if cerr := q.decideTransferApprovalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing decideTransferApprovalStmt: %w", cerr)
+		}
+	}
 	if q.deleteAccountStmt != nil {
 		if cerr := q.deleteAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteAccountStmt: %w", cerr)
 		}
 	}
+	if q.deleteAccountCoOwnerStmt != nil {
+		if cerr := q.deleteAccountCoOwnerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteAccountCoOwnerStmt: %w", cerr)
+		}
+	}
+	if q.deleteExchangeRateStmt != nil {
+		if cerr := q.deleteExchangeRateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExchangeRateStmt: %w", cerr)
+		}
+	}
+	if q.deleteFeeScheduleStmt != nil {
+		if cerr := q.deleteFeeScheduleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteFeeScheduleStmt: %w", cerr)
+		}
+	}
+	if q.deleteTransferLimitStmt != nil {
+		if cerr := q.deleteTransferLimitStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteTransferLimitStmt: %w", cerr)
+		}
+	}
+	if q.expireMoneyRequestsStmt != nil {
+		if cerr := q.expireMoneyRequestsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing expireMoneyRequestsStmt: %w", cerr)
+		}
+	}
+	if q.expirePendingTransfersStmt != nil {
+		if cerr := q.expirePendingTransfersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing expirePendingTransfersStmt: %w", cerr)
+		}
+	}
+	if q.expireTransferApprovalsStmt != nil {
+		if cerr := q.expireTransferApprovalsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing expireTransferApprovalsStmt: %w", cerr)
+		}
+	}
 	if q.getAccountStmt != nil {
 		if cerr := q.getAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAccountStmt: %w", cerr)
 		}
 	}
+	if q.getAccountCoOwnerStmt != nil {
+		if cerr := q.getAccountCoOwnerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAccountCoOwnerStmt: %w", cerr)
+		}
+	}
 	if q.getAccountForUpdateStmt != nil {
 		if cerr := q.getAccountForUpdateStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAccountForUpdateStmt: %w", cerr)
 		}
 	}
+	if q.getEffectiveTransferLimitStmt != nil {
+		if cerr := q.getEffectiveTransferLimitStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getEffectiveTransferLimitStmt: %w", cerr)
+		}
+	}
 	if q.getEntryStmt != nil {
 		if cerr := q.getEntryStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getEntryStmt: %w", cerr)
 		}
 	}
+	if q.getExchangeRateStmt != nil {
+		if cerr := q.getExchangeRateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getExchangeRateStmt: %w", cerr)
+		}
+	}
+	if q.getFeeAccountStmt != nil {
+		if cerr := q.getFeeAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFeeAccountStmt: %w", cerr)
+		}
+	}
+	if q.getFeeScheduleStmt != nil {
+		if cerr := q.getFeeScheduleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFeeScheduleStmt: %w", cerr)
+		}
+	}
+	if q.getFxAccountStmt != nil {
+		if cerr := q.getFxAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFxAccountStmt: %w", cerr)
+		}
+	}
+	if q.getIdempotencyKeyStmt != nil {
+		if cerr := q.getIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.getJournalStmt != nil {
+		if cerr := q.getJournalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getJournalStmt: %w", cerr)
+		}
+	}
+	if q.getMoneyRequestStmt != nil {
+		if cerr := q.getMoneyRequestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMoneyRequestStmt: %w", cerr)
+		}
+	}
+	if q.getMoneyRequestForUpdateStmt != nil {
+		if cerr := q.getMoneyRequestForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMoneyRequestForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getOutgoingTransferStatsSinceStmt != nil {
+		if cerr := q.getOutgoingTransferStatsSinceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getOutgoingTransferStatsSinceStmt: %w", cerr)
+		}
+	}
+	if q.getRecipientAccountStmt != nil {
+		if cerr := q.getRecipientAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getRecipientAccountStmt: %w", cerr)
+		}
+	}
+	if q.getRiskDecisionStmt != nil {
+		if cerr := q.getRiskDecisionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getRiskDecisionStmt: %w", cerr)
+		}
+	}
+	if q.getScheduledTransferStmt != nil {
+		if cerr := q.getScheduledTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getScheduledTransferStmt: %w", cerr)
+		}
+	}
+	if q.getScheduledTransferForUpdateStmt != nil {
+		if cerr := q.getScheduledTransferForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getScheduledTransferForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getSessionStmt != nil {
+		if cerr := q.getSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSessionStmt: %w", cerr)
+		}
+	}
+	if q.getSettlementAccountStmt != nil {
+		if cerr := q.getSettlementAccountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSettlementAccountStmt: %w", cerr)
+		}
+	}
+	if q.getStandingOrderStmt != nil {
+		if cerr := q.getStandingOrderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStandingOrderStmt: %w", cerr)
+		}
+	}
 	if q.getTransferStmt != nil {
 		if cerr := q.getTransferStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getTransferStmt: %w", cerr)
 		}
 	}
+	if q.getTransferApprovalStmt != nil {
+		if cerr := q.getTransferApprovalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransferApprovalStmt: %w", cerr)
+		}
+	}
+	if q.getTransferApprovalByIdempotencyKeyStmt != nil {
+		if cerr := q.getTransferApprovalByIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransferApprovalByIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.getTransferApprovalForUpdateStmt != nil {
+		if cerr := q.getTransferApprovalForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransferApprovalForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getTransferBatchStmt != nil {
+		if cerr := q.getTransferBatchStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransferBatchStmt: %w", cerr)
+		}
+	}
+	if q.getTransferFeeStmt != nil {
+		if cerr := q.getTransferFeeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransferFeeStmt: %w", cerr)
+		}
+	}
+	if q.getTransferForUpdateStmt != nil {
+		if cerr := q.getTransferForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransferForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getTransferHoldStmt != nil {
+		if cerr := q.getTransferHoldStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransferHoldStmt: %w", cerr)
+		}
+	}
+	if q.getTransferReversalStmt != nil {
+		if cerr := q.getTransferReversalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransferReversalStmt: %w", cerr)
+		}
+	}
 	if q.getUserStmt != nil {
 		if cerr := q.getUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getUserStmt: %w", cerr)
 		}
 	}
+	if q.getUserForUpdateStmt != nil {
+		if cerr := q.getUserForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.isTokenRevokedStmt != nil {
+		if cerr := q.isTokenRevokedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing isTokenRevokedStmt: %w", cerr)
+		}
+	}
+	if q.listAccountCoOwnersStmt != nil {
+		if cerr := q.listAccountCoOwnersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAccountCoOwnersStmt: %w", cerr)
+		}
+	}
 	if q.listAccountsStmt != nil {
 		if cerr := q.listAccountsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listAccountsStmt: %w", cerr)
@@ -137,16 +764,181 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listEntriesStmt: %w", cerr)
 		}
 	}
+	if q.listEntriesBetweenStmt != nil {
+		if cerr := q.listEntriesBetweenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listEntriesBetweenStmt: %w", cerr)
+		}
+	}
+	if q.listExchangeRatesStmt != nil {
+		if cerr := q.listExchangeRatesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listExchangeRatesStmt: %w", cerr)
+		}
+	}
+	if q.listExpiredPendingTransfersForUpdateStmt != nil {
+		if cerr := q.listExpiredPendingTransfersForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listExpiredPendingTransfersForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.listExpiredTransferApprovalsForUpdateStmt != nil {
+		if cerr := q.listExpiredTransferApprovalsForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listExpiredTransferApprovalsForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.listFeeSchedulesStmt != nil {
+		if cerr := q.listFeeSchedulesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listFeeSchedulesStmt: %w", cerr)
+		}
+	}
+	if q.listJournalEntriesStmt != nil {
+		if cerr := q.listJournalEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listJournalEntriesStmt: %w", cerr)
+		}
+	}
+	if q.listMoneyRequestsStmt != nil {
+		if cerr := q.listMoneyRequestsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listMoneyRequestsStmt: %w", cerr)
+		}
+	}
+	if q.listOwnerScheduledTransfersStmt != nil {
+		if cerr := q.listOwnerScheduledTransfersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listOwnerScheduledTransfersStmt: %w", cerr)
+		}
+	}
+	if q.listOwnerStandingOrdersStmt != nil {
+		if cerr := q.listOwnerStandingOrdersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listOwnerStandingOrdersStmt: %w", cerr)
+		}
+	}
+	if q.listOwnerTransfersStmt != nil {
+		if cerr := q.listOwnerTransfersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listOwnerTransfersStmt: %w", cerr)
+		}
+	}
+	if q.listRiskDecisionsStmt != nil {
+		if cerr := q.listRiskDecisionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listRiskDecisionsStmt: %w", cerr)
+		}
+	}
+	if q.listScheduledTransferAttemptsStmt != nil {
+		if cerr := q.listScheduledTransferAttemptsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listScheduledTransferAttemptsStmt: %w", cerr)
+		}
+	}
+	if q.listSettlementAccountsStmt != nil {
+		if cerr := q.listSettlementAccountsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listSettlementAccountsStmt: %w", cerr)
+		}
+	}
+	if q.listTransferApprovalsStmt != nil {
+		if cerr := q.listTransferApprovalsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTransferApprovalsStmt: %w", cerr)
+		}
+	}
+	if q.listTransferBatchLegsStmt != nil {
+		if cerr := q.listTransferBatchLegsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTransferBatchLegsStmt: %w", cerr)
+		}
+	}
+	if q.listTransferLimitsStmt != nil {
+		if cerr := q.listTransferLimitsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTransferLimitsStmt: %w", cerr)
+		}
+	}
 	if q.listTransfersStmt != nil {
 		if cerr := q.listTransfersStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listTransfersStmt: %w", cerr)
 		}
 	}
+	if q.pauseStandingOrderStmt != nil {
+		if cerr := q.pauseStandingOrderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing pauseStandingOrderStmt: %w", cerr)
+		}
+	}
+	if q.postPendingTransferStmt != nil {
+		if cerr := q.postPendingTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing postPendingTransferStmt: %w", cerr)
+		}
+	}
+	if q.reconcileAccountsStmt != nil {
+		if cerr := q.reconcileAccountsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing reconcileAccountsStmt: %w", cerr)
+		}
+	}
+	if q.reconcileEntriesStmt != nil {
+		if cerr := q.reconcileEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing reconcileEntriesStmt: %w", cerr)
+		}
+	}
+	if q.reconcileTransfersStmt != nil {
+		if cerr := q.reconcileTransfersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing reconcileTransfersStmt: %w", cerr)
+		}
+	}
+	if q.resumeStandingOrderStmt != nil {
+		if cerr := q.resumeStandingOrderStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing resumeStandingOrderStmt: %w", cerr)
+		}
+	}
+	if q.revokeUserTokensStmt != nil {
+		if cerr := q.revokeUserTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing revokeUserTokensStmt: %w", cerr)
+		}
+	}
+	if q.sumEntriesSinceStmt != nil {
+		if cerr := q.sumEntriesSinceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing sumEntriesSinceStmt: %w", cerr)
+		}
+	}
+	if q.sumOutgoingTransfersSinceStmt != nil {
+		if cerr := q.sumOutgoingTransfersSinceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing sumOutgoingTransfersSinceStmt: %w", cerr)
+		}
+	}
 	if q.updateAccountStmt != nil {
 		if cerr := q.updateAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateAccountStmt: %w", cerr)
 		}
 	}
+	if q.updateAccountOverdraftLimitStmt != nil {
+		if cerr := q.updateAccountOverdraftLimitStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateAccountOverdraftLimitStmt: %w", cerr)
+		}
+	}
+	if q.updateScheduledTransferResultStmt != nil {
+		if cerr := q.updateScheduledTransferResultStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateScheduledTransferResultStmt: %w", cerr)
+		}
+	}
+	if q.updateStandingOrderRunStmt != nil {
+		if cerr := q.updateStandingOrderRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateStandingOrderRunStmt: %w", cerr)
+		}
+	}
+	if q.updateUserRoleStmt != nil {
+		if cerr := q.updateUserRoleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateUserRoleStmt: %w", cerr)
+		}
+	}
+	if q.upsertExchangeRateStmt != nil {
+		if cerr := q.upsertExchangeRateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertExchangeRateStmt: %w", cerr)
+		}
+	}
+	if q.upsertFeeScheduleStmt != nil {
+		if cerr := q.upsertFeeScheduleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertFeeScheduleStmt: %w", cerr)
+		}
+	}
+	if q.upsertTransferLimitStmt != nil {
+		if cerr := q.upsertTransferLimitStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertTransferLimitStmt: %w", cerr)
+		}
+	}
+	if q.voidPendingTransferStmt != nil {
+		if cerr := q.voidPendingTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing voidPendingTransferStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -184,43 +976,241 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                      DBTX
-	tx                      *sql.Tx
-	addAccountBalanceStmt   *sql.Stmt
-	createAccountStmt       *sql.Stmt
-	createEntryStmt         *sql.Stmt
-	createTransferStmt      *sql.Stmt
-	createUserStmt          *sql.Stmt
-	deleteAccountStmt       *sql.Stmt
-	getAccountStmt          *sql.Stmt
-	getAccountForUpdateStmt *sql.Stmt
-	getEntryStmt            *sql.Stmt
-	getTransferStmt         *sql.Stmt
-	getUserStmt             *sql.Stmt
-	listAccountsStmt        *sql.Stmt
-	listEntriesStmt         *sql.Stmt
-	listTransfersStmt       *sql.Stmt
-	updateAccountStmt       *sql.Stmt
+	db                                        DBTX
+	tx                                        *sql.Tx
+	acceptMoneyRequestStmt                    *sql.Stmt
+	addAccountBalanceStmt                     *sql.Stmt
+	addAccountHeldStmt                        *sql.Stmt
+	blockSessionStmt                          *sql.Stmt
+	blockUserSessionsStmt                     *sql.Stmt
+	cancelScheduledTransferStmt               *sql.Stmt
+	cancelStandingOrderStmt                   *sql.Stmt
+	cancelStandingOrderTransfersStmt          *sql.Stmt
+	claimDueScheduledTransfersStmt            *sql.Stmt
+	claimDueStandingOrdersStmt                *sql.Stmt
+	closeMoneyRequestStmt                     *sql.Stmt
+	countTransfersToAccountStmt               *sql.Stmt
+	createAccountStmt                         *sql.Stmt
+	createAccountCoOwnerStmt                  *sql.Stmt
+	createEntryStmt                           *sql.Stmt
+	createIdempotencyKeyStmt                  *sql.Stmt
+	createJournalStmt                         *sql.Stmt
+	createMoneyRequestStmt                    *sql.Stmt
+	createPendingTransferStmt                 *sql.Stmt
+	createRevokedTokenStmt                    *sql.Stmt
+	createRiskDecisionStmt                    *sql.Stmt
+	createScheduledTransferStmt               *sql.Stmt
+	createScheduledTransferAttemptStmt        *sql.Stmt
+	createSessionStmt                         *sql.Stmt
+	createStandingOrderStmt                   *sql.Stmt
+	createStandingOrderTransferStmt           *sql.Stmt
+	createTransferStmt                        *sql.Stmt
+	createTransferApprovalStmt                *sql.Stmt
+	createTransferBatchStmt                   *sql.Stmt
+	createTransferBatchLegStmt                *sql.Stmt
+	createTransferFeeStmt                     *sql.Stmt
+	createTransferHoldStmt                    *sql.Stmt
+	createTransferReversalStmt                *sql.Stmt
+	createUserStmt                            *sql.Stmt
+	decideTransferApprovalStmt                *sql.Stmt
+	deleteAccountStmt                         *sql.Stmt
+	deleteAccountCoOwnerStmt                  *sql.Stmt
+	deleteExchangeRateStmt                    *sql.Stmt
+	deleteFeeScheduleStmt                     *sql.Stmt
+	deleteTransferLimitStmt                   *sql.Stmt
+	expireMoneyRequestsStmt                   *sql.Stmt
+	expirePendingTransfersStmt                *sql.Stmt
+	expireTransferApprovalsStmt               *sql.Stmt
+	getAccountStmt                            *sql.Stmt
+	getAccountCoOwnerStmt                     *sql.Stmt
+	getAccountForUpdateStmt                   *sql.Stmt
+	getEffectiveTransferLimitStmt             *sql.Stmt
+	getEntryStmt                              *sql.Stmt
+	getExchangeRateStmt                       *sql.Stmt
+	getFeeAccountStmt                         *sql.Stmt
+	getFeeScheduleStmt                        *sql.Stmt
+	getFxAccountStmt                          *sql.Stmt
+	getIdempotencyKeyStmt                     *sql.Stmt
+	getJournalStmt                            *sql.Stmt
+	getMoneyRequestStmt                       *sql.Stmt
+	getMoneyRequestForUpdateStmt              *sql.Stmt
+	getOutgoingTransferStatsSinceStmt         *sql.Stmt
+	getRecipientAccountStmt                   *sql.Stmt
+	getRiskDecisionStmt                       *sql.Stmt
+	getScheduledTransferStmt                  *sql.Stmt
+	getScheduledTransferForUpdateStmt         *sql.Stmt
+	getSessionStmt                            *sql.Stmt
+	getSettlementAccountStmt                  *sql.Stmt
+	getStandingOrderStmt                      *sql.Stmt
+	getTransferStmt                           *sql.Stmt
+	getTransferApprovalStmt                   *sql.Stmt
+	getTransferApprovalByIdempotencyKeyStmt   *sql.Stmt
+	getTransferApprovalForUpdateStmt          *sql.Stmt
+	getTransferBatchStmt                      *sql.Stmt
+	getTransferFeeStmt                        *sql.Stmt
+	getTransferForUpdateStmt                  *sql.Stmt
+	getTransferHoldStmt                       *sql.Stmt
+	getTransferReversalStmt                   *sql.Stmt
+	getUserStmt                               *sql.Stmt
+	getUserForUpdateStmt                      *sql.Stmt
+	isTokenRevokedStmt                        *sql.Stmt
+	listAccountCoOwnersStmt                   *sql.Stmt
+	listAccountsStmt                          *sql.Stmt
+	listEntriesStmt                           *sql.Stmt
+	listEntriesBetweenStmt                    *sql.Stmt
+	listExchangeRatesStmt                     *sql.Stmt
+	listExpiredPendingTransfersForUpdateStmt  *sql.Stmt
+	listExpiredTransferApprovalsForUpdateStmt *sql.Stmt
+	listFeeSchedulesStmt                      *sql.Stmt
+	listJournalEntriesStmt                    *sql.Stmt
+	listMoneyRequestsStmt                     *sql.Stmt
+	listOwnerScheduledTransfersStmt           *sql.Stmt
+	listOwnerStandingOrdersStmt               *sql.Stmt
+	listOwnerTransfersStmt                    *sql.Stmt
+	listRiskDecisionsStmt                     *sql.Stmt
+	listScheduledTransferAttemptsStmt         *sql.Stmt
+	listSettlementAccountsStmt                *sql.Stmt
+	listTransferApprovalsStmt                 *sql.Stmt
+	listTransferBatchLegsStmt                 *sql.Stmt
+	listTransferLimitsStmt                    *sql.Stmt
+	listTransfersStmt                         *sql.Stmt
+	pauseStandingOrderStmt                    *sql.Stmt
+	postPendingTransferStmt                   *sql.Stmt
+	reconcileAccountsStmt                     *sql.Stmt
+	reconcileEntriesStmt                      *sql.Stmt
+	reconcileTransfersStmt                    *sql.Stmt
+	resumeStandingOrderStmt                   *sql.Stmt
+	revokeUserTokensStmt                      *sql.Stmt
+	sumEntriesSinceStmt                       *sql.Stmt
+	sumOutgoingTransfersSinceStmt             *sql.Stmt
+	updateAccountStmt                         *sql.Stmt
+	updateAccountOverdraftLimitStmt           *sql.Stmt
+	updateScheduledTransferResultStmt         *sql.Stmt
+	updateStandingOrderRunStmt                *sql.Stmt
+	updateUserRoleStmt                        *sql.Stmt
+	upsertExchangeRateStmt                    *sql.Stmt
+	upsertFeeScheduleStmt                     *sql.Stmt
+	upsertTransferLimitStmt                   *sql.Stmt
+	voidPendingTransferStmt                   *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                      tx,
-		tx:                      tx,
-		addAccountBalanceStmt:   q.addAccountBalanceStmt,
-		createAccountStmt:       q.createAccountStmt,
-		createEntryStmt:         q.createEntryStmt,
-		createTransferStmt:      q.createTransferStmt,
-		createUserStmt:          q.createUserStmt,
-		deleteAccountStmt:       q.deleteAccountStmt,
-		getAccountStmt:          q.getAccountStmt,
-		getAccountForUpdateStmt: q.getAccountForUpdateStmt,
-		getEntryStmt:            q.getEntryStmt,
-		getTransferStmt:         q.getTransferStmt,
-		getUserStmt:             q.getUserStmt,
-		listAccountsStmt:        q.listAccountsStmt,
-		listEntriesStmt:         q.listEntriesStmt,
-		listTransfersStmt:       q.listTransfersStmt,
-		updateAccountStmt:       q.updateAccountStmt,
+		db:                                        tx,
+		tx:                                        tx,
+		acceptMoneyRequestStmt:                    q.acceptMoneyRequestStmt,
+		addAccountBalanceStmt:                     q.addAccountBalanceStmt,
+		addAccountHeldStmt:                        q.addAccountHeldStmt,
+		blockSessionStmt:                          q.blockSessionStmt,
+		blockUserSessionsStmt:                     q.blockUserSessionsStmt,
+		cancelScheduledTransferStmt:               q.cancelScheduledTransferStmt,
+		cancelStandingOrderStmt:                   q.cancelStandingOrderStmt,
+		cancelStandingOrderTransfersStmt:          q.cancelStandingOrderTransfersStmt,
+		claimDueScheduledTransfersStmt:            q.claimDueScheduledTransfersStmt,
+		claimDueStandingOrdersStmt:                q.claimDueStandingOrdersStmt,
+		closeMoneyRequestStmt:                     q.closeMoneyRequestStmt,
+		countTransfersToAccountStmt:               q.countTransfersToAccountStmt,
+		createAccountStmt:                         q.createAccountStmt,
+		createAccountCoOwnerStmt:                  q.createAccountCoOwnerStmt,
+		createEntryStmt:                           q.createEntryStmt,
+		createIdempotencyKeyStmt:                  q.createIdempotencyKeyStmt,
+		createJournalStmt:                         q.createJournalStmt,
+		createMoneyRequestStmt:                    q.createMoneyRequestStmt,
+		createPendingTransferStmt:                 q.createPendingTransferStmt,
+		createRevokedTokenStmt:                    q.createRevokedTokenStmt,
+		createRiskDecisionStmt:                    q.createRiskDecisionStmt,
+		createScheduledTransferStmt:               q.createScheduledTransferStmt,
+		createScheduledTransferAttemptStmt:        q.createScheduledTransferAttemptStmt,
+		createSessionStmt:                         q.createSessionStmt,
+		createStandingOrderStmt:                   q.createStandingOrderStmt,
+		createStandingOrderTransferStmt:           q.createStandingOrderTransferStmt,
+		createTransferStmt:                        q.createTransferStmt,
+		createTransferApprovalStmt:                q.createTransferApprovalStmt,
+		createTransferBatchStmt:                   q.createTransferBatchStmt,
+		createTransferBatchLegStmt:                q.createTransferBatchLegStmt,
+		createTransferFeeStmt:                     q.createTransferFeeStmt,
+		createTransferHoldStmt:                    q.createTransferHoldStmt,
+		createTransferReversalStmt:                q.createTransferReversalStmt,
+		createUserStmt:                            q.createUserStmt,
+		decideTransferApprovalStmt:                q.decideTransferApprovalStmt,
+		deleteAccountStmt:                         q.deleteAccountStmt,
+		deleteAccountCoOwnerStmt:                  q.deleteAccountCoOwnerStmt,
+		deleteExchangeRateStmt:                    q.deleteExchangeRateStmt,
+		deleteFeeScheduleStmt:                     q.deleteFeeScheduleStmt,
+		deleteTransferLimitStmt:                   q.deleteTransferLimitStmt,
+		expireMoneyRequestsStmt:                   q.expireMoneyRequestsStmt,
+		expirePendingTransfersStmt:                q.expirePendingTransfersStmt,
+		expireTransferApprovalsStmt:               q.expireTransferApprovalsStmt,
+		getAccountStmt:                            q.getAccountStmt,
+		getAccountCoOwnerStmt:                     q.getAccountCoOwnerStmt,
+		getAccountForUpdateStmt:                   q.getAccountForUpdateStmt,
+		getEffectiveTransferLimitStmt:             q.getEffectiveTransferLimitStmt,
+		getEntryStmt:                              q.getEntryStmt,
+		getExchangeRateStmt:                       q.getExchangeRateStmt,
+		getFeeAccountStmt:                         q.getFeeAccountStmt,
+		getFeeScheduleStmt:                        q.getFeeScheduleStmt,
+		getFxAccountStmt:                          q.getFxAccountStmt,
+		getIdempotencyKeyStmt:                     q.getIdempotencyKeyStmt,
+		getJournalStmt:                            q.getJournalStmt,
+		getMoneyRequestStmt:                       q.getMoneyRequestStmt,
+		getMoneyRequestForUpdateStmt:              q.getMoneyRequestForUpdateStmt,
+		getOutgoingTransferStatsSinceStmt:         q.getOutgoingTransferStatsSinceStmt,
+		getRecipientAccountStmt:                   q.getRecipientAccountStmt,
+		getRiskDecisionStmt:                       q.getRiskDecisionStmt,
+		getScheduledTransferStmt:                  q.getScheduledTransferStmt,
+		getScheduledTransferForUpdateStmt:         q.getScheduledTransferForUpdateStmt,
+		getSessionStmt:                            q.getSessionStmt,
+		getSettlementAccountStmt:                  q.getSettlementAccountStmt,
+		getStandingOrderStmt:                      q.getStandingOrderStmt,
+		getTransferStmt:                           q.getTransferStmt,
+		getTransferApprovalStmt:                   q.getTransferApprovalStmt,
+		getTransferApprovalByIdempotencyKeyStmt:   q.getTransferApprovalByIdempotencyKeyStmt,
+		getTransferApprovalForUpdateStmt:          q.getTransferApprovalForUpdateStmt,
+		getTransferBatchStmt:                      q.getTransferBatchStmt,
+		getTransferFeeStmt:                        q.getTransferFeeStmt,
+		getTransferForUpdateStmt:                  q.getTransferForUpdateStmt,
+		getTransferHoldStmt:                       q.getTransferHoldStmt,
+		getTransferReversalStmt:                   q.getTransferReversalStmt,
+		getUserStmt:                               q.getUserStmt,
+		getUserForUpdateStmt:                      q.getUserForUpdateStmt,
+		isTokenRevokedStmt:                        q.isTokenRevokedStmt,
+		listAccountCoOwnersStmt:                   q.listAccountCoOwnersStmt,
+		listAccountsStmt:                          q.listAccountsStmt,
+		listEntriesStmt:                           q.listEntriesStmt,
+		listEntriesBetweenStmt:                    q.listEntriesBetweenStmt,
+		listExchangeRatesStmt:                     q.listExchangeRatesStmt,
+		listExpiredPendingTransfersForUpdateStmt:  q.listExpiredPendingTransfersForUpdateStmt,
+		listExpiredTransferApprovalsForUpdateStmt: q.listExpiredTransferApprovalsForUpdateStmt,
+		listFeeSchedulesStmt:                      q.listFeeSchedulesStmt,
+		listJournalEntriesStmt:                    q.listJournalEntriesStmt,
+		listMoneyRequestsStmt:                     q.listMoneyRequestsStmt,
+		listOwnerScheduledTransfersStmt:           q.listOwnerScheduledTransfersStmt,
+		listOwnerStandingOrdersStmt:               q.listOwnerStandingOrdersStmt,
+		listOwnerTransfersStmt:                    q.listOwnerTransfersStmt,
+		listRiskDecisionsStmt:                     q.listRiskDecisionsStmt,
+		listScheduledTransferAttemptsStmt:         q.listScheduledTransferAttemptsStmt,
+		listSettlementAccountsStmt:                q.listSettlementAccountsStmt,
+		listTransferApprovalsStmt:                 q.listTransferApprovalsStmt,
+		listTransferBatchLegsStmt:                 q.listTransferBatchLegsStmt,
+		listTransferLimitsStmt:                    q.listTransferLimitsStmt,
+		listTransfersStmt:                         q.listTransfersStmt,
+		pauseStandingOrderStmt:                    q.pauseStandingOrderStmt,
+		postPendingTransferStmt:                   q.postPendingTransferStmt,
+		reconcileAccountsStmt:                     q.reconcileAccountsStmt,
+		reconcileEntriesStmt:                      q.reconcileEntriesStmt,
+		reconcileTransfersStmt:                    q.reconcileTransfersStmt,
+		resumeStandingOrderStmt:                   q.resumeStandingOrderStmt,
+		revokeUserTokensStmt:                      q.revokeUserTokensStmt,
+		sumEntriesSinceStmt:                       q.sumEntriesSinceStmt,
+		sumOutgoingTransfersSinceStmt:             q.sumOutgoingTransfersSinceStmt,
+		updateAccountStmt:                         q.updateAccountStmt,
+		updateAccountOverdraftLimitStmt:           q.updateAccountOverdraftLimitStmt,
+		updateScheduledTransferResultStmt:         q.updateScheduledTransferResultStmt,
+		updateStandingOrderRunStmt:                q.updateStandingOrderRunStmt,
+		updateUserRoleStmt:                        q.updateUserRoleStmt,
+		upsertExchangeRateStmt:                    q.upsertExchangeRateStmt,
+		upsertFeeScheduleStmt:                     q.upsertFeeScheduleStmt,
+		upsertTransferLimitStmt:                   q.upsertTransferLimitStmt,
+		voidPendingTransferStmt:                   q.voidPendingTransferStmt,
 	}
 }
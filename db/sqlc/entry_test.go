@@ -71,3 +71,40 @@ func TestQueries_ListEntries(t *testing.T) {
 		require.Equal(t, arg.AccountID, entry.AccountID)
 	}
 }
+
+func TestQueries_ListEntriesBetween(t *testing.T) {
+	account := createRandomAccount(t)
+
+	first := createRandomEntry(t, account)
+	second := createRandomEntry(t, account)
+
+	entries, err := testQueries.ListEntriesBetween(context.Background(), ListEntriesBetweenParams{
+		AccountID:   int64(account.ID),
+		CreatedFrom: first.CreatedAt,
+		CreatedTo:   second.CreatedAt,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, first.ID, entries[0].ID)
+}
+
+func TestQueries_SumEntriesSince(t *testing.T) {
+	account := createRandomAccount(t)
+
+	first := createRandomEntry(t, account)
+	second := createRandomEntry(t, account)
+
+	total, err := testQueries.SumEntriesSince(context.Background(), SumEntriesSinceParams{
+		AccountID:   int64(account.ID),
+		CreatedFrom: first.CreatedAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, first.Amount+second.Amount, total)
+
+	total, err = testQueries.SumEntriesSince(context.Background(), SumEntriesSinceParams{
+		AccountID:   int64(account.ID),
+		CreatedFrom: second.CreatedAt.Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
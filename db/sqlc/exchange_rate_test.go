@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func createExchangeRate(t *testing.T, fromCurrency, toCurrency, rate string) ExchangeRate {
+	arg := UpsertExchangeRateParams{
+		FromCurrency: fromCurrency,
+		ToCurrency:   toCurrency,
+		Rate:         rate,
+	}
+
+	exchangeRate, err := testQueries.UpsertExchangeRate(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.FromCurrency, exchangeRate.FromCurrency)
+	require.Equal(t, arg.ToCurrency, exchangeRate.ToCurrency)
+	require.Equal(t, arg.Rate, exchangeRate.Rate)
+	require.NotZero(t, exchangeRate.UpdatedAt)
+
+	return exchangeRate
+}
+
+func TestQueries_UpsertExchangeRate(t *testing.T) {
+	first := createExchangeRate(t, util.USD, util.CAD, "1.25")
+	second := createExchangeRate(t, util.USD, util.CAD, "1.3")
+
+	require.True(t, second.UpdatedAt.After(first.UpdatedAt))
+}
+
+func TestQueries_GetExchangeRate(t *testing.T) {
+	expected := createExchangeRate(t, util.CAD, util.USD, "0.8")
+
+	actual, err := testQueries.GetExchangeRate(context.Background(), GetExchangeRateParams{
+		FromCurrency: expected.FromCurrency,
+		ToCurrency:   expected.ToCurrency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, expected.Rate, actual.Rate)
+}
+
+func TestQueries_ListExchangeRates(t *testing.T) {
+	createExchangeRate(t, util.EUR, util.CAD, "1.45")
+
+	rates, err := testQueries.ListExchangeRates(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, rates)
+}
+
+func TestQueries_DeleteExchangeRate(t *testing.T) {
+	rate := createExchangeRate(t, util.CAD, util.EUR, "0.69")
+
+	arg := DeleteExchangeRateParams{
+		FromCurrency: rate.FromCurrency,
+		ToCurrency:   rate.ToCurrency,
+	}
+
+	_, err := testQueries.DeleteExchangeRate(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = testQueries.DeleteExchangeRate(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
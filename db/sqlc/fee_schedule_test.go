@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestQueries_GetFeeAccount(t *testing.T) {
+	for _, currency := range []string{util.USD, util.EUR, util.CAD} {
+		account, err := testQueries.GetFeeAccount(context.Background(), currency)
+		require.NoError(t, err)
+		require.Equal(t, currency, account.Currency)
+
+		// Fees are kept apart from the cash of the settlement accounts
+		settlementAccount, err := testQueries.GetSettlementAccount(context.Background(), currency)
+		require.NoError(t, err)
+		require.NotEqual(t, settlementAccount.ID, account.ID)
+	}
+}
+
+func TestQueries_FeeSchedule(t *testing.T) {
+	// Schedules apply to every transfer in the currency, they are removed once checked
+	defer func() {
+		_, _ = testQueries.DeleteFeeSchedule(context.Background(), util.CAD)
+	}()
+
+	arg := UpsertFeeScheduleParams{
+		Currency:              util.CAD,
+		Flat:                  25,
+		Percentage:            "1.5",
+		MinFee:                50,
+		MaxFee:                500,
+		FreeTransfersPerMonth: 3,
+	}
+
+	schedule, err := testQueries.UpsertFeeSchedule(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Currency, schedule.Currency)
+	require.Equal(t, arg.Flat, schedule.Flat)
+	require.Equal(t, arg.Percentage, schedule.Percentage)
+	require.Equal(t, arg.MinFee, schedule.MinFee)
+	require.Equal(t, arg.MaxFee, schedule.MaxFee)
+	require.Equal(t, arg.FreeTransfersPerMonth, schedule.FreeTransfersPerMonth)
+	require.NotZero(t, schedule.UpdatedAt)
+
+	arg.Flat = 0
+	arg.MaxFee = 0
+
+	updated, err := testQueries.UpsertFeeSchedule(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, updated.Flat)
+	require.Zero(t, updated.MaxFee)
+
+	got, err := testQueries.GetFeeSchedule(context.Background(), util.CAD)
+	require.NoError(t, err)
+	require.Equal(t, updated.Flat, got.Flat)
+
+	schedules, err := testQueries.ListFeeSchedules(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, schedules)
+
+	// The maximum fee cannot be lower than the minimum
+	arg.MaxFee = 10
+
+	_, err = testQueries.UpsertFeeSchedule(context.Background(), arg)
+	require.Error(t, err)
+
+	_, err = testQueries.DeleteFeeSchedule(context.Background(), util.CAD)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetFeeSchedule(context.Background(), util.CAD)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func createRandomIdempotencyKey(t *testing.T) IdempotencyKey {
+	user := createRandomUser(t)
+
+	arg := CreateIdempotencyKeyParams{
+		Username:       user.Username,
+		IdempotencyKey: util.RandomString(16),
+		RequestHash:    util.RandomString(32),
+		Response:       json.RawMessage(`{"transfer":{"id":1}}`),
+	}
+
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.Username, key.Username)
+	require.Equal(t, arg.IdempotencyKey, key.IdempotencyKey)
+	require.Equal(t, arg.RequestHash, key.RequestHash)
+	require.JSONEq(t, string(arg.Response), string(key.Response))
+	require.NotZero(t, key.CreatedAt)
+
+	return key
+}
+
+func TestQueries_CreateIdempotencyKey(t *testing.T) {
+	key := createRandomIdempotencyKey(t)
+
+	// Creating the same key again does not overwrite it
+	_, err := testQueries.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		Username:       key.Username,
+		IdempotencyKey: key.IdempotencyKey,
+		RequestHash:    util.RandomString(32),
+		Response:       json.RawMessage(`{}`),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestQueries_GetIdempotencyKey(t *testing.T) {
+	expected := createRandomIdempotencyKey(t)
+
+	actual, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username:       expected.Username,
+		IdempotencyKey: expected.IdempotencyKey,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, expected.RequestHash, actual.RequestHash)
+	require.JSONEq(t, string(expected.Response), string(actual.Response))
+}
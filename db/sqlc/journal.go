@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Posting is a single line of a journal, a positive amount credits the account and a negative one debits it. The
+// currency must be the one the account holds.
+type Posting struct {
+	AccountID int64  `json:"account_id"`
+	Currency  string `json:"currency"`
+	Amount    int64  `json:"amount"`
+}
+
+// JournalResult is the result of posting a journal, the entries are in the order of the postings and the accounts
+// have their balances after the journal
+type JournalResult struct {
+	Journal  Journal           `json:"journal"`
+	Entries  []Entry           `json:"entries"`
+	Accounts map[int64]Account `json:"accounts"`
+}
+
+// checkBalanced returns ErrUnbalancedJournal if the postings do not sum to zero in each currency
+func checkBalanced(postings []Posting) error {
+	if len(postings) == 0 {
+		return errors.New("journal has no postings")
+	}
+
+	var currencies []string
+	sums := make(map[string]int64)
+
+	for i, posting := range postings {
+		if posting.Amount == 0 {
+			return fmt.Errorf("posting %d has no amount", i)
+		}
+
+		if _, ok := sums[posting.Currency]; !ok {
+			currencies = append(currencies, posting.Currency)
+		}
+
+		sums[posting.Currency] += posting.Amount
+	}
+
+	for _, currency := range currencies {
+		if sums[currency] != 0 {
+			return fmt.Errorf("%w: %s postings sum to %d", ErrUnbalancedJournal, currency, sums[currency])
+		}
+	}
+
+	return nil
+}
+
+// postJournal records the postings as the entries of a new journal and updates the balances of their accounts.
+// ErrUnbalancedJournal is returned before anything is written if the postings do not sum to zero in each currency,
+// the database checks it again when the transaction commits. The balances are updated in the order of the account
+// IDs, so accounts that must not deadlock with each other have to be locked beforehand.
+func postJournal(ctx context.Context, q *Queries, postings []Posting) (JournalResult, error) {
+	var result JournalResult
+
+	if err := checkBalanced(postings); err != nil {
+		return result, err
+	}
+
+	var err error
+
+	result.Journal, err = q.CreateJournal(ctx)
+
+	if err != nil {
+		return result, err
+	}
+
+	journalID := sql.NullInt64{Int64: result.Journal.ID, Valid: true}
+
+	result.Entries = make([]Entry, len(postings))
+	currencies := make(map[int64]string, len(postings))
+	changes := make(map[int64]int64, len(postings))
+
+	for i, posting := range postings {
+		result.Entries[i], err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: posting.AccountID,
+			Amount:    posting.Amount,
+			JournalID: journalID,
+		})
+
+		if err != nil {
+			return result, err
+		}
+
+		currencies[posting.AccountID] = posting.Currency
+		changes[posting.AccountID] += posting.Amount
+	}
+
+	ids := make([]int64, 0, len(changes))
+
+	for id := range changes {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	result.Accounts = make(map[int64]Account, len(ids))
+
+	for _, id := range ids {
+		account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     int32(id),
+			Amount: changes[id],
+		})
+
+		if err != nil {
+			return result, err
+		}
+
+		// The journal only balances if every posting is in the currency of its account
+		if account.Currency != currencies[id] {
+			return result, fmt.Errorf("account [%d] currency mismatch: %s vs %s", id, account.Currency, currencies[id])
+		}
+
+		result.Accounts[id] = account
+	}
+
+	return result, nil
+}
+
+// transferJournal posts the journal debiting the amount from the sender and crediting the converted amount to the
+// receiver. Money converted between currencies goes through the bank's FX accounts, which take the amount in the
+// sender's currency and pay out the converted amount, so that the journal balances in both currencies. The first two
+// entries of the journal are always those of the sender and the receiver.
+func transferJournal(ctx context.Context, q *Queries, from, to Account, amount, toAmount int64) (JournalResult,
+	error) {
+
+	postings := []Posting{
+		{AccountID: int64(from.ID), Currency: from.Currency, Amount: -amount},
+		{AccountID: int64(to.ID), Currency: to.Currency, Amount: toAmount},
+	}
+
+	if from.Currency != to.Currency {
+		for _, leg := range []Posting{
+			{Currency: from.Currency, Amount: amount},
+			{Currency: to.Currency, Amount: -toAmount},
+		} {
+			fxAccount, err := q.GetFxAccount(ctx, leg.Currency)
+
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return JournalResult{}, ErrFxAccountNotFound
+				}
+
+				return JournalResult{}, err
+			}
+
+			leg.AccountID = int64(fxAccount.ID)
+			postings = append(postings, leg)
+		}
+	}
+
+	return postJournal(ctx, q, postings)
+}
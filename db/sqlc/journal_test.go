@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCheckBalanced(t *testing.T) {
+	testCases := []struct {
+		name     string
+		postings []Posting
+		isValid  bool
+	}{
+		{
+			name: "Balanced",
+			postings: []Posting{
+				{AccountID: 1, Currency: util.USD, Amount: -100},
+				{AccountID: 2, Currency: util.USD, Amount: 100},
+			},
+			isValid: true,
+		},
+		{
+			name: "BalancedInEachCurrency",
+			postings: []Posting{
+				{AccountID: 1, Currency: util.EUR, Amount: -100},
+				{AccountID: 2, Currency: util.USD, Amount: 110},
+				{AccountID: 3, Currency: util.EUR, Amount: 100},
+				{AccountID: 4, Currency: util.USD, Amount: -110},
+			},
+			isValid: true,
+		},
+		{
+			name: "Unbalanced",
+			postings: []Posting{
+				{AccountID: 1, Currency: util.USD, Amount: -100},
+				{AccountID: 2, Currency: util.USD, Amount: 99},
+			},
+		},
+		{
+			// Amounts in different currencies never offset each other
+			name: "UnbalancedAcrossCurrencies",
+			postings: []Posting{
+				{AccountID: 1, Currency: util.EUR, Amount: -100},
+				{AccountID: 2, Currency: util.USD, Amount: 100},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkBalanced(tc.postings)
+
+			if tc.isValid {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrUnbalancedJournal)
+		})
+	}
+}
+
+func TestCheckBalancedInvalidPostings(t *testing.T) {
+	require.Error(t, checkBalanced(nil))
+
+	err := checkBalanced([]Posting{
+		{AccountID: 1, Currency: util.USD, Amount: 0},
+	})
+	require.Error(t, err)
+}
+
+func TestQueries_GetJournal(t *testing.T) {
+	journal, err := testQueries.CreateJournal(context.Background())
+	require.NoError(t, err)
+	require.NotZero(t, journal.ID)
+	require.NotZero(t, journal.CreatedAt)
+
+	gotJournal, err := testQueries.GetJournal(context.Background(), journal.ID)
+	require.NoError(t, err)
+	require.Equal(t, journal, gotJournal)
+}
+
+func TestPostJournal(t *testing.T) {
+	from := createRandomAccountWithBalance(t, 1000)
+	toOne := createRandomAccountWithCurrency(t, 0, from.Currency)
+	toTwo := createRandomAccountWithCurrency(t, 0, from.Currency)
+
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+
+	result, err := postJournal(context.Background(), New(tx), []Posting{
+		{AccountID: int64(from.ID), Currency: from.Currency, Amount: -300},
+		{AccountID: int64(toOne.ID), Currency: from.Currency, Amount: 100},
+		{AccountID: int64(toTwo.ID), Currency: from.Currency, Amount: 200},
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	require.Len(t, result.Entries, 3)
+	require.Equal(t, int64(-300), result.Entries[0].Amount)
+	require.Equal(t, int64(200), result.Entries[2].Amount)
+	require.Equal(t, from.Balance-300, result.Accounts[int64(from.ID)].Balance)
+	require.Equal(t, toOne.Balance+100, result.Accounts[int64(toOne.ID)].Balance)
+	require.Equal(t, toTwo.Balance+200, result.Accounts[int64(toTwo.ID)].Balance)
+
+	entries, err := testQueries.ListJournalEntries(context.Background(), result.Entries[0].JournalID)
+	require.NoError(t, err)
+	require.Equal(t, result.Entries, entries)
+}
+
+func TestPostJournalUnbalanced(t *testing.T) {
+	from := createRandomAccountWithBalance(t, 1000)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = postJournal(context.Background(), New(tx), []Posting{
+		{AccountID: int64(from.ID), Currency: from.Currency, Amount: -300},
+		{AccountID: int64(to.ID), Currency: from.Currency, Amount: 200},
+	})
+	require.ErrorIs(t, err, ErrUnbalancedJournal)
+}
+
+func TestJournalBalancedOnCommit(t *testing.T) {
+	account := createRandomAccountWithBalance(t, 1000)
+
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+
+	q := New(tx)
+
+	journal, err := q.CreateJournal(context.Background())
+	require.NoError(t, err)
+
+	// The database rejects journals that do not balance even if they are written without the store
+	_, err = q.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID: int64(account.ID),
+		Amount:    100,
+		JournalID: sql.NullInt64{Int64: journal.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Error(t, tx.Commit())
+
+	_, err = testQueries.GetJournal(context.Background(), journal.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -3,15 +3,33 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
-	ID        int32     `json:"id"`
-	Owner     string    `json:"owner"`
+	ID    int32  `json:"id"`
+	Owner string `json:"owner"`
+	// the ledger balance, it includes the money held
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"createdAt"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraftLimit"`
+	// money reserved for transfers awaiting approval or posting, it cannot be spent
+	Held int64 `json:"held"`
+	// the ledger balance less the money held
+	AvailableBalance int64 `json:"availableBalance"`
+}
+
+type AccountCoOwner struct {
+	AccountID int64 `json:"accountID"`
+	// a co-owner may approve or reject the large transfers of the account, never the owner of the account
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 type Entry struct {
@@ -20,6 +38,163 @@ type Entry struct {
 	// can be positive or negative
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"createdAt"`
+	// the entries of a journal sum to zero in each currency, it is NULL for entries booked before journals
+	JournalID sql.NullInt64 `json:"journalID"`
+}
+
+type ExchangeRate struct {
+	FromCurrency string `json:"fromCurrency"`
+	ToCurrency   string `json:"toCurrency"`
+	// units of to_currency for one unit of from_currency
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type FeeAccount struct {
+	Currency string `json:"currency"`
+	// the account credited with the fees charged in the currency
+	AccountID int64 `json:"accountID"`
+}
+
+type FeeSchedule struct {
+	Currency string `json:"currency"`
+	Flat     int64  `json:"flat"`
+	// percent of the amount charged on top of the flat fee
+	Percentage string `json:"percentage"`
+	MinFee     int64  `json:"minFee"`
+	// zero means no maximum
+	MaxFee int64 `json:"maxFee"`
+	// transfers each user makes in the currency without a fee every calendar month
+	FreeTransfersPerMonth int32     `json:"freeTransfersPerMonth"`
+	UpdatedAt             time.Time `json:"updatedAt"`
+}
+
+type FxAccount struct {
+	Currency string `json:"currency"`
+	// the account on the other side of the money converted to or from the currency
+	AccountID int64 `json:"accountID"`
+}
+
+type IdempotencyKey struct {
+	Username       string `json:"username"`
+	IdempotencyKey string `json:"idempotencyKey"`
+	// hash of the request the key was first used with
+	RequestHash string `json:"requestHash"`
+	// the serialized result replayed for retries of the request
+	Response  json.RawMessage `json:"response"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type Journal struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type MoneyRequest struct {
+	ID int64 `json:"id"`
+	// the user asking to be paid
+	Requester string `json:"requester"`
+	// the user asked to pay
+	Payer    string `json:"payer"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	// pending, accepted, declined, cancelled or expired
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	// the transfer made when the payer accepted the request
+	TransferID sql.NullInt64 `json:"transferID"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+type RevokedToken struct {
+	// the ID of the revoked token payload
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	RevokedAt time.Time `json:"revokedAt"`
+}
+
+type RiskDecision struct {
+	ID int64 `json:"id"`
+	// the user who requested the transfer
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"fromAccountID"`
+	ToAccountID   int64  `json:"toAccountID"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	// allow, review or deny, only allowed transfers are made
+	Decision string `json:"decision"`
+	// the rules that matched the transfer and why
+	RuleHits  json.RawMessage `json:"ruleHits"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"fromAccountID"`
+	ToAccountID   int64 `json:"toAccountID"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// pending, processing, completed, failed or cancelled
+	Status    string    `json:"status"`
+	ExecuteAt time.Time `json:"executeAt"`
+	// when the worker may pick the transfer up, moved forward after a failed attempt
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	AttemptCount  int32     `json:"attemptCount"`
+	// the transfer created by the successful attempt
+	TransferID sql.NullInt64 `json:"transferID"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	// the standing order the transfer was generated by
+	StandingOrderID sql.NullInt64 `json:"standingOrderID"`
+	// when a worker last claimed the transfer, a processing transfer whose claim timed out is claimed again
+	ClaimedAt sql.NullTime `json:"claimedAt"`
+}
+
+type ScheduledTransferAttempt struct {
+	ID                  int64         `json:"id"`
+	ScheduledTransferID int64         `json:"scheduledTransferID"`
+	TransferID          sql.NullInt64 `json:"transferID"`
+	// why the attempt failed, empty if it succeeded
+	Error     string    `json:"error"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	RefreshToken string    `json:"refreshToken"`
+	UserAgent    string    `json:"userAgent"`
+	ClientIp     string    `json:"clientIp"`
+	IsBlocked    bool      `json:"isBlocked"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type SettlementAccount struct {
+	Currency string `json:"currency"`
+	// the account on the other side of the deposits and withdrawals in the currency
+	AccountID int64 `json:"accountID"`
+}
+
+type StandingOrder struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"fromAccountID"`
+	ToAccountID   int64 `json:"toAccountID"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// daily, weekly or monthly
+	Frequency string `json:"frequency"`
+	// the day monthly orders run on, the last day of shorter months is used instead
+	DayOfMonth int32 `json:"dayOfMonth"`
+	// active, paused, completed or cancelled
+	Status    string    `json:"status"`
+	NextRunAt time.Time `json:"nextRunAt"`
+	// no transfer is made after this time
+	EndAt sql.NullTime `json:"endAt"`
+	// the order completes after this many transfers
+	MaxRuns   sql.NullInt32 `json:"maxRuns"`
+	RunCount  int32         `json:"runCount"`
+	CreatedAt time.Time     `json:"createdAt"`
 }
 
 type Transfer struct {
@@ -29,6 +204,107 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"createdAt"`
+	// the amount credited in the receiver's currency
+	ToAmount int64 `json:"toAmount"`
+	// the rate applied to convert amount into to_amount
+	ExchangeRate string `json:"exchangeRate"`
+	// a note from the sender shown to both parties
+	Memo string `json:"memo"`
+	// an identifier of the transfer in the systems of the sender
+	Reference string `json:"reference"`
+	// a JSON object of up to 4KB attached by the sender
+	Metadata json.RawMessage `json:"metadata"`
+	// pending transfers only hold the money of the sender until they are posted or voided
+	Status string `json:"status"`
+	// when the money moved, the entries of the transfer are created at this time
+	PostedAt sql.NullTime `json:"postedAt"`
+	// the journal moving the money, it is NULL until the transfer is posted and for transfers posted before journals
+	JournalID sql.NullInt64 `json:"journalID"`
+}
+
+type TransferApproval struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"fromAccountID"`
+	ToAccountID   int64 `json:"toAccountID"`
+	// held on the sender account until the transfer is approved, rejected or expires
+	Amount      int64           `json:"amount"`
+	Memo        string          `json:"memo"`
+	Reference   string          `json:"reference"`
+	Metadata    json.RawMessage `json:"metadata"`
+	RequestedBy string          `json:"requestedBy"`
+	// empty when the client did not send one
+	IdempotencyKey string `json:"idempotencyKey"`
+	RequestHash    string `json:"requestHash"`
+	// pending, approved, rejected or expired
+	Status string `json:"status"`
+	// the officer who approved or rejected the transfer, never the requester
+	DecidedBy sql.NullString `json:"decidedBy"`
+	Reason    string         `json:"reason"`
+	// the transfer made once approved
+	TransferID sql.NullInt64 `json:"transferID"`
+	ExpiresAt  time.Time     `json:"expiresAt"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+type TransferBatch struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"fromAccountID"`
+	// the sum of the legs in the currency of the from account
+	TotalAmount int64     `json:"totalAmount"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type TransferBatchLeg struct {
+	BatchID int64 `json:"batchID"`
+	// the position of the leg in the request, starting at 0
+	LegIndex   int32 `json:"legIndex"`
+	TransferID int64 `json:"transferID"`
+}
+
+type TransferFee struct {
+	TransferID int64 `json:"transferID"`
+	// the transfer moving the fee from the sender to the fee account
+	FeeTransferID    int64  `json:"feeTransferID"`
+	Flat             int64  `json:"flat"`
+	Percentage       string `json:"percentage"`
+	PercentageAmount int64  `json:"percentageAmount"`
+	// added to reach the minimum fee or subtracted to stay within the maximum fee
+	Adjustment int64 `json:"adjustment"`
+	Total      int64 `json:"total"`
+}
+
+type TransferHold struct {
+	TransferID int64 `json:"transferID"`
+	// the amount held when the transfer was created, it may be posted for less
+	Amount int64 `json:"amount"`
+	// the transfer is voided and the money released if it is still pending by then
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type TransferLimit struct {
+	// user limits apply to a single username and take precedence over role limits
+	Scope string `json:"scope"`
+	// the username or the role the limit applies to
+	Subject  string `json:"subject"`
+	Currency string `json:"currency"`
+	// zero means no limit
+	PerTransaction int64 `json:"perTransaction"`
+	// zero means no limit
+	Daily int64 `json:"daily"`
+	// zero means no limit
+	Monthly   int64     `json:"monthly"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type TransferReversal struct {
+	// the transfer that was reversed, it can only be reversed once
+	TransferID int64 `json:"transferID"`
+	// the transfer moving the money back
+	ReversalTransferID int64  `json:"reversalTransferID"`
+	Reason             string `json:"reason"`
+	// the username of the admin who reversed the transfer
+	ReversedBy string    `json:"reversedBy"`
+	CreatedAt  time.Time `json:"createdAt"`
 }
 
 type User struct {
@@ -38,4 +314,7 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"passwordChangedAt"`
 	CreatedAt         time.Time `json:"createdAt"`
+	// tokens issued before this time are revoked
+	TokensRevokedAt time.Time `json:"tokensRevokedAt"`
+	Role            string    `json:"role"`
 }
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomMoneyRequest(t *testing.T, requester, payer Account, expiresAt time.Time) MoneyRequest {
+	arg := CreateMoneyRequestParams{
+		Requester: requester.Owner,
+		Payer:     payer.Owner,
+		Amount:    util.RandomInt(1, 100),
+		Currency:  requester.Currency,
+		ExpiresAt: expiresAt,
+	}
+
+	request, err := testQueries.CreateMoneyRequest(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, request)
+
+	require.Equal(t, arg.Requester, request.Requester)
+	require.Equal(t, arg.Payer, request.Payer)
+	require.Equal(t, arg.Amount, request.Amount)
+	require.Equal(t, arg.Currency, request.Currency)
+	require.Equal(t, util.MoneyRequestPending, request.Status)
+	require.WithinDuration(t, arg.ExpiresAt, request.ExpiresAt, time.Second)
+	require.False(t, request.TransferID.Valid)
+
+	require.NotZero(t, request.ID)
+	require.NotZero(t, request.CreatedAt)
+
+	return request
+}
+
+func TestQueries_CreateMoneyRequest(t *testing.T) {
+	requester := createRandomAccount(t)
+	payer := createRandomAccountWithCurrency(t, 1000, requester.Currency)
+
+	createRandomMoneyRequest(t, requester, payer, time.Now().Add(time.Hour))
+}
+
+func TestQueries_ListMoneyRequests(t *testing.T) {
+	requester := createRandomAccount(t)
+	payer := createRandomAccountWithCurrency(t, 1000, requester.Currency)
+
+	for i := 0; i < 3; i++ {
+		createRandomMoneyRequest(t, requester, payer, time.Now().Add(time.Hour))
+	}
+
+	declined := createRandomMoneyRequest(t, requester, payer, time.Now().Add(time.Hour))
+
+	_, err := testQueries.CloseMoneyRequest(context.Background(), CloseMoneyRequestParams{
+		Status: util.MoneyRequestDeclined,
+		ID:     declined.ID,
+	})
+	require.NoError(t, err)
+
+	arg := ListMoneyRequestsParams{
+		Direction: "incoming",
+		Username:  payer.Owner,
+		Limit:     10,
+	}
+
+	incoming, err := testQueries.ListMoneyRequests(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, incoming, 4)
+
+	for _, request := range incoming {
+		require.Equal(t, payer.Owner, request.Payer)
+	}
+
+	arg.Status = util.MoneyRequestPending
+
+	pending, err := testQueries.ListMoneyRequests(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+
+	// The payer did not request money from anyone
+	arg.Direction = "outgoing"
+
+	outgoing, err := testQueries.ListMoneyRequests(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, outgoing)
+}
+
+func TestQueries_CloseMoneyRequest(t *testing.T) {
+	requester := createRandomAccount(t)
+	payer := createRandomAccountWithCurrency(t, 1000, requester.Currency)
+
+	request := createRandomMoneyRequest(t, requester, payer, time.Now().Add(time.Hour))
+
+	cancelled, err := testQueries.CloseMoneyRequest(context.Background(), CloseMoneyRequestParams{
+		Status: util.MoneyRequestCancelled,
+		ID:     request.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.MoneyRequestCancelled, cancelled.Status)
+
+	// Only pending requests can be closed
+	_, err = testQueries.CloseMoneyRequest(context.Background(), CloseMoneyRequestParams{
+		Status: util.MoneyRequestDeclined,
+		ID:     request.ID,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestQueries_ExpireMoneyRequests(t *testing.T) {
+	requester := createRandomAccount(t)
+	payer := createRandomAccountWithCurrency(t, 1000, requester.Currency)
+
+	expired := createRandomMoneyRequest(t, requester, payer, time.Now().Add(-time.Minute))
+	pending := createRandomMoneyRequest(t, requester, payer, time.Now().Add(time.Hour))
+
+	err := testQueries.ExpireMoneyRequests(context.Background())
+	require.NoError(t, err)
+
+	got, err := testQueries.GetMoneyRequest(context.Background(), expired.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.MoneyRequestExpired, got.Status)
+
+	got, err = testQueries.GetMoneyRequest(context.Background(), pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.MoneyRequestPending, got.Status)
+}
@@ -0,0 +1,244 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// FeeBreakdown explains the fee charged for a transfer. The amounts are in the sender's currency and add up to the
+// total, which the sender pays on top of the amount transferred.
+type FeeBreakdown struct {
+	Currency string `json:"currency"`
+	Flat     int64  `json:"flat"`
+	// Percentage is the percent of the amount charged on top of the flat fee
+	Percentage       string `json:"percentage"`
+	PercentageAmount int64  `json:"percentage_amount"`
+	// Adjustment is added to reach the minimum fee or subtracted to stay within the maximum fee
+	Adjustment int64 `json:"adjustment"`
+	Total      int64 `json:"total"`
+	// FreeTransfer is true if the transfer is one of the free transfers of the month
+	FreeTransfer bool `json:"free_transfer"`
+	// FreeTransfersLeft is the number of transfers the sender can still make for free this month
+	FreeTransfersLeft int64 `json:"free_transfers_left"`
+	// FeeTransferID is the transfer moving the fee to the fee account, it is zero if nothing was charged
+	FeeTransferID int64 `json:"fee_transfer_id"`
+}
+
+// computeFee works out the fee of transferring the amount under the schedule, given the number of transfers the
+// sender already made in the currency this month. The percentage amount is rounded half up to the nearest minor unit.
+func computeFee(schedule FeeSchedule, amount int64, transfersThisMonth int64) (FeeBreakdown, error) {
+	fee := FeeBreakdown{
+		Currency:   schedule.Currency,
+		Percentage: schedule.Percentage,
+	}
+
+	if free := int64(schedule.FreeTransfersPerMonth); transfersThisMonth < free {
+		fee.FreeTransfer = true
+		fee.FreeTransfersLeft = free - transfersThisMonth - 1
+		return fee, nil
+	}
+
+	percentage, ok := new(big.Rat).SetString(schedule.Percentage)
+
+	if !ok || percentage.Sign() < 0 {
+		return fee, fmt.Errorf("invalid fee percentage: %s", schedule.Percentage)
+	}
+
+	percentageAmount, ok := roundHalfUp(percentage.Mul(percentage, big.NewRat(amount, 100)))
+
+	if !ok {
+		return fee, errors.New("fee is out of range")
+	}
+
+	fee.Flat = schedule.Flat
+	fee.PercentageAmount = percentageAmount
+	fee.Total = fee.Flat + fee.PercentageAmount
+
+	if fee.Total < schedule.MinFee {
+		fee.Total = schedule.MinFee
+	}
+
+	if schedule.MaxFee > 0 && fee.Total > schedule.MaxFee {
+		fee.Total = schedule.MaxFee
+	}
+
+	fee.Adjustment = fee.Total - fee.Flat - fee.PercentageAmount
+	return fee, nil
+}
+
+// priceTransfer returns the fee of transferring the amount from the account and the fee account it is paid into.
+// Transfers in a currency without a fee schedule and transfers made by the fee account itself are free.
+// The owner must already be locked, so that concurrent transfers count the free transfers of the month one after
+// the other.
+func priceTransfer(ctx context.Context, q *Queries, account Account, amount int64) (FeeBreakdown, Account, error) {
+	fees, feeAccount, err := priceTransfers(ctx, q, account, amount)
+	return fees[0], feeAccount, err
+}
+
+// priceTransfers returns the fees of transferring each of the amounts from the account like priceTransfer, for
+// transfers made one after the other in the same transaction. Each transfer uses up one of the free transfers of the
+// month, so only the first ones of a batch can be free.
+func priceTransfers(ctx context.Context, q *Queries, account Account, amounts ...int64) ([]FeeBreakdown, Account,
+	error) {
+
+	fees := make([]FeeBreakdown, len(amounts))
+
+	for i := range fees {
+		fees[i] = FeeBreakdown{
+			Currency:   account.Currency,
+			Percentage: "0",
+		}
+	}
+
+	schedule, err := q.GetFeeSchedule(ctx, account.Currency)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fees, Account{}, nil
+		}
+
+		return fees, Account{}, err
+	}
+
+	// Every schedule has a fee account, the schedules reference them
+	feeAccount, err := q.GetFeeAccount(ctx, account.Currency)
+
+	if err != nil || feeAccount.ID == account.ID {
+		return fees, feeAccount, err
+	}
+
+	var transfersThisMonth int64
+
+	if schedule.FreeTransfersPerMonth > 0 {
+		// Months start at midnight UTC like the monthly transfer limits
+		now := time.Now().UTC()
+
+		stats, err := q.GetOutgoingTransferStatsSince(ctx, GetOutgoingTransferStatsSinceParams{
+			Owner:       account.Owner,
+			Currency:    account.Currency,
+			CreatedFrom: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		})
+
+		if err != nil {
+			return fees, feeAccount, err
+		}
+
+		transfersThisMonth = stats.Count
+	}
+
+	for i, amount := range amounts {
+		if fees[i], err = computeFee(schedule, amount, transfersThisMonth+int64(i)); err != nil {
+			return fees, feeAccount, err
+		}
+	}
+
+	return fees, feeAccount, nil
+}
+
+// chargeFee moves the fee of the transfer in the result from the sender to the fee account and records how it was
+// worked out. The fee account is only locked once the accounts of the transfer are, so transfers cannot deadlock on it.
+func chargeFee(ctx context.Context, q *Queries, result *TransferTxResult, feeAccount Account) error {
+	feeResult, err := postTransfer(ctx, q, CreateTransferParams{
+		FromAccountID: int64(result.FromAccount.ID),
+		ToAccountID:   int64(feeAccount.ID),
+		Amount:        result.Fee.Total,
+		ToAmount:      result.Fee.Total,
+		ExchangeRate:  "1",
+		Memo:          fmt.Sprintf("Fee for transfer %d", result.Transfer.ID),
+	}, result.FromAccount, feeAccount)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateTransferFee(ctx, CreateTransferFeeParams{
+		TransferID:       result.Transfer.ID,
+		FeeTransferID:    feeResult.Transfer.ID,
+		Flat:             result.Fee.Flat,
+		Percentage:       result.Fee.Percentage,
+		PercentageAmount: result.Fee.PercentageAmount,
+		Adjustment:       result.Fee.Adjustment,
+		Total:            result.Fee.Total,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	result.FromAccount = feeResult.FromAccount
+	result.Fee.FeeTransferID = feeResult.Transfer.ID
+
+	// The receiver may be the fee account itself
+	if result.ToAccount.ID == feeAccount.ID {
+		result.ToAccount = feeResult.ToAccount
+	}
+
+	return nil
+}
+
+// refundFee moves the fee charged on the original transfer from the fee account back to the sender, who is the
+// receiver of the reversal in the result, and records the refund as the reversal of the fee transfer. Nothing is
+// refunded if the fee transfer was already reversed on its own. The fee account is locked last like in chargeFee.
+func refundFee(ctx context.Context, q *Queries, result *ReverseTransferTxResult, fee TransferFee,
+	arg ReverseTransferTxParams) error {
+
+	_, err := q.GetTransferReversal(ctx, fee.FeeTransferID)
+
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	feeTransfer, err := q.GetTransfer(ctx, fee.FeeTransferID)
+
+	if err != nil {
+		return err
+	}
+
+	feeAccount, err := q.GetAccountForUpdate(ctx, int32(feeTransfer.ToAccountID))
+
+	if err != nil {
+		return err
+	}
+
+	refund, err := postTransfer(ctx, q, CreateTransferParams{
+		FromAccountID: feeTransfer.ToAccountID,
+		ToAccountID:   feeTransfer.FromAccountID,
+		Amount:        fee.Total,
+		ToAmount:      fee.Total,
+		ExchangeRate:  "1",
+		Memo:          fmt.Sprintf("Refund of the fee for transfer %d", fee.TransferID),
+	}, feeAccount, result.ToAccount)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateTransferReversal(ctx, CreateTransferReversalParams{
+		TransferID:         fee.FeeTransferID,
+		ReversalTransferID: refund.Transfer.ID,
+		Reason:             arg.Reason,
+		ReversedBy:         arg.ReversedBy,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	result.FeeRefund = refund.Transfer
+	result.ToAccount = refund.ToAccount
+
+	// The original receiver may be the fee account itself
+	if result.FromAccount.ID == feeAccount.ID {
+		result.FromAccount = refund.FromAccount
+	}
+
+	return nil
+}
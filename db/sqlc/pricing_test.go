@@ -0,0 +1,87 @@
+package db
+
+import (
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestComputeFee(t *testing.T) {
+	testCases := []struct {
+		name               string
+		schedule           FeeSchedule
+		amount             int64
+		transfersThisMonth int64
+		expected           FeeBreakdown
+	}{
+		{
+			name:     "Flat",
+			schedule: FeeSchedule{Flat: 25, Percentage: "0"},
+			amount:   1000,
+			expected: FeeBreakdown{Flat: 25, Percentage: "0", Total: 25},
+		},
+		{
+			name:     "Percentage",
+			schedule: FeeSchedule{Percentage: "1.5"},
+			amount:   1000,
+			expected: FeeBreakdown{Percentage: "1.5", PercentageAmount: 15, Total: 15},
+		},
+		{
+			name:     "FlatAndPercentage",
+			schedule: FeeSchedule{Flat: 10, Percentage: "2"},
+			amount:   1000,
+			expected: FeeBreakdown{Flat: 10, Percentage: "2", PercentageAmount: 20, Total: 30},
+		},
+		{
+			name:     "PercentageRoundsHalfUp",
+			schedule: FeeSchedule{Percentage: "1.5"},
+			amount:   100,
+			expected: FeeBreakdown{Percentage: "1.5", PercentageAmount: 2, Total: 2},
+		},
+		{
+			name:     "MinFee",
+			schedule: FeeSchedule{Percentage: "1", MinFee: 50},
+			amount:   1000,
+			expected: FeeBreakdown{Percentage: "1", PercentageAmount: 10, Adjustment: 40, Total: 50},
+		},
+		{
+			name:     "MaxFee",
+			schedule: FeeSchedule{Flat: 10, Percentage: "1", MinFee: 50, MaxFee: 200},
+			amount:   100000,
+			expected: FeeBreakdown{Flat: 10, Percentage: "1", PercentageAmount: 1000, Adjustment: -810, Total: 200},
+		},
+		{
+			name:               "FreeTransfer",
+			schedule:           FeeSchedule{Flat: 25, Percentage: "1", FreeTransfersPerMonth: 3},
+			amount:             1000,
+			transfersThisMonth: 1,
+			expected:           FeeBreakdown{Percentage: "1", FreeTransfer: true, FreeTransfersLeft: 1},
+		},
+		{
+			name:               "FreeTransfersUsedUp",
+			schedule:           FeeSchedule{Flat: 25, Percentage: "0", FreeTransfersPerMonth: 3},
+			amount:             1000,
+			transfersThisMonth: 3,
+			expected:           FeeBreakdown{Flat: 25, Percentage: "0", Total: 25},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			tc.schedule.Currency = util.USD
+			tc.expected.Currency = util.USD
+
+			fee, err := computeFee(tc.schedule, tc.amount, tc.transfersThisMonth)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, fee)
+			require.Equal(t, fee.Total, fee.Flat+fee.PercentageAmount+fee.Adjustment)
+		})
+	}
+}
+
+func TestComputeFeeInvalidPercentage(t *testing.T) {
+	_, err := computeFee(FeeSchedule{Percentage: "one"}, 1000, 0)
+	require.Error(t, err)
+}
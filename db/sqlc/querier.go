@@ -4,24 +4,126 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AcceptMoneyRequest(ctx context.Context, arg AcceptMoneyRequestParams) (MoneyRequest, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeld(ctx context.Context, arg AddAccountHeldParams) (Account, error)
+	BlockSession(ctx context.Context, id uuid.UUID) (Session, error)
+	BlockUserSessions(ctx context.Context, username string) error
+	CancelScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	CancelStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	CancelStandingOrderTransfers(ctx context.Context, standingOrderID sql.NullInt64) error
+	ClaimDueScheduledTransfers(ctx context.Context, arg ClaimDueScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ClaimDueStandingOrders(ctx context.Context, limit int32) ([]StandingOrder, error)
+	CloseMoneyRequest(ctx context.Context, arg CloseMoneyRequestParams) (MoneyRequest, error)
+	CountTransfersToAccount(ctx context.Context, arg CountTransfersToAccountParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountCoOwner(ctx context.Context, arg CreateAccountCoOwnerParams) (AccountCoOwner, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateJournal(ctx context.Context) (Journal, error)
+	CreateMoneyRequest(ctx context.Context, arg CreateMoneyRequestParams) (MoneyRequest, error)
+	CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (Transfer, error)
+	CreateRevokedToken(ctx context.Context, arg CreateRevokedTokenParams) (RevokedToken, error)
+	CreateRiskDecision(ctx context.Context, arg CreateRiskDecisionParams) (RiskDecision, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferAttempt(ctx context.Context, arg CreateScheduledTransferAttemptParams) (ScheduledTransferAttempt, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateStandingOrder(ctx context.Context, arg CreateStandingOrderParams) (StandingOrder, error)
+	CreateStandingOrderTransfer(ctx context.Context, arg CreateStandingOrderTransferParams) (ScheduledTransfer, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferApproval(ctx context.Context, arg CreateTransferApprovalParams) (TransferApproval, error)
+	CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error)
+	CreateTransferBatchLeg(ctx context.Context, arg CreateTransferBatchLegParams) (TransferBatchLeg, error)
+	CreateTransferFee(ctx context.Context, arg CreateTransferFeeParams) (TransferFee, error)
+	CreateTransferHold(ctx context.Context, arg CreateTransferHoldParams) (TransferHold, error)
+	CreateTransferReversal(ctx context.Context, arg CreateTransferReversalParams) (TransferReversal, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DecideTransferApproval(ctx context.Context, arg DecideTransferApprovalParams) (TransferApproval, error)
 	DeleteAccount(ctx context.Context, id int32) error
+	DeleteAccountCoOwner(ctx context.Context, arg DeleteAccountCoOwnerParams) (AccountCoOwner, error)
+	DeleteExchangeRate(ctx context.Context, arg DeleteExchangeRateParams) (ExchangeRate, error)
+	DeleteFeeSchedule(ctx context.Context, currency string) (FeeSchedule, error)
+	DeleteTransferLimit(ctx context.Context, arg DeleteTransferLimitParams) (TransferLimit, error)
+	ExpireMoneyRequests(ctx context.Context) error
+	ExpirePendingTransfers(ctx context.Context) error
+	ExpireTransferApprovals(ctx context.Context) error
 	GetAccount(ctx context.Context, id int32) (Account, error)
+	GetAccountCoOwner(ctx context.Context, arg GetAccountCoOwnerParams) (AccountCoOwner, error)
 	GetAccountForUpdate(ctx context.Context, id int32) (Account, error)
+	GetEffectiveTransferLimit(ctx context.Context, arg GetEffectiveTransferLimitParams) (TransferLimit, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
-	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error)
+	GetFeeAccount(ctx context.Context, currency string) (Account, error)
+	GetFeeSchedule(ctx context.Context, currency string) (FeeSchedule, error)
+	GetFxAccount(ctx context.Context, currency string) (Account, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetJournal(ctx context.Context, id int64) (Journal, error)
+	GetMoneyRequest(ctx context.Context, id int64) (MoneyRequest, error)
+	GetMoneyRequestForUpdate(ctx context.Context, id int64) (MoneyRequest, error)
+	GetOutgoingTransferStatsSince(ctx context.Context, arg GetOutgoingTransferStatsSinceParams) (GetOutgoingTransferStatsSinceRow, error)
+	GetRecipientAccount(ctx context.Context, arg GetRecipientAccountParams) (Account, error)
+	GetRiskDecision(ctx context.Context, id int64) (RiskDecision, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSettlementAccount(ctx context.Context, currency string) (Account, error)
+	GetStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	GetTransfer(ctx context.Context, id int64) (GetTransferRow, error)
+	GetTransferApproval(ctx context.Context, id int64) (TransferApproval, error)
+	GetTransferApprovalByIdempotencyKey(ctx context.Context, arg GetTransferApprovalByIdempotencyKeyParams) (TransferApproval, error)
+	GetTransferApprovalForUpdate(ctx context.Context, id int64) (TransferApproval, error)
+	GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
+	GetTransferFee(ctx context.Context, transferID int64) (TransferFee, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferHold(ctx context.Context, transferID int64) (TransferHold, error)
+	GetTransferReversal(ctx context.Context, transferID int64) (TransferReversal, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	IsTokenRevoked(ctx context.Context, arg IsTokenRevokedParams) (bool, error)
+	ListAccountCoOwners(ctx context.Context, accountID int64) ([]AccountCoOwner, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesBetween(ctx context.Context, arg ListEntriesBetweenParams) ([]Entry, error)
+	ListExchangeRates(ctx context.Context) ([]ExchangeRate, error)
+	ListExpiredPendingTransfersForUpdate(ctx context.Context) ([]Transfer, error)
+	ListExpiredTransferApprovalsForUpdate(ctx context.Context) ([]TransferApproval, error)
+	ListFeeSchedules(ctx context.Context) ([]FeeSchedule, error)
+	ListJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]Entry, error)
+	ListMoneyRequests(ctx context.Context, arg ListMoneyRequestsParams) ([]MoneyRequest, error)
+	ListOwnerScheduledTransfers(ctx context.Context, arg ListOwnerScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListOwnerStandingOrders(ctx context.Context, arg ListOwnerStandingOrdersParams) ([]StandingOrder, error)
+	ListOwnerTransfers(ctx context.Context, arg ListOwnerTransfersParams) ([]ListOwnerTransfersRow, error)
+	ListRiskDecisions(ctx context.Context, arg ListRiskDecisionsParams) ([]RiskDecision, error)
+	ListScheduledTransferAttempts(ctx context.Context, scheduledTransferID int64) ([]ScheduledTransferAttempt, error)
+	ListSettlementAccounts(ctx context.Context) ([]Account, error)
+	ListTransferApprovals(ctx context.Context, arg ListTransferApprovalsParams) ([]TransferApproval, error)
+	ListTransferBatchLegs(ctx context.Context, batchID int64) ([]TransferBatchLeg, error)
+	ListTransferLimits(ctx context.Context) ([]TransferLimit, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	PauseStandingOrder(ctx context.Context, id int64) (StandingOrder, error)
+	PostPendingTransfer(ctx context.Context, arg PostPendingTransferParams) (Transfer, error)
+	ReconcileAccounts(ctx context.Context, arg ReconcileAccountsParams) ([]ReconcileAccountsRow, error)
+	ReconcileEntries(ctx context.Context, arg ReconcileEntriesParams) ([]ReconcileEntriesRow, error)
+	ReconcileTransfers(ctx context.Context, arg ReconcileTransfersParams) ([]ReconcileTransfersRow, error)
+	ResumeStandingOrder(ctx context.Context, arg ResumeStandingOrderParams) (StandingOrder, error)
+	RevokeUserTokens(ctx context.Context, username string) (User, error)
+	SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error)
+	SumOutgoingTransfersSince(ctx context.Context, arg SumOutgoingTransfersSinceParams) (int64, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateScheduledTransferResult(ctx context.Context, arg UpdateScheduledTransferResultParams) (ScheduledTransfer, error)
+	UpdateStandingOrderRun(ctx context.Context, arg UpdateStandingOrderRunParams) (StandingOrder, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error)
+	UpsertFeeSchedule(ctx context.Context, arg UpsertFeeScheduleParams) (FeeSchedule, error)
+	UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error)
+	VoidPendingTransfer(ctx context.Context, id int64) (Transfer, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestQueries_ReconcileAccounts(t *testing.T) {
+	account := createRandomAccount(t)
+	entry := createRandomEntry(t, account)
+
+	rows, err := testQueries.ReconcileAccounts(context.Background(), ReconcileAccountsParams{
+		AfterID: account.ID - 1,
+		Limit:   1,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+
+	require.Equal(t, account.ID, rows[0].ID)
+	require.Equal(t, account.Balance, rows[0].Balance)
+	require.Equal(t, entry.Amount, rows[0].EntriesTotal)
+}
+
+func TestQueries_ReconcileEntries(t *testing.T) {
+	store := NewStore(testDB)
+
+	accountOne := createRandomAccountWithBalance(t, 1000)
+	accountTwo := createRandomAccountWithCurrency(t, 1000, accountOne.Currency)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	orphan := createRandomEntry(t, accountOne)
+
+	rows, err := testQueries.ReconcileEntries(context.Background(), ReconcileEntriesParams{
+		AfterID: result.FromEntry.ID - 1,
+		Limit:   100,
+	})
+	require.NoError(t, err)
+
+	hasTransfer := make(map[int64]bool)
+
+	for _, row := range rows {
+		hasTransfer[row.ID] = row.HasTransfer
+	}
+
+	require.True(t, hasTransfer[result.FromEntry.ID])
+	require.True(t, hasTransfer[result.ToEntry.ID])
+	require.False(t, hasTransfer[orphan.ID])
+}
+
+func TestQueries_ReconcileTransfers(t *testing.T) {
+	accountOne := createRandomAccount(t)
+	accountTwo := createRandomAccount(t)
+
+	// A transfer created without its entries is unbalanced
+	transfer := createRandomTransfer(t, accountOne, accountTwo)
+
+	rows, err := testQueries.ReconcileTransfers(context.Background(), ReconcileTransfersParams{
+		AfterID: transfer.ID - 1,
+		Limit:   1,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+
+	require.Equal(t, transfer.ID, rows[0].ID)
+	require.Zero(t, rows[0].DebitEntries)
+	require.Zero(t, rows[0].CreditEntries)
+}
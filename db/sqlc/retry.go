@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/lib/pq"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how transactions that fail with a serialization failure or a deadlock are retried
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a transaction is run before giving up, including the first run
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, it doubles after every retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two retries
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of stores created with NewStore
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    500 * time.Millisecond,
+}
+
+// TxStats counts the retries made by a store since it was created
+type TxStats struct {
+	// Retries is the number of times a transaction was run again after a serialization failure or a deadlock
+	Retries uint64 `json:"retries"`
+	// Exhausted is the number of transactions that still failed after using up every attempt
+	Exhausted uint64 `json:"exhausted"`
+}
+
+// isRetryableError returns true if the transaction failed because of a serialization failure or a deadlock,
+// in which case running it again may succeed
+func isRetryableError(err error) bool {
+	var pqErr *pq.Error
+
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	switch pqErr.Code.Name() {
+	case "serialization_failure", "deadlock_detected":
+		return true
+	}
+
+	return false
+}
+
+// backoff returns the delay before the retry following the attempt. The delay grows exponentially and is jittered
+// so that transactions which failed together do not collide again.
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.BaseDelay
+
+	for i := 1; i < attempt && delay < policy.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	// Wait between half and the full delay
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// sleep waits for the delay unless the context is done first
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestRetryStore(maxAttempts int) *SQLStore {
+	store := NewStore(testDB).(*SQLStore)
+	store.retryPolicy = RetryPolicy{
+		MaxAttempts: maxAttempts,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+	}
+
+	return store
+}
+
+func TestIsRetryableError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "SerializationFailure", err: &pq.Error{Code: "40001"}, retryable: true},
+		{name: "DeadlockDetected", err: &pq.Error{Code: "40P01"}, retryable: true},
+		{name: "Wrapped", err: fmt.Errorf("tx err: %w", &pq.Error{Code: "40001"}), retryable: true},
+		{name: "UniqueViolation", err: &pq.Error{Code: "23505"}, retryable: false},
+		{name: "NoRows", err: sql.ErrNoRows, retryable: false},
+		{name: "Nil", err: nil, retryable: false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.retryable, isRetryableError(tc.err))
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 10,
+		BaseDelay:   10 * time.Millisecond,
+		MaxDelay:    40 * time.Millisecond,
+	}
+
+	for attempt, max := range []time.Duration{10, 20, 40, 40, 40} {
+		max *= time.Millisecond
+
+		delay := policy.backoff(attempt + 1)
+		require.GreaterOrEqual(t, int64(delay), int64(max/2))
+		require.LessOrEqual(t, int64(delay), int64(max))
+	}
+}
+
+func TestStore_ExecTxRetry(t *testing.T) {
+	store := newTestRetryStore(5)
+	attempts := 0
+
+	err := store.execTx(context.Background(), nil, func(q *Queries) error {
+		attempts++
+
+		if attempts < 3 {
+			return &pq.Error{Code: "40001"}
+		}
+
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+	require.Equal(t, TxStats{Retries: 2}, store.TxStats())
+}
+
+func TestStore_ExecTxRetryExhausted(t *testing.T) {
+	store := newTestRetryStore(3)
+	attempts := 0
+
+	err := store.execTx(context.Background(), nil, func(q *Queries) error {
+		attempts++
+		return &pq.Error{Code: "40P01"}
+	})
+
+	require.Error(t, err)
+	require.True(t, isRetryableError(err))
+	require.Equal(t, 3, attempts)
+	require.Equal(t, TxStats{Retries: 2, Exhausted: 1}, store.TxStats())
+}
+
+func TestStore_ExecTxNotRetryable(t *testing.T) {
+	store := newTestRetryStore(3)
+	attempts := 0
+
+	err := store.execTx(context.Background(), nil, func(q *Queries) error {
+		attempts++
+		return ErrInsufficientFunds
+	})
+
+	require.True(t, errors.Is(err, ErrInsufficientFunds))
+	require.Equal(t, 1, attempts)
+	require.Equal(t, TxStats{}, store.TxStats())
+}
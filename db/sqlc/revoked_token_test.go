@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomRevokedToken(t *testing.T, user User) RevokedToken {
+	arg := CreateRevokedTokenParams{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	revokedToken, err := testQueries.CreateRevokedToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, revokedToken)
+
+	require.Equal(t, arg.ID, revokedToken.ID)
+	require.Equal(t, arg.Username, revokedToken.Username)
+	require.WithinDuration(t, arg.ExpiresAt, revokedToken.ExpiresAt, time.Second)
+	require.NotZero(t, revokedToken.RevokedAt)
+
+	return revokedToken
+}
+
+func TestQueries_CreateRevokedToken(t *testing.T) {
+	user := createRandomUser(t)
+	revokedToken := createRandomRevokedToken(t, user)
+
+	// Revoking the same token twice is a no-op
+	again, err := testQueries.CreateRevokedToken(context.Background(), CreateRevokedTokenParams{
+		ID:        revokedToken.ID,
+		Username:  revokedToken.Username,
+		ExpiresAt: revokedToken.ExpiresAt,
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, revokedToken.RevokedAt, again.RevokedAt, time.Second)
+}
+
+func TestQueries_IsTokenRevoked(t *testing.T) {
+	user := createRandomUser(t)
+	revokedToken := createRandomRevokedToken(t, user)
+
+	revoked, err := testQueries.IsTokenRevoked(context.Background(), IsTokenRevokedParams{
+		ID:       revokedToken.ID,
+		Username: user.Username,
+		IssuedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	arg := IsTokenRevokedParams{
+		ID:       uuid.New(),
+		Username: user.Username,
+		IssuedAt: time.Now(),
+	}
+
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	// Revoking all the user's tokens revokes the ones issued before
+	_, err = testQueries.RevokeUserTokens(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	revoked, err = testQueries.IsTokenRevoked(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
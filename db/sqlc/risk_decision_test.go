@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func createRandomRiskDecision(t *testing.T, from, to Account, decision string) RiskDecision {
+	arg := CreateRiskDecisionParams{
+		Username:      from.Owner,
+		FromAccountID: int64(from.ID),
+		ToAccountID:   int64(to.ID),
+		Amount:        util.RandomMoney(),
+		Currency:      from.Currency,
+		Decision:      decision,
+		RuleHits:      json.RawMessage(`[{"rule": "round", "type": "round_amount", "decision": "review"}]`),
+	}
+
+	riskDecision, err := testQueries.CreateRiskDecision(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, riskDecision)
+
+	require.Equal(t, arg.Username, riskDecision.Username)
+	require.Equal(t, arg.FromAccountID, riskDecision.FromAccountID)
+	require.Equal(t, arg.ToAccountID, riskDecision.ToAccountID)
+	require.Equal(t, arg.Amount, riskDecision.Amount)
+	require.Equal(t, arg.Currency, riskDecision.Currency)
+	require.Equal(t, arg.Decision, riskDecision.Decision)
+	require.JSONEq(t, string(arg.RuleHits), string(riskDecision.RuleHits))
+
+	require.NotZero(t, riskDecision.ID)
+	require.NotZero(t, riskDecision.CreatedAt)
+
+	return riskDecision
+}
+
+func TestQueries_CreateRiskDecision(t *testing.T) {
+	createRandomRiskDecision(t, createRandomAccount(t), createRandomAccount(t), util.RiskReview)
+}
+
+func TestQueries_GetRiskDecision(t *testing.T) {
+	riskDecision := createRandomRiskDecision(t, createRandomAccount(t), createRandomAccount(t), util.RiskDeny)
+
+	got, err := testQueries.GetRiskDecision(context.Background(), riskDecision.ID)
+	require.NoError(t, err)
+	require.Equal(t, riskDecision.ID, got.ID)
+	require.Equal(t, riskDecision.Decision, got.Decision)
+	require.JSONEq(t, string(riskDecision.RuleHits), string(got.RuleHits))
+}
+
+func TestQueries_ListRiskDecisions(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccount(t)
+
+	for i := 0; i < 2; i++ {
+		createRandomRiskDecision(t, from, to, util.RiskReview)
+	}
+
+	denied := createRandomRiskDecision(t, from, to, util.RiskDeny)
+
+	arg := ListRiskDecisionsParams{
+		Username: from.Owner,
+		Limit:    10,
+	}
+
+	decisions, err := testQueries.ListRiskDecisions(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, decisions, 3)
+
+	// The latest decisions come first
+	require.Equal(t, denied.ID, decisions[0].ID)
+
+	arg.Decision = util.RiskDeny
+
+	decisions, err = testQueries.ListRiskDecisions(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+	require.Equal(t, denied.ID, decisions[0].ID)
+}
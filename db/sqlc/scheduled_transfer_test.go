@@ -0,0 +1,300 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomScheduledTransfer(t *testing.T, a, b Account, executeAt time.Time) ScheduledTransfer {
+	arg := CreateScheduledTransferParams{
+		FromAccountID: int64(a.ID),
+		ToAccountID:   int64(b.ID),
+		Amount:        util.RandomMoney(),
+		ExecuteAt:     executeAt,
+	}
+
+	scheduledTransfer, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, scheduledTransfer)
+
+	require.Equal(t, arg.FromAccountID, scheduledTransfer.FromAccountID)
+	require.Equal(t, arg.ToAccountID, scheduledTransfer.ToAccountID)
+	require.Equal(t, arg.Amount, scheduledTransfer.Amount)
+	require.Equal(t, util.ScheduledTransferPending, scheduledTransfer.Status)
+	require.WithinDuration(t, arg.ExecuteAt, scheduledTransfer.ExecuteAt, time.Second)
+	require.WithinDuration(t, arg.ExecuteAt, scheduledTransfer.NextAttemptAt, time.Second)
+	require.Zero(t, scheduledTransfer.AttemptCount)
+	require.False(t, scheduledTransfer.TransferID.Valid)
+
+	require.NotZero(t, scheduledTransfer.ID)
+	require.NotZero(t, scheduledTransfer.CreatedAt)
+
+	return scheduledTransfer
+}
+
+// claimScheduledTransfer claims the due scheduled transfers, like the worker does, and returns the claimed copy of the
+// given one
+func claimScheduledTransfer(t *testing.T, scheduledTransfer ScheduledTransfer, staleBefore time.Time) ScheduledTransfer {
+	claimed, err := testQueries.ClaimDueScheduledTransfers(context.Background(), ClaimDueScheduledTransfersParams{
+		StaleBefore: sql.NullTime{Time: staleBefore, Valid: true},
+		Limit:       1000,
+	})
+	require.NoError(t, err)
+
+	for _, claimedTransfer := range claimed {
+		if claimedTransfer.ID == scheduledTransfer.ID {
+			require.Equal(t, util.ScheduledTransferProcessing, claimedTransfer.Status)
+			require.True(t, claimedTransfer.ClaimedAt.Valid)
+
+			return claimedTransfer
+		}
+	}
+
+	t.Fatalf("scheduled transfer [%d] was not claimed", scheduledTransfer.ID)
+	return ScheduledTransfer{}
+}
+
+func TestQueries_CreateScheduledTransfer(t *testing.T) {
+	a := createRandomAccount(t)
+	b := createRandomAccount(t)
+
+	createRandomScheduledTransfer(t, a, b, time.Now().Add(time.Hour))
+}
+
+func TestQueries_CancelScheduledTransfer(t *testing.T) {
+	a := createRandomAccount(t)
+	b := createRandomAccount(t)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, a, b, time.Now().Add(time.Hour))
+
+	cancelled, err := testQueries.CancelScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.ScheduledTransferCancelled, cancelled.Status)
+
+	// Only pending transfers can be cancelled
+	_, err = testQueries.CancelScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestQueries_ClaimDueScheduledTransfers(t *testing.T) {
+	a := createRandomAccount(t)
+	b := createRandomAccount(t)
+
+	due := createRandomScheduledTransfer(t, a, b, time.Now().Add(-time.Minute))
+	notDue := createRandomScheduledTransfer(t, a, b, time.Now().Add(time.Hour))
+
+	arg := ClaimDueScheduledTransfersParams{
+		StaleBefore: sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true},
+		Limit:       1000,
+	}
+
+	claimed, err := testQueries.ClaimDueScheduledTransfers(context.Background(), arg)
+	require.NoError(t, err)
+
+	var found bool
+
+	for _, scheduledTransfer := range claimed {
+		require.NotEqual(t, notDue.ID, scheduledTransfer.ID)
+
+		if scheduledTransfer.ID == due.ID {
+			found = true
+
+			require.Equal(t, util.ScheduledTransferProcessing, scheduledTransfer.Status)
+			require.Equal(t, int32(1), scheduledTransfer.AttemptCount)
+		}
+	}
+
+	require.True(t, found)
+
+	// A claimed transfer is not handed out again until its claim times out
+	claimed, err = testQueries.ClaimDueScheduledTransfers(context.Background(), arg)
+	require.NoError(t, err)
+
+	for _, scheduledTransfer := range claimed {
+		require.NotEqual(t, due.ID, scheduledTransfer.ID)
+	}
+}
+
+func TestStore_ExecuteScheduledTransferTxStaleClaim(t *testing.T) {
+	store := NewStore(testDB)
+
+	a := createRandomAccountWithBalance(t, 1000)
+	b := createRandomAccountWithCurrency(t, 0, a.Currency)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, a, b, time.Now().Add(-time.Minute))
+	stale := claimScheduledTransfer(t, scheduledTransfer, time.Now().Add(-time.Hour))
+	require.Equal(t, int32(1), stale.AttemptCount)
+
+	// The worker holding the claim stopped, the transfer is left processing until the claim times out
+	reclaimed := claimScheduledTransfer(t, scheduledTransfer, time.Now().Add(time.Second))
+	require.Equal(t, int32(2), reclaimed.AttemptCount)
+
+	// The worker whose claim timed out can neither make the transfer nor record its attempt
+	_, err := store.ExecuteScheduledTransferTx(context.Background(), stale)
+	require.ErrorIs(t, err, ErrScheduledTransferNotClaimed)
+
+	_, err = store.RecordScheduledTransferAttemptTx(context.Background(), RecordScheduledTransferAttemptTxParams{
+		ScheduledTransferID: stale.ID,
+		AttemptCount:        stale.AttemptCount,
+		Status:              util.ScheduledTransferFailed,
+		Error:               ErrInsufficientFunds.Error(),
+		NextAttemptAt:       stale.NextAttemptAt,
+	})
+	require.ErrorIs(t, err, ErrScheduledTransferNotClaimed)
+
+	result, err := store.ExecuteScheduledTransferTx(context.Background(), reclaimed)
+	require.NoError(t, err)
+
+	attempts, err := testQueries.ListScheduledTransferAttempts(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	require.Equal(t, result.Transfer.ID, attempts[0].TransferID.Int64)
+}
+
+func TestQueries_CancelScheduledTransferProcessing(t *testing.T) {
+	a := createRandomAccount(t)
+	b := createRandomAccount(t)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, a, b, time.Now().Add(-time.Minute))
+	claimed := claimScheduledTransfer(t, scheduledTransfer, time.Now().Add(-time.Hour))
+
+	// A transfer stuck processing can still be cancelled, the worker then finds it is no longer claimed
+	cancelled, err := testQueries.CancelScheduledTransfer(context.Background(), claimed.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.ScheduledTransferCancelled, cancelled.Status)
+
+	_, err = NewStore(testDB).ExecuteScheduledTransferTx(context.Background(), claimed)
+	require.ErrorIs(t, err, ErrScheduledTransferNotClaimed)
+}
+
+func TestQueries_ListOwnerScheduledTransfers(t *testing.T) {
+	a := createRandomAccount(t)
+	b := createRandomAccount(t)
+
+	first := createRandomScheduledTransfer(t, a, b, time.Now().Add(time.Hour))
+	second := createRandomScheduledTransfer(t, a, b, time.Now().Add(time.Hour))
+	createRandomScheduledTransfer(t, b, a, time.Now().Add(time.Hour))
+
+	arg := ListOwnerScheduledTransfersParams{
+		Owner:  a.Owner,
+		Limit:  10,
+		Offset: 0,
+	}
+
+	scheduledTransfers, err := testQueries.ListOwnerScheduledTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, scheduledTransfers, 2)
+	require.Equal(t, second.ID, scheduledTransfers[0].ID)
+	require.Equal(t, first.ID, scheduledTransfers[1].ID)
+}
+
+func TestStore_RecordScheduledTransferAttemptTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	a := createRandomAccount(t)
+	b := createRandomAccount(t)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, a, b, time.Now().Add(-time.Minute))
+	scheduledTransfer = claimScheduledTransfer(t, scheduledTransfer, time.Now().Add(-time.Hour))
+	transfer := createRandomTransfer(t, a, b)
+
+	retryAt := time.Now().Add(time.Minute)
+
+	updated, err := store.RecordScheduledTransferAttemptTx(context.Background(), RecordScheduledTransferAttemptTxParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		AttemptCount:        scheduledTransfer.AttemptCount,
+		Status:              util.ScheduledTransferPending,
+		Error:               ErrInsufficientFunds.Error(),
+		NextAttemptAt:       retryAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.ScheduledTransferPending, updated.Status)
+	require.WithinDuration(t, retryAt, updated.NextAttemptAt, time.Second)
+
+	// The transfer must be claimed again before the next attempt is recorded
+	_, err = store.RecordScheduledTransferAttemptTx(context.Background(), RecordScheduledTransferAttemptTxParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		AttemptCount:        scheduledTransfer.AttemptCount,
+		Status:              util.ScheduledTransferCompleted,
+		TransferID:          sql.NullInt64{Int64: transfer.ID, Valid: true},
+		NextAttemptAt:       retryAt,
+	})
+	require.ErrorIs(t, err, ErrScheduledTransferNotClaimed)
+
+	updated, err = testQueries.UpdateScheduledTransferResult(context.Background(), UpdateScheduledTransferResultParams{
+		ID:            scheduledTransfer.ID,
+		Status:        util.ScheduledTransferPending,
+		NextAttemptAt: time.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+
+	scheduledTransfer = claimScheduledTransfer(t, scheduledTransfer, time.Now().Add(-time.Hour))
+
+	updated, err = store.RecordScheduledTransferAttemptTx(context.Background(), RecordScheduledTransferAttemptTxParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		AttemptCount:        scheduledTransfer.AttemptCount,
+		Status:              util.ScheduledTransferCompleted,
+		TransferID:          sql.NullInt64{Int64: transfer.ID, Valid: true},
+		NextAttemptAt:       retryAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.ScheduledTransferCompleted, updated.Status)
+	require.Equal(t, transfer.ID, updated.TransferID.Int64)
+
+	attempts, err := testQueries.ListScheduledTransferAttempts(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+
+	require.Equal(t, ErrInsufficientFunds.Error(), attempts[0].Error)
+	require.False(t, attempts[0].TransferID.Valid)
+
+	require.Empty(t, attempts[1].Error)
+	require.Equal(t, transfer.ID, attempts[1].TransferID.Int64)
+}
+
+func TestStore_ExecuteScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	a := createRandomAccountWithBalance(t, 1000)
+	b := createRandomAccountWithCurrency(t, 0, a.Currency)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, a, b, time.Now().Add(-time.Minute))
+	scheduledTransfer = claimScheduledTransfer(t, scheduledTransfer, time.Now().Add(-time.Hour))
+
+	result, err := store.ExecuteScheduledTransferTx(context.Background(), scheduledTransfer)
+	require.NoError(t, err)
+	require.Equal(t, scheduledTransfer.Amount, result.Transfer.Amount)
+
+	// The attempt is recorded with the transfer
+	updated, err := testQueries.GetScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.ScheduledTransferCompleted, updated.Status)
+	require.Equal(t, result.Transfer.ID, updated.TransferID.Int64)
+
+	attempts, err := testQueries.ListScheduledTransferAttempts(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	require.Equal(t, result.Transfer.ID, attempts[0].TransferID.Int64)
+}
+
+func TestStore_ExecuteScheduledTransferTxFailed(t *testing.T) {
+	store := NewStore(testDB)
+
+	a := createRandomAccountWithBalance(t, 0)
+	b := createRandomAccountWithCurrency(t, 0, a.Currency)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, a, b, time.Now().Add(-time.Minute))
+	scheduledTransfer = claimScheduledTransfer(t, scheduledTransfer, time.Now().Add(-time.Hour))
+
+	_, err := store.ExecuteScheduledTransferTx(context.Background(), scheduledTransfer)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// Nothing is recorded, the caller records the failed attempt
+	attempts, err := testQueries.ListScheduledTransferAttempts(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Empty(t, attempts)
+}
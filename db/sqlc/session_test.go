@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomSession(t *testing.T) Session {
+	user := createRandomUser(t)
+
+	arg := CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		IsBlocked:    false,
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, session)
+
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.Username, session.Username)
+	require.Equal(t, arg.RefreshToken, session.RefreshToken)
+	require.Equal(t, arg.UserAgent, session.UserAgent)
+	require.Equal(t, arg.ClientIp, session.ClientIp)
+	require.False(t, session.IsBlocked)
+	require.WithinDuration(t, arg.ExpiresAt, session.ExpiresAt, time.Second)
+	require.NotZero(t, session.CreatedAt)
+
+	return session
+}
+
+func TestQueries_CreateSession(t *testing.T) {
+	createRandomSession(t)
+}
+
+func TestQueries_GetSession(t *testing.T) {
+	expected := createRandomSession(t)
+
+	actual, err := testQueries.GetSession(context.Background(), expected.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, actual)
+
+	require.Equal(t, expected.ID, actual.ID)
+	require.Equal(t, expected.Username, actual.Username)
+	require.Equal(t, expected.RefreshToken, actual.RefreshToken)
+	require.Equal(t, expected.IsBlocked, actual.IsBlocked)
+	require.WithinDuration(t, expected.ExpiresAt, actual.ExpiresAt, time.Second)
+}
+
+func TestQueries_BlockSession(t *testing.T) {
+	session := createRandomSession(t)
+
+	blocked, err := testQueries.BlockSession(context.Background(), session.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, session.ID, blocked.ID)
+	require.True(t, blocked.IsBlocked)
+}
+
+func TestQueries_BlockUserSessions(t *testing.T) {
+	session := createRandomSession(t)
+
+	err := testQueries.BlockUserSessions(context.Background(), session.Username)
+	require.NoError(t, err)
+
+	blocked, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+}
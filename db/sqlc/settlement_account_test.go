@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestQueries_GetSettlementAccount(t *testing.T) {
+	for _, currency := range []string{util.USD, util.EUR, util.CAD} {
+		account, err := testQueries.GetSettlementAccount(context.Background(), currency)
+		require.NoError(t, err)
+		require.Equal(t, currency, account.Currency)
+	}
+}
+
+func TestQueries_ListSettlementAccounts(t *testing.T) {
+	accounts, err := testQueries.ListSettlementAccounts(context.Background())
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+
+	// There is a single settlement account per currency, owned by the bank
+	for _, account := range accounts {
+		require.Equal(t, accounts[0].Owner, account.Owner)
+	}
+}
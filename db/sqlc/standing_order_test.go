@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomStandingOrder(t *testing.T, a, b Account, nextRunAt time.Time, maxRuns int32) StandingOrder {
+	arg := CreateStandingOrderParams{
+		FromAccountID: int64(a.ID),
+		ToAccountID:   int64(b.ID),
+		Amount:        util.RandomMoney(),
+		Frequency:     util.WeeklyFrequency,
+		NextRunAt:     nextRunAt,
+		MaxRuns:       sql.NullInt32{Int32: maxRuns, Valid: maxRuns != 0},
+	}
+
+	order, err := testQueries.CreateStandingOrder(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, order)
+
+	require.Equal(t, arg.FromAccountID, order.FromAccountID)
+	require.Equal(t, arg.ToAccountID, order.ToAccountID)
+	require.Equal(t, arg.Amount, order.Amount)
+	require.Equal(t, arg.Frequency, order.Frequency)
+	require.Equal(t, arg.MaxRuns, order.MaxRuns)
+	require.Equal(t, util.StandingOrderActive, order.Status)
+	require.WithinDuration(t, arg.NextRunAt, order.NextRunAt, time.Second)
+	require.False(t, order.EndAt.Valid)
+	require.Zero(t, order.RunCount)
+
+	require.NotZero(t, order.ID)
+	require.NotZero(t, order.CreatedAt)
+
+	return order
+}
+
+func TestQueries_CreateStandingOrder(t *testing.T) {
+	a := createRandomAccount(t)
+	b := createRandomAccount(t)
+
+	createRandomStandingOrder(t, a, b, time.Now().Add(time.Hour), 0)
+}
+
+func TestQueries_PauseAndResumeStandingOrder(t *testing.T) {
+	a := createRandomAccount(t)
+	b := createRandomAccount(t)
+
+	order := createRandomStandingOrder(t, a, b, time.Now().Add(time.Hour), 0)
+
+	paused, err := testQueries.PauseStandingOrder(context.Background(), order.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.StandingOrderPaused, paused.Status)
+
+	_, err = testQueries.PauseStandingOrder(context.Background(), order.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	nextRunAt := time.Now().Add(2 * time.Hour)
+
+	resumed, err := testQueries.ResumeStandingOrder(context.Background(), ResumeStandingOrderParams{
+		ID:        order.ID,
+		NextRunAt: nextRunAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.StandingOrderActive, resumed.Status)
+	require.WithinDuration(t, nextRunAt, resumed.NextRunAt, time.Second)
+}
+
+func TestStore_GenerateStandingOrderTransfersTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	a := createRandomAccount(t)
+	b := createRandomAccount(t)
+
+	lastRun := createRandomStandingOrder(t, a, b, time.Now().Add(-time.Minute), 1)
+	recurring := createRandomStandingOrder(t, a, b, time.Now().Add(-time.Minute), 0)
+	notDue := createRandomStandingOrder(t, a, b, time.Now().Add(time.Hour), 0)
+
+	scheduledTransfers, err := store.GenerateStandingOrderTransfersTx(context.Background(), 1000)
+	require.NoError(t, err)
+
+	generated := make(map[int64]ScheduledTransfer)
+
+	for _, scheduledTransfer := range scheduledTransfers {
+		generated[scheduledTransfer.StandingOrderID.Int64] = scheduledTransfer
+	}
+
+	require.Contains(t, generated, lastRun.ID)
+	require.Contains(t, generated, recurring.ID)
+	require.NotContains(t, generated, notDue.ID)
+
+	scheduledTransfer := generated[recurring.ID]
+	require.Equal(t, recurring.Amount, scheduledTransfer.Amount)
+	require.Equal(t, util.ScheduledTransferPending, scheduledTransfer.Status)
+	require.WithinDuration(t, recurring.NextRunAt, scheduledTransfer.ExecuteAt, time.Second)
+
+	// The order with a single run completes, the other moves to the next week
+	order, err := testQueries.GetStandingOrder(context.Background(), lastRun.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.StandingOrderCompleted, order.Status)
+	require.Equal(t, int32(1), order.RunCount)
+
+	order, err = testQueries.GetStandingOrder(context.Background(), recurring.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.StandingOrderActive, order.Status)
+	require.Equal(t, int32(1), order.RunCount)
+	require.WithinDuration(t, recurring.NextRunAt.AddDate(0, 0, 7), order.NextRunAt, time.Second)
+
+	// Cancelling the order cancels its transfer that has not been made yet
+	order, err = store.CancelStandingOrderTx(context.Background(), recurring.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.StandingOrderCancelled, order.Status)
+
+	scheduledTransfer, err = testQueries.GetScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.ScheduledTransferCancelled, scheduledTransfer.Status)
+
+	_, err = store.CancelStandingOrderTx(context.Background(), recurring.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
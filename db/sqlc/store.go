@@ -3,26 +3,155 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"math/big"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrInsufficientFunds is returned by TransferTx when the sender's balance, including its overdraft limit,
+	// cannot cover the amount being transferred
+	ErrInsufficientFunds = errors.New("insufficient funds")
+
+	// ErrIdempotencyKeyReused is returned by IdempotentTransferTx when the idempotency key was already used for a
+	// different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+
+	// ErrExchangeRateNotFound is returned by TransferTx when the accounts hold different currencies and there is no
+	// exchange rate between them
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+
+	// ErrConvertedAmountTooSmall is returned by TransferTx when the converted amount rounds down to zero
+	ErrConvertedAmountTooSmall = errors.New("converted amount is too small")
+
+	// ErrTransferAlreadyReversed is returned by ReverseTransferTx when the transfer was reversed before
+	ErrTransferAlreadyReversed = errors.New("transfer has already been reversed")
+
+	// ErrCannotReverseReversal is returned by ReverseTransferTx when the transfer is itself a reversal
+	ErrCannotReverseReversal = errors.New("a reversal cannot be reversed")
+
+	// ErrTransferLimitExceeded is wrapped by the TransferLimitError returned when a transfer exceeds a limit of the
+	// sender
+	ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+
+	// ErrMoneyRequestNotPending is returned by AcceptMoneyRequestTx when the request was already closed
+	ErrMoneyRequestNotPending = errors.New("money request is not pending")
+
+	// ErrMoneyRequestExpired is returned by AcceptMoneyRequestTx when the request expired before it was accepted
+	ErrMoneyRequestExpired = errors.New("money request has expired")
+
+	// ErrSettlementAccountNotFound is returned by DepositTx and WithdrawTx when the bank has no settlement account in
+	// the currency of the account
+	ErrSettlementAccountNotFound = errors.New("settlement account not found")
+
+	// ErrCashOnSettlementAccount is returned by DepositTx and WithdrawTx when the account is a settlement account
+	ErrCashOnSettlementAccount = errors.New("cannot deposit into or withdraw from a settlement account")
+
+	// ErrCashOnOwnAccount is returned by DepositTx and by the transfer approval transactions when a staff member
+	// deposits cash into, or approves a deposit into, an account they own
+	ErrCashOnOwnAccount = errors.New("cannot deposit cash into an own account")
+
+	// ErrTransferApprovalNotPending is returned by ApproveTransferTx and RejectTransferTx when the approval was
+	// already decided or expired
+	ErrTransferApprovalNotPending = errors.New("transfer approval is not pending")
+
+	// ErrTransferApprovalExpired is returned by ApproveTransferTx when the approval expired before it was approved
+	ErrTransferApprovalExpired = errors.New("transfer approval has expired")
+
+	// ErrApproverIsRequester is returned by ApproveTransferTx and RejectTransferTx when the user deciding is the one who
+	// requested the transfer
+	ErrApproverIsRequester = errors.New("a transfer cannot be decided by the user who requested it")
+
+	// ErrApproverNotCoOwner is returned by ApproveTransferTx and RejectTransferTx when the user deciding may only decide
+	// the transfers of the accounts they co-own and does not co-own the sender account
+	ErrApproverNotCoOwner = errors.New("a transfer can only be decided by a co-owner of the account or an approver")
+
+	// ErrTransferNotPending is returned by PostTransferTx and VoidTransferTx when the transfer was already posted or
+	// voided
+	ErrTransferNotPending = errors.New("transfer is not pending")
+
+	// ErrTransferHoldExpired is returned by PostTransferTx when the hold of the transfer expired before it was posted
+	ErrTransferHoldExpired = errors.New("transfer hold has expired")
+
+	// ErrPostAmountExceedsHold is returned by PostTransferTx when the amount posted is more than the amount held
+	ErrPostAmountExceedsHold = errors.New("amount posted exceeds the amount held")
+
+	// ErrTransferNotPosted is returned by ReverseTransferTx when the transfer never moved any money
+	ErrTransferNotPosted = errors.New("transfer is not posted")
+
+	// ErrUnbalancedJournal is returned when the postings of a journal do not sum to zero in each currency
+	ErrUnbalancedJournal = errors.New("journal does not balance")
+
+	// ErrFxAccountNotFound is returned by TransferTx when the accounts hold different currencies and the bank has no
+	// FX account in one of them
+	ErrFxAccountNotFound = errors.New("fx account not found")
+
+	// ErrScheduledTransferNotClaimed is returned by ExecuteScheduledTransferTx and RecordScheduledTransferAttemptTx when
+	// the claim of the worker timed out and the transfer was claimed again or cancelled in the meantime
+	ErrScheduledTransferNotClaimed = errors.New("scheduled transfer is no longer claimed")
+
+	errIdempotencyKeyExists = errors.New("idempotency key already exists")
 )
 
 // Store defines all functions to execute db queries and transactions
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	IdempotentTransferTx(ctx context.Context, arg IdempotentTransferTxParams) (TransferTxResult, error)
+	ReplayTransfer(ctx context.Context, arg IdempotentTransferTxParams) (TransferTxResult, error)
+	RevokeUserTokensTx(ctx context.Context, username string) (User, error)
+	UpdateUserRoleTx(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	AccountStatementTx(ctx context.Context, arg AccountStatementTxParams) (AccountStatementTxResult, error)
+	RecordScheduledTransferAttemptTx(ctx context.Context, arg RecordScheduledTransferAttemptTxParams) (
+		ScheduledTransfer, error)
+	ExecuteScheduledTransferTx(ctx context.Context, scheduledTransfer ScheduledTransfer) (TransferTxResult, error)
+	GenerateStandingOrderTransfersTx(ctx context.Context, limit int32) ([]ScheduledTransfer, error)
+	CancelStandingOrderTx(ctx context.Context, id int64) (StandingOrder, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	BulkTransferTx(ctx context.Context, arg BulkTransferTxParams) (BulkTransferTxResult, error)
+	AcceptMoneyRequestTx(ctx context.Context, arg AcceptMoneyRequestTxParams) (AcceptMoneyRequestTxResult, error)
+	DepositTx(ctx context.Context, arg CashTxParams) (TransferTxResult, error)
+	WithdrawTx(ctx context.Context, arg CashTxParams) (TransferTxResult, error)
+	RequestTransferApprovalTx(ctx context.Context, arg RequestTransferApprovalTxParams) (
+		RequestTransferApprovalTxResult, error)
+	ReplayTransferApproval(ctx context.Context, arg RequestTransferApprovalTxParams) (
+		RequestTransferApprovalTxResult, error)
+	ApproveTransferTx(ctx context.Context, arg DecideTransferTxParams) (ApproveTransferTxResult, error)
+	RejectTransferTx(ctx context.Context, arg DecideTransferTxParams) (TransferApproval, error)
+	ExpireTransferApprovalsTx(ctx context.Context) error
+	CreatePendingTransferTx(ctx context.Context, arg PendingTransferTxParams) (PendingTransferTxResult, error)
+	PostTransferTx(ctx context.Context, arg PostTransferTxParams) (TransferTxResult, error)
+	VoidTransferTx(ctx context.Context, id int64) (PendingTransferTxResult, error)
+	ExpirePendingTransfersTx(ctx context.Context) error
+	TxStats() TxStats
 }
 
 // SQLStore provides all functions to execute SQL queries and transactions
 type SQLStore struct {
+	// The counters are accessed atomically and come first to keep them 64-bit aligned
+	retries          uint64
+	exhaustedRetries uint64
+
 	*Queries
-	db *sql.DB
+	db          *sql.DB
+	retryPolicy RetryPolicy
 }
 
-// TransferTxParams contains the input parameters of the transfer transaction
+// TransferTxParams contains the input parameters of the transfer transaction, the memo, the reference and the
+// metadata are optional and stored on the transfer as they are
 type TransferTxParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64           `json:"from_account_id"`
+	ToAccountID   int64           `json:"to_account_id"`
+	Amount        int64           `json:"amount"`
+	Memo          string          `json:"memo"`
+	Reference     string          `json:"reference"`
+	Metadata      json.RawMessage `json:"metadata"`
 }
 
 // TransferTxResult is the result of the transfer transaction
@@ -32,6 +161,204 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// Fee is what the sender was charged on top of the amount, the sender account balance includes it
+	Fee FeeBreakdown `json:"fee"`
+}
+
+// IdempotentTransferTxParams contains the input parameters of the idempotent transfer transaction
+type IdempotentTransferTxParams struct {
+	TransferTxParams
+	Username       string `json:"username"`
+	IdempotencyKey string `json:"idempotency_key"`
+	RequestHash    string `json:"request_hash"`
+}
+
+// AccountStatementTxParams contains the input parameters of the account statement transaction
+type AccountStatementTxParams struct {
+	AccountID int64     `json:"account_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+// StatementLine is an entry of an account statement with the balance of the account right after it
+type StatementLine struct {
+	Entry
+	Balance int64 `json:"balance"`
+}
+
+// AccountStatementTxResult is the result of the account statement transaction
+type AccountStatementTxResult struct {
+	Account        Account         `json:"account"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance int64           `json:"opening_balance"`
+	ClosingBalance int64           `json:"closing_balance"`
+	Lines          []StatementLine `json:"lines"`
+}
+
+// ReverseTransferTxParams contains the input parameters of the reverse transfer transaction
+type ReverseTransferTxParams struct {
+	TransferID int64  `json:"transfer_id"`
+	Reason     string `json:"reason"`
+	ReversedBy string `json:"reversed_by"`
+}
+
+// ReverseTransferTxResult is the result of the reverse transfer transaction, the embedded transfer moves the money
+// back
+type ReverseTransferTxResult struct {
+	TransferTxResult
+	Reversal TransferReversal `json:"reversal"`
+	// FeeRefund moves the fee charged on the original transfer back to the sender, its ID is zero if no fee was charged
+	FeeRefund Transfer `json:"fee_refund"`
+}
+
+// BulkTransferLeg is a single payment of a bulk transfer, the amount is in the sender's currency
+type BulkTransferLeg struct {
+	ToAccountID int64 `json:"to_account_id"`
+	Amount      int64 `json:"amount"`
+}
+
+// BulkTransferTxParams contains the input parameters of the bulk transfer transaction
+type BulkTransferTxParams struct {
+	FromAccountID int64             `json:"from_account_id"`
+	Legs          []BulkTransferLeg `json:"legs"`
+}
+
+// BulkTransferLegResult is the result of a single leg of the bulk transfer transaction
+type BulkTransferLegResult struct {
+	Transfer  Transfer     `json:"transfer"`
+	FromEntry Entry        `json:"from_entry"`
+	ToEntry   Entry        `json:"to_entry"`
+	Fee       FeeBreakdown `json:"fee"`
+}
+
+// BulkTransferTxResult is the result of the bulk transfer transaction, the legs are in the order of the request
+type BulkTransferTxResult struct {
+	Batch       TransferBatch           `json:"batch"`
+	FromAccount Account                 `json:"from_account"`
+	Legs        []BulkTransferLegResult `json:"legs"`
+}
+
+// LegError is returned by BulkTransferTx when one of the legs cannot be executed
+type LegError struct {
+	Index int
+	Err   error
+}
+
+func (e *LegError) Error() string {
+	return fmt.Sprintf("leg %d: %v", e.Index, e.Err)
+}
+
+func (e *LegError) Unwrap() error {
+	return e.Err
+}
+
+// AcceptMoneyRequestTxParams contains the input parameters of the accept money request transaction, the accounts of
+// the payer and the requester must hold the currency of the request
+type AcceptMoneyRequestTxParams struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+}
+
+// AcceptMoneyRequestTxResult is the result of the accept money request transaction
+type AcceptMoneyRequestTxResult struct {
+	TransferTxResult
+	MoneyRequest MoneyRequest `json:"money_request"`
+}
+
+// CashTxParams contains the input parameters of the deposit and withdrawal transactions, the amount is in the
+// currency of the account
+type CashTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Amount    int64  `json:"amount"`
+	Memo      string `json:"memo"`
+	// PostedBy is the username of the staff member handling the cash, it is kept in the metadata of the transfer
+	PostedBy string `json:"posted_by"`
+}
+
+// RequestTransferApprovalTxParams contains the input parameters of the request transfer approval transaction. The
+// idempotency key and the request hash are optional, a retry with the same key gets the first approval back.
+type RequestTransferApprovalTxParams struct {
+	TransferTxParams
+	RequestedBy    string    `json:"requested_by"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	RequestHash    string    `json:"request_hash"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// RequestTransferApprovalTxResult is the result of the request transfer approval transaction, the sender account
+// holds the amount of the approval
+type RequestTransferApprovalTxResult struct {
+	Approval    TransferApproval `json:"approval"`
+	FromAccount Account          `json:"from_account"`
+}
+
+// DecideTransferTxParams contains the input parameters of the approve and reject transfer transactions, the reason
+// is only kept for rejections
+type DecideTransferTxParams struct {
+	ID        int64  `json:"id"`
+	DecidedBy string `json:"decided_by"`
+	Reason    string `json:"reason"`
+	// CanDecideAny is true if the user may decide the transfers of any account, otherwise they must co-own the sender
+	// account
+	CanDecideAny bool `json:"can_decide_any"`
+}
+
+// ApproveTransferTxResult is the result of the approve transfer transaction
+type ApproveTransferTxResult struct {
+	TransferTxResult
+	Approval TransferApproval `json:"approval"`
+}
+
+// PendingTransferTxParams contains the input parameters of the create pending transfer transaction, the amount is
+// held on the sender account until the transfer is posted, voided or expires
+type PendingTransferTxParams struct {
+	TransferTxParams
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PendingTransferTxResult is the result of the create pending transfer and void transfer transactions
+type PendingTransferTxResult struct {
+	Transfer    Transfer     `json:"transfer"`
+	Hold        TransferHold `json:"hold"`
+	FromAccount Account      `json:"from_account"`
+}
+
+// PostTransferTxParams contains the input parameters of the post transfer transaction, the amount may be lower than
+// the amount held and defaults to it when zero
+type PostTransferTxParams struct {
+	ID     int64 `json:"id"`
+	Amount int64 `json:"amount"`
+}
+
+// TransferLimitError is returned when a transfer exceeds one of the limits of the sender, it tells how much the
+// sender can still transfer within the limit
+type TransferLimitError struct {
+	Period    string
+	Currency  string
+	Limit     int64
+	Remaining int64
+}
+
+func (e *TransferLimitError) Error() string {
+	return fmt.Sprintf("%s transfer limit of %d %s exceeded, remaining allowance is %d %s", e.Period, e.Limit,
+		e.Currency, e.Remaining, e.Currency)
+}
+
+func (e *TransferLimitError) Unwrap() error {
+	return ErrTransferLimitExceeded
+}
+
+// RecordScheduledTransferAttemptTxParams contains the input parameters of the record scheduled transfer attempt
+// transaction
+type RecordScheduledTransferAttemptTxParams struct {
+	ScheduledTransferID int64         `json:"scheduled_transfer_id"`
+	AttemptCount        int32         `json:"attempt_count"`
+	Status              string        `json:"status"`
+	TransferID          sql.NullInt64 `json:"transfer_id"`
+	Error               string        `json:"error"`
+	NextAttemptAt       time.Time     `json:"next_attempt_at"`
 }
 
 var txKey = struct{}{}
@@ -39,14 +366,47 @@ var txKey = struct{}{}
 // NewStore creates a new store
 func NewStore(db *sql.DB) Store {
 	return &SQLStore{
-		db:      db,
-		Queries: New(db),
+		db:          db,
+		Queries:     New(db),
+		retryPolicy: DefaultRetryPolicy,
+	}
+}
+
+// TxStats returns the number of transaction retries made by the store
+func (store *SQLStore) TxStats() TxStats {
+	return TxStats{
+		Retries:   atomic.LoadUint64(&store.retries),
+		Exhausted: atomic.LoadUint64(&store.exhaustedRetries),
+	}
+}
+
+// execTx executes a function within a database transaction started with the options, which may be nil to use the
+// defaults. The whole transaction is run again if it fails with a serialization failure or a deadlock, so fn must
+// not keep any state from a previous attempt.
+func (store *SQLStore) execTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	for attempt := 1; ; attempt++ {
+		err := store.runTx(ctx, opts, fn)
+
+		if err == nil || !isRetryableError(err) {
+			return err
+		}
+
+		if attempt >= store.retryPolicy.MaxAttempts {
+			atomic.AddUint64(&store.exhaustedRetries, 1)
+			return fmt.Errorf("tx failed after %d attempts: %w", attempt, err)
+		}
+
+		if sleepErr := sleep(ctx, store.retryPolicy.backoff(attempt)); sleepErr != nil {
+			return err
+		}
+
+		atomic.AddUint64(&store.retries, 1)
 	}
 }
 
-// execTx executes a function within a database transaction
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+// runTx executes a function within a single database transaction
+func (store *SQLStore) runTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 
 	if err != nil {
 		return err
@@ -56,7 +416,7 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 
 	if err := fn(q); err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			return fmt.Errorf("tx err: %v, rollback err: %v", err, rollbackErr)
+			return fmt.Errorf("tx err: %w, rollback err: %v", err, rollbackErr)
 		}
 
 		return err
@@ -65,77 +425,1684 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	return tx.Commit()
 }
 
-func addMoney(ctx context.Context, q *Queries, fromAccountID, fromAmount, toAccountID, toAmount int64) (
-	from, to Account, err error) {
+// lockAccounts locks both accounts of a transfer for update. The rows are always locked in the order of their IDs
+// so that concurrent transfers between the same accounts cannot deadlock.
+func lockAccounts(ctx context.Context, q *Queries, fromAccountID, toAccountID int64) (from, to Account, err error) {
+	if fromAccountID < toAccountID {
+		if from, err = q.GetAccountForUpdate(ctx, int32(fromAccountID)); err != nil {
+			return
+		}
+
+		to, err = q.GetAccountForUpdate(ctx, int32(toAccountID))
+		return
+	}
+
+	if to, err = q.GetAccountForUpdate(ctx, int32(toAccountID)); err != nil {
+		return
+	}
+
+	from, err = q.GetAccountForUpdate(ctx, int32(fromAccountID))
+	return
+}
+
+// hasSufficientFunds returns true if the account can be debited by amount without exceeding its overdraft limit.
+// Money held for transfers awaiting approval cannot be spent.
+func hasSufficientFunds(account Account, amount int64) bool {
+	return account.Balance-account.Held-amount >= -account.OverdraftLimit
+}
+
+// convertAmount converts the amount using the exchange rate, rounding half up to the nearest minor unit
+func convertAmount(amount int64, rate string) (int64, error) {
+	r, ok := new(big.Rat).SetString(rate)
 
-	from, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     int32(fromAccountID),
-		Amount: fromAmount,
+	if !ok {
+		return 0, fmt.Errorf("invalid exchange rate: %s", rate)
+	}
+
+	converted, ok := roundHalfUp(r.Mul(r, new(big.Rat).SetInt64(amount)))
+
+	if !ok {
+		return 0, errors.New("converted amount is out of range")
+	}
+
+	return converted, nil
+}
+
+// roundHalfUp rounds the non-negative number half up to the nearest integer, it returns false if the result does not
+// fit in an int64
+func roundHalfUp(r *big.Rat) (int64, bool) {
+	// Add half of the denominator before the integer division to round the result
+	num := new(big.Int).Mul(r.Num(), big.NewInt(2))
+	num.Add(num, r.Denom())
+
+	result := num.Quo(num, new(big.Int).Mul(r.Denom(), big.NewInt(2)))
+	return result.Int64(), result.IsInt64()
+}
+
+// checkTransferLimits returns a TransferLimitError if debiting the amounts from the account exceeds a limit of its
+// owner. Each amount is checked against the per transaction limit and their sum against the daily and monthly
+// limits. The owner is locked so that concurrent transfers from their other accounts are counted one after the other,
+// this must happen after the accounts are locked to keep the lock order of every transfer the same.
+func checkTransferLimits(ctx context.Context, q *Queries, account Account, amounts ...int64) error {
+	owner, err := q.GetUserForUpdate(ctx, account.Owner)
+
+	if err != nil {
+		return err
+	}
+
+	limit, err := q.GetEffectiveTransferLimit(ctx, GetEffectiveTransferLimitParams{
+		Currency: account.Currency,
+		Username: owner.Username,
+		Role:     owner.Role,
 	})
 
 	if err != nil {
-		return
+		// Users without a limit for the currency can transfer any amount
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return err
+	}
+
+	var total int64
+
+	for _, amount := range amounts {
+		if limit.PerTransaction > 0 && amount > limit.PerTransaction {
+			return &TransferLimitError{
+				Period:    util.PerTransactionLimit,
+				Currency:  account.Currency,
+				Limit:     limit.PerTransaction,
+				Remaining: limit.PerTransaction,
+			}
+		}
+
+		total += amount
+	}
+
+	// Days and months start at midnight UTC
+	now := time.Now().UTC()
+
+	periods := []struct {
+		name  string
+		limit int64
+		since time.Time
+	}{
+		{util.DailyLimit, limit.Daily, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)},
+		{util.MonthlyLimit, limit.Monthly, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, period := range periods {
+		if period.limit == 0 {
+			continue
+		}
+
+		spent, err := q.SumOutgoingTransfersSince(ctx, SumOutgoingTransfersSinceParams{
+			Owner:       owner.Username,
+			Currency:    account.Currency,
+			CreatedFrom: period.since,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if remaining := period.limit - spent; total > remaining {
+			if remaining < 0 {
+				remaining = 0
+			}
+
+			return &TransferLimitError{
+				Period:    period.name,
+				Currency:  account.Currency,
+				Limit:     period.limit,
+				Remaining: remaining,
+			}
+		}
+	}
+
+	return nil
+}
+
+// maxRateScale is the number of decimal places kept when a rate cannot be written exactly
+const maxRateScale = 10
+
+// invertRate returns the rate converting money back in the opposite direction
+func invertRate(rate string) (string, error) {
+	r, ok := new(big.Rat).SetString(rate)
+
+	if !ok || r.Sign() <= 0 {
+		return "", fmt.Errorf("invalid exchange rate: %s", rate)
+	}
+
+	inverse := strings.TrimRight(r.Inv(r).FloatString(maxRateScale), "0")
+	return strings.TrimSuffix(inverse, "."), nil
+}
+
+// exchangeRate returns the rate to convert money between the accounts' currencies
+func exchangeRate(ctx context.Context, q *Queries, from, to Account) (string, error) {
+	if from.Currency == to.Currency {
+		return "1", nil
 	}
 
-	to, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     int32(toAccountID),
-		Amount: toAmount,
+	rate, err := q.GetExchangeRate(ctx, GetExchangeRateParams{
+		FromCurrency: from.Currency,
+		ToCurrency:   to.Currency,
 	})
 
-	return
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrExchangeRateNotFound
+		}
+
+		return "", err
+	}
+
+	return rate.Rate, nil
+}
+
+// transfer moves money from one account to the other using the queries of an open transaction
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	// Lock both accounts so the sender's balance cannot change until the transfer is committed
+	fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+
+	if err != nil {
+		return result, err
+	}
+
+	if err = checkTransferLimits(ctx, q, fromAccount, arg.Amount); err != nil {
+		return result, err
+	}
+
+	fee, feeAccount, err := priceTransfer(ctx, q, fromAccount, arg.Amount)
+
+	if err != nil {
+		return result, err
+	}
+
+	// The sender must be able to cover the fee as well as the amount
+	if !hasSufficientFunds(fromAccount, arg.Amount+fee.Total) {
+		return result, ErrInsufficientFunds
+	}
+
+	// The amount is in the sender's currency, convert it into the receiver's currency
+	rate, err := exchangeRate(ctx, q, fromAccount, toAccount)
+
+	if err != nil {
+		return result, err
+	}
+
+	toAmount, err := convertAmount(arg.Amount, rate)
+
+	if err != nil {
+		return result, err
+	}
+
+	if toAmount <= 0 {
+		return result, ErrConvertedAmountTooSmall
+	}
+
+	result, err = postTransfer(ctx, q, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ToAmount:      toAmount,
+		ExchangeRate:  rate,
+		Memo:          arg.Memo,
+		Reference:     arg.Reference,
+		Metadata:      arg.Metadata,
+	}, fromAccount, toAccount)
+
+	if err != nil {
+		return result, err
+	}
+
+	result.Fee = fee
+
+	if fee.Total > 0 {
+		err = chargeFee(ctx, q, &result, feeAccount)
+	}
+
+	return result, err
+}
+
+// postTransfer records the transfer and moves its money through a journal, the accounts must already be locked
+func postTransfer(ctx context.Context, q *Queries, arg CreateTransferParams, from, to Account) (TransferTxResult,
+	error) {
+
+	// Transfers without metadata store an empty object rather than NULL
+	if len(arg.Metadata) == 0 {
+		arg.Metadata = json.RawMessage("{}")
+	}
+
+	journal, err := transferJournal(ctx, q, from, to, arg.Amount, arg.ToAmount)
+
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	arg.JournalID = sql.NullInt64{Int64: journal.Journal.ID, Valid: true}
+
+	// Create a new transfer between the accounts
+	transfer, err := q.CreateTransfer(ctx, arg)
+
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	return newTransferTxResult(transfer, journal), nil
+}
+
+// newTransferTxResult returns the result of a transfer whose money moved through the journal
+func newTransferTxResult(transfer Transfer, journal JournalResult) TransferTxResult {
+	return TransferTxResult{
+		Transfer:    transfer,
+		FromAccount: journal.Accounts[transfer.FromAccountID],
+		ToAccount:   journal.Accounts[transfer.ToAccountID],
+		FromEntry:   journal.Entries[0],
+		ToEntry:     journal.Entries[1],
+	}
 }
 
 // TransferTx performs a money transfer from one account to the other.
-// It creates the transfer, add account entries, and update accounts' balance within a database transaction
+// It creates the transfer with the journal of its entries, and update accounts' balance within a database transaction.
+// The amount is in the sender's currency and is converted into the receiver's currency if they differ, through the
+// bank's FX accounts so that the journal balances in each currency.
+// The sender is charged the fee of the schedule of their currency on top of the amount, it is paid into the bank's fee
+// account in the same transaction.
+// ErrInsufficientFunds is returned if the sender cannot cover the amount and the fee, and ErrExchangeRateNotFound if
+// there is no rate between the accounts' currencies.
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	err := store.execTx(ctx, nil, func(q *Queries) error {
 		var err error
 
-		// Create a new transfer between the accounts
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+		result, err = transfer(ctx, q, arg)
+		return err
+	})
 
-		if err != nil {
-			return err
-		}
+	return result, err
+}
 
-		// Debit money from the sender
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+// ReplayTransfer returns the stored result of the transfer made with the idempotency key.
+// sql.ErrNoRows is returned if the key has not been used yet.
+func (store *SQLStore) ReplayTransfer(ctx context.Context, arg IdempotentTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	key, err := store.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username:       arg.Username,
+		IdempotencyKey: arg.IdempotencyKey,
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	if key.RequestHash != arg.RequestHash {
+		return result, ErrIdempotencyKeyReused
+	}
+
+	err = json.Unmarshal(key.Response, &result)
+	return result, err
+}
+
+// IdempotentTransferTx performs a money transfer at most once per idempotency key.
+// The result is stored with the key in the same database transaction as the transfer, and retries of the same request
+// get the stored result back instead of moving the money again.
+// ErrIdempotencyKeyReused is returned if the key was already used for a request with a different hash.
+func (store *SQLStore) IdempotentTransferTx(ctx context.Context, arg IdempotentTransferTxParams) (
+	TransferTxResult, error) {
+
+	result, err := store.ReplayTransfer(ctx, arg)
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return result, err
+	}
+
+	err = store.execTx(ctx, nil, func(q *Queries) error {
+		var err error
+
+		result, err = transfer(ctx, q, arg.TransferTxParams)
 
 		if err != nil {
 			return err
 		}
 
-		// Credit money to the receiver
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
+		response, err := json.Marshal(result)
 
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			// Update the sender's account balance first
-			result.FromAccount, result.ToAccount, err = addMoney(context.Background(), q, arg.FromAccountID, -arg.Amount,
-				arg.ToAccountID, arg.Amount)
-		} else {
-			// Update the receiver's account balance first
-			result.ToAccount, result.FromAccount, err = addMoney(context.Background(), q, arg.ToAccountID, arg.Amount,
-				arg.FromAccountID, -arg.Amount)
+		// A concurrent request with the same key blocks here until the first one commits or rolls back
+		_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+			Username:       arg.Username,
+			IdempotencyKey: arg.IdempotencyKey,
+			RequestHash:    arg.RequestHash,
+			Response:       response,
+		})
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return errIdempotencyKeyExists
 		}
 
-		return nil
+		return err
 	})
 
+	// Another request with the same key was committed first and this transfer was rolled back, so replay that one
+	if errors.Is(err, errIdempotencyKeyExists) {
+		return store.ReplayTransfer(ctx, arg)
+	}
+
 	return result, err
 }
+
+// RevokeUserTokensTx revokes every token issued to the user so far and blocks all of their sessions,
+// so that neither their access tokens nor their refresh tokens can be used any longer
+func (store *SQLStore) RevokeUserTokensTx(ctx context.Context, username string) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		var err error
+
+		user, err = q.RevokeUserTokens(ctx, username)
+
+		if err != nil {
+			return err
+		}
+
+		return q.BlockUserSessions(ctx, username)
+	})
+
+	return user, err
+}
+
+// UpdateUserRoleTx changes the role of the user and revokes every token issued to them so far, so that the
+// permissions of the old role cannot be used any longer. The user has to log in again to get tokens with the new role.
+func (store *SQLStore) UpdateUserRoleTx(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		var err error
+
+		if _, err = q.UpdateUserRole(ctx, arg); err != nil {
+			return err
+		}
+
+		user, err = q.RevokeUserTokens(ctx, arg.Username)
+
+		if err != nil {
+			return err
+		}
+
+		return q.BlockUserSessions(ctx, arg.Username)
+	})
+
+	return user, err
+}
+
+// AccountStatementTx returns the entries of an account created within [From, To) with the balance before, after and
+// at every entry. Everything is read from the same snapshot so the balances always add up, even while transfers are
+// being made. Balances are derived from the current balance because accounts may be opened with a balance that has no
+// matching entry.
+func (store *SQLStore) AccountStatementTx(ctx context.Context, arg AccountStatementTxParams) (
+	AccountStatementTxResult, error) {
+
+	result := AccountStatementTxResult{
+		From: arg.From,
+		To:   arg.To,
+	}
+
+	opts := &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	}
+
+	err := store.execTx(ctx, opts, func(q *Queries) error {
+		var err error
+
+		result.Account, err = q.GetAccount(ctx, int32(arg.AccountID))
+
+		if err != nil {
+			return err
+		}
+
+		// Undo everything booked since the end of the statement to get the closing balance
+		sinceClose, err := q.SumEntriesSince(ctx, SumEntriesSinceParams{
+			AccountID:   arg.AccountID,
+			CreatedFrom: arg.To,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		entries, err := q.ListEntriesBetween(ctx, ListEntriesBetweenParams{
+			AccountID:   arg.AccountID,
+			CreatedFrom: arg.From,
+			CreatedTo:   arg.To,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.ClosingBalance = result.Account.Balance - sinceClose
+		result.OpeningBalance = result.ClosingBalance
+
+		for _, entry := range entries {
+			result.OpeningBalance -= entry.Amount
+		}
+
+		balance := result.OpeningBalance
+		result.Lines = make([]StatementLine, 0, len(entries))
+
+		for _, entry := range entries {
+			balance += entry.Amount
+
+			result.Lines = append(result.Lines, StatementLine{
+				Entry:   entry,
+				Balance: balance,
+			})
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// claimedScheduledTransfer locks the scheduled transfer and checks it is still processing the attempt it was claimed
+// for. A worker whose claim timed out must not make the transfer or record its attempt, another worker may have claimed
+// it since.
+func claimedScheduledTransfer(ctx context.Context, q *Queries, id int64, attemptCount int32) error {
+	scheduledTransfer, err := q.GetScheduledTransferForUpdate(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if scheduledTransfer.Status != util.ScheduledTransferProcessing || scheduledTransfer.AttemptCount != attemptCount {
+		return ErrScheduledTransferNotClaimed
+	}
+
+	return nil
+}
+
+// recordScheduledTransferAttempt saves the outcome of an attempt to execute a scheduled transfer and moves the
+// scheduled transfer to its next status
+func recordScheduledTransferAttempt(ctx context.Context, q *Queries, arg RecordScheduledTransferAttemptTxParams) (
+	ScheduledTransfer, error) {
+
+	_, err := q.CreateScheduledTransferAttempt(ctx, CreateScheduledTransferAttemptParams{
+		ScheduledTransferID: arg.ScheduledTransferID,
+		TransferID:          arg.TransferID,
+		Error:               arg.Error,
+	})
+
+	if err != nil {
+		return ScheduledTransfer{}, err
+	}
+
+	return q.UpdateScheduledTransferResult(ctx, UpdateScheduledTransferResultParams{
+		ID:            arg.ScheduledTransferID,
+		Status:        arg.Status,
+		TransferID:    arg.TransferID,
+		NextAttemptAt: arg.NextAttemptAt,
+	})
+}
+
+// RecordScheduledTransferAttemptTx saves the outcome of an attempt to execute a scheduled transfer and moves the
+// scheduled transfer to its next status
+func (store *SQLStore) RecordScheduledTransferAttemptTx(ctx context.Context,
+	arg RecordScheduledTransferAttemptTxParams) (ScheduledTransfer, error) {
+
+	var scheduledTransfer ScheduledTransfer
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		err := claimedScheduledTransfer(ctx, q, arg.ScheduledTransferID, arg.AttemptCount)
+
+		if err != nil {
+			return err
+		}
+
+		scheduledTransfer, err = recordScheduledTransferAttempt(ctx, q, arg)
+		return err
+	})
+
+	return scheduledTransfer, err
+}
+
+// ExecuteScheduledTransferTx makes the transfer of a claimed scheduled transfer and records the successful attempt in
+// the same database transaction, so the scheduled transfer cannot be left processing once the money has moved.
+// Nothing is recorded if the transfer fails, the failed attempt is recorded with RecordScheduledTransferAttemptTx.
+// ErrScheduledTransferNotClaimed is returned without making the transfer if the claim timed out.
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, scheduledTransfer ScheduledTransfer) (
+	TransferTxResult, error) {
+
+	var result TransferTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		// The scheduled transfer is locked before the accounts, like the transfers and approvals decided elsewhere
+		err := claimedScheduledTransfer(ctx, q, scheduledTransfer.ID, scheduledTransfer.AttemptCount)
+
+		if err != nil {
+			return err
+		}
+
+		result, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: scheduledTransfer.FromAccountID,
+			ToAccountID:   scheduledTransfer.ToAccountID,
+			Amount:        scheduledTransfer.Amount,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		_, err = recordScheduledTransferAttempt(ctx, q, RecordScheduledTransferAttemptTxParams{
+			ScheduledTransferID: scheduledTransfer.ID,
+			Status:              util.ScheduledTransferCompleted,
+			TransferID:          sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+			NextAttemptAt:       scheduledTransfer.NextAttemptAt,
+		})
+
+		return err
+	})
+
+	return result, err
+}
+
+// generateStandingOrderTransfer schedules the transfer of the current run of the standing order and moves the order
+// to its next run. Orders past their end time complete without making a transfer.
+func generateStandingOrderTransfer(ctx context.Context, q *Queries, order StandingOrder) (ScheduledTransfer, bool,
+	error) {
+
+	var scheduledTransfer ScheduledTransfer
+
+	if order.EndAt.Valid && order.NextRunAt.After(order.EndAt.Time) {
+		_, err := q.UpdateStandingOrderRun(ctx, UpdateStandingOrderRunParams{
+			ID:        order.ID,
+			NextRunAt: order.NextRunAt,
+			RunCount:  order.RunCount,
+			Status:    util.StandingOrderCompleted,
+		})
+
+		return scheduledTransfer, false, err
+	}
+
+	scheduledTransfer, err := q.CreateStandingOrderTransfer(ctx, CreateStandingOrderTransferParams{
+		FromAccountID:   order.FromAccountID,
+		ToAccountID:     order.ToAccountID,
+		Amount:          order.Amount,
+		ExecuteAt:       order.NextRunAt,
+		StandingOrderID: sql.NullInt64{Int64: order.ID, Valid: true},
+	})
+
+	if err != nil {
+		return scheduledTransfer, false, err
+	}
+
+	arg := UpdateStandingOrderRunParams{
+		ID:        order.ID,
+		NextRunAt: util.NextStandingOrderRun(order.Frequency, int(order.DayOfMonth), order.NextRunAt),
+		RunCount:  order.RunCount + 1,
+		Status:    util.StandingOrderActive,
+	}
+
+	if (order.MaxRuns.Valid && arg.RunCount >= order.MaxRuns.Int32) ||
+		(order.EndAt.Valid && arg.NextRunAt.After(order.EndAt.Time)) {
+		arg.Status = util.StandingOrderCompleted
+	}
+
+	_, err = q.UpdateStandingOrderRun(ctx, arg)
+	return scheduledTransfer, true, err
+}
+
+// GenerateStandingOrderTransfersTx claims up to limit standing orders that are due and schedules a transfer for each
+// of them, which is then made and retried like any other scheduled transfer. Orders locked by another worker are
+// skipped.
+func (store *SQLStore) GenerateStandingOrderTransfersTx(ctx context.Context, limit int32) ([]ScheduledTransfer,
+	error) {
+
+	var scheduledTransfers []ScheduledTransfer
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		scheduledTransfers = []ScheduledTransfer{}
+
+		orders, err := q.ClaimDueStandingOrders(ctx, limit)
+
+		if err != nil {
+			return err
+		}
+
+		for _, order := range orders {
+			scheduledTransfer, generated, err := generateStandingOrderTransfer(ctx, q, order)
+
+			if err != nil {
+				return err
+			}
+
+			if generated {
+				scheduledTransfers = append(scheduledTransfers, scheduledTransfer)
+			}
+		}
+
+		return nil
+	})
+
+	return scheduledTransfers, err
+}
+
+// CancelStandingOrderTx cancels an active or paused standing order together with its transfers that have not been
+// made yet
+func (store *SQLStore) CancelStandingOrderTx(ctx context.Context, id int64) (StandingOrder, error) {
+	var order StandingOrder
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		var err error
+
+		order, err = q.CancelStandingOrder(ctx, id)
+
+		if err != nil {
+			return err
+		}
+
+		return q.CancelStandingOrderTransfers(ctx, sql.NullInt64{Int64: id, Valid: true})
+	})
+
+	return order, err
+}
+
+// ReverseTransferTx undoes a transfer by moving the money back between the same accounts. The receiver returns
+// exactly the amount they were credited, so the sender gets back the original amount even if the exchange rate has
+// changed since. The fee charged on the transfer is refunded to the sender as well. Each transfer can be reversed once
+// and reversals cannot be reversed themselves, pending and voided transfers have nothing to reverse. Reversals do not
+// count towards the transfer limits and free transfers of the receiver returning the money.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult,
+	error) {
+
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		// Lock the transfer so that concurrent reversals of it run one after the other
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+
+		if err != nil {
+			return err
+		}
+
+		if original.Status != util.TransferPosted {
+			return ErrTransferNotPosted
+		}
+
+		reversal, err := q.GetTransferReversal(ctx, original.ID)
+
+		if err == nil {
+			if reversal.TransferID == original.ID {
+				return ErrTransferAlreadyReversed
+			}
+
+			return ErrCannotReverseReversal
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		fromAccount, toAccount, err := lockAccounts(ctx, q, original.ToAccountID, original.FromAccountID)
+
+		if err != nil {
+			return err
+		}
+
+		if !hasSufficientFunds(fromAccount, original.ToAmount) {
+			return ErrInsufficientFunds
+		}
+
+		rate, err := invertRate(original.ExchangeRate)
+
+		if err != nil {
+			return err
+		}
+
+		result.TransferTxResult, err = postTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        original.ToAmount,
+			ToAmount:      original.Amount,
+			ExchangeRate:  rate,
+		}, fromAccount, toAccount)
+
+		if err != nil {
+			return err
+		}
+
+		result.Reversal, err = q.CreateTransferReversal(ctx, CreateTransferReversalParams{
+			TransferID:         original.ID,
+			ReversalTransferID: result.Transfer.ID,
+			Reason:             arg.Reason,
+			ReversedBy:         arg.ReversedBy,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		// The fee charged on the original transfer is refunded with it
+		fee, err := q.GetTransferFee(ctx, original.ID)
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+
+			return err
+		}
+
+		return refundFee(ctx, q, &result, fee, arg)
+	})
+
+	return result, err
+}
+
+// lockBatchAccounts locks the sender and every receiver of a bulk transfer. Like lockAccounts the rows are locked in
+// the order of their IDs, so batches sharing accounts with each other or with single transfers cannot deadlock.
+func lockBatchAccounts(ctx context.Context, q *Queries, fromAccountID int64, legs []BulkTransferLeg) (
+	map[int64]Account, error) {
+
+	// firstLeg maps each receiver to the first leg paying it, to report which leg refers to a missing account
+	firstLeg := make(map[int64]int, len(legs))
+	ids := []int64{fromAccountID}
+
+	for i, leg := range legs {
+		if _, ok := firstLeg[leg.ToAccountID]; ok || leg.ToAccountID == fromAccountID {
+			continue
+		}
+
+		firstLeg[leg.ToAccountID] = i
+		ids = append(ids, leg.ToAccountID)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	accounts := make(map[int64]Account, len(ids))
+
+	for _, id := range ids {
+		account, err := q.GetAccountForUpdate(ctx, int32(id))
+
+		if err != nil {
+			if index, ok := firstLeg[id]; ok {
+				return nil, &LegError{Index: index, Err: err}
+			}
+
+			return nil, err
+		}
+
+		accounts[id] = account
+	}
+
+	return accounts, nil
+}
+
+// lockAccountsByID locks every account once, in ID order like lockAccounts, whatever the order of the IDs
+func lockAccountsByID(ctx context.Context, q *Queries, ids []int64) error {
+	sorted := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			sorted = append(sorted, id)
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	for _, id := range sorted {
+		if _, err := q.GetAccountForUpdate(ctx, int32(id)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BulkTransferTx pays every leg from the same account in a single transaction. All the legs are validated before any
+// money moves, if one of them fails nothing is transferred. Each leg is charged the fee of a transfer of its amount,
+// the sender must be able to cover the fees of the whole batch as well as the amounts.
+func (store *SQLStore) BulkTransferTx(ctx context.Context, arg BulkTransferTxParams) (BulkTransferTxResult, error) {
+	var result BulkTransferTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		accounts, err := lockBatchAccounts(ctx, q, arg.FromAccountID, arg.Legs)
+
+		if err != nil {
+			return err
+		}
+
+		fromAccount := accounts[arg.FromAccountID]
+
+		// Receivers commonly share a currency, so each rate is only looked up once
+		rates := make(map[string]string)
+		amounts := make([]int64, len(arg.Legs))
+		toAmounts := make([]int64, len(arg.Legs))
+
+		var totalAmount int64
+
+		for i, leg := range arg.Legs {
+			toAccount := accounts[leg.ToAccountID]
+			rate, ok := rates[toAccount.Currency]
+
+			if !ok {
+				if rate, err = exchangeRate(ctx, q, fromAccount, toAccount); err != nil {
+					return &LegError{Index: i, Err: err}
+				}
+
+				rates[toAccount.Currency] = rate
+			}
+
+			if toAmounts[i], err = convertAmount(leg.Amount, rate); err != nil {
+				return &LegError{Index: i, Err: err}
+			}
+
+			if toAmounts[i] <= 0 {
+				return &LegError{Index: i, Err: ErrConvertedAmountTooSmall}
+			}
+
+			amounts[i] = leg.Amount
+			totalAmount += leg.Amount
+		}
+
+		if err = checkTransferLimits(ctx, q, fromAccount, amounts...); err != nil {
+			return err
+		}
+
+		fees, feeAccount, err := priceTransfers(ctx, q, fromAccount, amounts...)
+
+		if err != nil {
+			return err
+		}
+
+		var totalFee int64
+
+		for _, fee := range fees {
+			totalFee += fee.Total
+		}
+
+		// The sender must be able to cover the fees of every leg as well as the amounts
+		if !hasSufficientFunds(fromAccount, totalAmount+totalFee) {
+			return ErrInsufficientFunds
+		}
+
+		result.Batch, err = q.CreateTransferBatch(ctx, CreateTransferBatchParams{
+			FromAccountID: arg.FromAccountID,
+			TotalAmount:   totalAmount,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount = fromAccount
+		result.Legs = make([]BulkTransferLegResult, len(arg.Legs))
+
+		for i, leg := range arg.Legs {
+			transferResult, err := postTransfer(ctx, q, CreateTransferParams{
+				FromAccountID: arg.FromAccountID,
+				ToAccountID:   leg.ToAccountID,
+				Amount:        leg.Amount,
+				ToAmount:      toAmounts[i],
+				ExchangeRate:  rates[accounts[leg.ToAccountID].Currency],
+			}, fromAccount, accounts[leg.ToAccountID])
+
+			if err != nil {
+				return err
+			}
+
+			transferResult.Fee = fees[i]
+
+			if fees[i].Total > 0 {
+				if err = chargeFee(ctx, q, &transferResult, feeAccount); err != nil {
+					return err
+				}
+			}
+
+			_, err = q.CreateTransferBatchLeg(ctx, CreateTransferBatchLegParams{
+				BatchID:    result.Batch.ID,
+				LegIndex:   int32(i),
+				TransferID: transferResult.Transfer.ID,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			result.FromAccount = transferResult.FromAccount
+			result.Legs[i] = BulkTransferLegResult{
+				Transfer:  transferResult.Transfer,
+				FromEntry: transferResult.FromEntry,
+				ToEntry:   transferResult.ToEntry,
+				Fee:       transferResult.Fee,
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// AcceptMoneyRequestTx pays a pending money request. The request is locked so that it is paid at most once, even if
+// the payer accepts it several times concurrently.
+func (store *SQLStore) AcceptMoneyRequestTx(ctx context.Context, arg AcceptMoneyRequestTxParams) (
+	AcceptMoneyRequestTxResult, error) {
+
+	var result AcceptMoneyRequestTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		request, err := q.GetMoneyRequestForUpdate(ctx, arg.ID)
+
+		if err != nil {
+			return err
+		}
+
+		if request.Status != util.MoneyRequestPending {
+			return ErrMoneyRequestNotPending
+		}
+
+		// The worker closes expired requests periodically, they may still be pending in between
+		if !request.ExpiresAt.After(time.Now()) {
+			return ErrMoneyRequestExpired
+		}
+
+		result.TransferTxResult, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        request.Amount,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.MoneyRequest, err = q.AcceptMoneyRequest(ctx, AcceptMoneyRequestParams{
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+			ID:         request.ID,
+		})
+
+		return err
+	})
+
+	return result, err
+}
+
+// DepositTx credits cash handed in by a customer to the account. The money comes from the settlement account in the
+// currency of the account, whose balance goes below zero by the total customers have deposited so that the ledger
+// always sums to zero. ErrCashOnOwnAccount is returned if the staff member posting the cash owns the account.
+func (store *SQLStore) DepositTx(ctx context.Context, arg CashTxParams) (TransferTxResult, error) {
+	return store.cashTx(ctx, arg, true)
+}
+
+// WithdrawTx debits cash handed out to a customer from the account and returns the money to the settlement account
+// in the currency of the account. ErrInsufficientFunds is returned if the account cannot cover the amount.
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg CashTxParams) (TransferTxResult, error) {
+	return store.cashTx(ctx, arg, false)
+}
+
+// isSettlementAccount returns true if the account is the settlement account of its currency
+func isSettlementAccount(ctx context.Context, q *Queries, account Account) (bool, error) {
+	settlementAccount, err := q.GetSettlementAccount(ctx, account.Currency)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return settlementAccount.ID == account.ID, nil
+}
+
+// cashTx posts a transfer between the account and the settlement account in its currency, towards the account for
+// deposits and away from it for withdrawals
+func (store *SQLStore) cashTx(ctx context.Context, arg CashTxParams, deposit bool) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	metadata, err := json.Marshal(map[string]string{"posted_by": arg.PostedBy})
+
+	if err != nil {
+		return result, err
+	}
+
+	err = store.execTx(ctx, nil, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, int32(arg.AccountID))
+
+		if err != nil {
+			return err
+		}
+
+		// Nobody else would be involved in creating the money
+		if deposit && account.Owner == arg.PostedBy {
+			return ErrCashOnOwnAccount
+		}
+
+		settlementAccount, err := q.GetSettlementAccount(ctx, account.Currency)
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrSettlementAccountNotFound
+			}
+
+			return err
+		}
+
+		if settlementAccount.ID == account.ID {
+			return ErrCashOnSettlementAccount
+		}
+
+		fromAccountID, toAccountID := int64(settlementAccount.ID), arg.AccountID
+
+		if !deposit {
+			fromAccountID, toAccountID = toAccountID, fromAccountID
+		}
+
+		fromAccount, toAccount, err := lockAccounts(ctx, q, fromAccountID, toAccountID)
+
+		if err != nil {
+			return err
+		}
+
+		// Settlement accounts have no floor, only the customer's balance is checked
+		if !deposit && !hasSufficientFunds(fromAccount, arg.Amount) {
+			return ErrInsufficientFunds
+		}
+
+		result, err = postTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: fromAccountID,
+			ToAccountID:   toAccountID,
+			Amount:        arg.Amount,
+			ToAmount:      arg.Amount,
+			ExchangeRate:  "1",
+			Memo:          arg.Memo,
+			Metadata:      metadata,
+		}, fromAccount, toAccount)
+
+		return err
+	})
+
+	return result, err
+}
+
+// ReplayTransferApproval returns the approval requested with the idempotency key.
+// sql.ErrNoRows is returned if the key has not been used yet.
+func (store *SQLStore) ReplayTransferApproval(ctx context.Context, arg RequestTransferApprovalTxParams) (
+	RequestTransferApprovalTxResult, error) {
+
+	var result RequestTransferApprovalTxResult
+
+	approval, err := store.GetTransferApprovalByIdempotencyKey(ctx, GetTransferApprovalByIdempotencyKeyParams{
+		RequestedBy:    arg.RequestedBy,
+		IdempotencyKey: arg.IdempotencyKey,
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	if approval.RequestHash != arg.RequestHash {
+		return result, ErrIdempotencyKeyReused
+	}
+
+	result.Approval = approval
+	result.FromAccount, err = store.GetAccount(ctx, int32(approval.FromAccountID))
+
+	return result, err
+}
+
+// RequestTransferApprovalTx records a transfer that must be approved by another user before it is made. The amount
+// is held on the sender account until the transfer is approved, rejected or expires, so that it cannot be spent in
+// the meantime. The transfer is checked like TransferTx would, except that the money does not move yet. Transfers from
+// a settlement account are cash deposits, they are not checked against its balance and limits.
+func (store *SQLStore) RequestTransferApprovalTx(ctx context.Context, arg RequestTransferApprovalTxParams) (
+	RequestTransferApprovalTxResult, error) {
+
+	if arg.IdempotencyKey != "" {
+		result, err := store.ReplayTransferApproval(ctx, arg)
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			return result, err
+		}
+	}
+
+	var result RequestTransferApprovalTxResult
+
+	// Approvals without metadata store an empty object rather than NULL
+	metadata := arg.Metadata
+
+	if len(metadata) == 0 {
+		metadata = json.RawMessage("{}")
+	}
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		fromAccount, err := q.GetAccountForUpdate(ctx, int32(arg.FromAccountID))
+
+		if err != nil {
+			return err
+		}
+
+		toAccount, err := q.GetAccount(ctx, int32(arg.ToAccountID))
+
+		if err != nil {
+			return err
+		}
+
+		deposit, err := isSettlementAccount(ctx, q, fromAccount)
+
+		if err != nil {
+			return err
+		}
+
+		// Deposits come from the settlement account, which has no floor and no limits
+		if deposit {
+			if toAccount.Owner == arg.RequestedBy {
+				return ErrCashOnOwnAccount
+			}
+		} else {
+			if !hasSufficientFunds(fromAccount, arg.Amount) {
+				return ErrInsufficientFunds
+			}
+
+			if err = checkTransferLimits(ctx, q, fromAccount, arg.Amount); err != nil {
+				return err
+			}
+		}
+
+		// The rate may still change before the approval, it is only checked so that the transfer can be made
+		if _, err = exchangeRate(ctx, q, fromAccount, toAccount); err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeld(ctx, AddAccountHeldParams{
+			Amount: arg.Amount,
+			ID:     fromAccount.ID,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		// A concurrent request with the same key blocks here until the first one commits or rolls back
+		result.Approval, err = q.CreateTransferApproval(ctx, CreateTransferApprovalParams{
+			FromAccountID:  arg.FromAccountID,
+			ToAccountID:    arg.ToAccountID,
+			Amount:         arg.Amount,
+			Memo:           arg.Memo,
+			Reference:      arg.Reference,
+			Metadata:       metadata,
+			RequestedBy:    arg.RequestedBy,
+			IdempotencyKey: arg.IdempotencyKey,
+			RequestHash:    arg.RequestHash,
+			ExpiresAt:      arg.ExpiresAt,
+		})
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return errIdempotencyKeyExists
+		}
+
+		return err
+	})
+
+	// Another request with the same key was committed first and this one was rolled back, so replay that one
+	if errors.Is(err, errIdempotencyKeyExists) {
+		return store.ReplayTransferApproval(ctx, arg)
+	}
+
+	return result, err
+}
+
+// pendingApproval locks the transfer approval and checks that the user can still decide it
+func pendingApproval(ctx context.Context, q *Queries, arg DecideTransferTxParams) (TransferApproval, error) {
+	approval, err := q.GetTransferApprovalForUpdate(ctx, arg.ID)
+
+	if err != nil {
+		return approval, err
+	}
+
+	if approval.Status != util.TransferApprovalPending {
+		return approval, ErrTransferApprovalNotPending
+	}
+
+	if approval.RequestedBy == arg.DecidedBy {
+		return approval, ErrApproverIsRequester
+	}
+
+	if arg.CanDecideAny {
+		return approval, nil
+	}
+
+	_, err = q.GetAccountCoOwner(ctx, GetAccountCoOwnerParams{
+		AccountID: approval.FromAccountID,
+		Username:  arg.DecidedBy,
+	})
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return approval, ErrApproverNotCoOwner
+	}
+
+	return approval, err
+}
+
+// ApproveTransferTx releases the money held for a pending approval and makes the transfer. The approval is locked so
+// that the transfer is made at most once, even if it is approved several times concurrently. Cash deposits are posted
+// like DepositTx does, neither the teller nor the approver may own the account.
+func (store *SQLStore) ApproveTransferTx(ctx context.Context, arg DecideTransferTxParams) (ApproveTransferTxResult,
+	error) {
+
+	var result ApproveTransferTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		approval, err := pendingApproval(ctx, q, arg)
+
+		if err != nil {
+			return err
+		}
+
+		// The worker expires approvals periodically, they may still be pending in between
+		if !approval.ExpiresAt.After(time.Now()) {
+			return ErrTransferApprovalExpired
+		}
+
+		// Both accounts are locked before the hold is released to keep the lock order of every transfer the same
+		fromAccount, toAccount, err := lockAccounts(ctx, q, approval.FromAccountID, approval.ToAccountID)
+
+		if err != nil {
+			return err
+		}
+
+		deposit, err := isSettlementAccount(ctx, q, fromAccount)
+
+		if err != nil {
+			return err
+		}
+
+		if deposit && toAccount.Owner == arg.DecidedBy {
+			return ErrCashOnOwnAccount
+		}
+
+		_, err = q.AddAccountHeld(ctx, AddAccountHeldParams{
+			Amount: -approval.Amount,
+			ID:     int32(approval.FromAccountID),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if deposit {
+			// Cash is posted like DepositTx does, without the limits and the fees of transfers
+			result.TransferTxResult, err = postTransfer(ctx, q, CreateTransferParams{
+				FromAccountID: approval.FromAccountID,
+				ToAccountID:   approval.ToAccountID,
+				Amount:        approval.Amount,
+				ToAmount:      approval.Amount,
+				ExchangeRate:  "1",
+				Memo:          approval.Memo,
+				Reference:     approval.Reference,
+				Metadata:      approval.Metadata,
+			}, fromAccount, toAccount)
+		} else {
+			result.TransferTxResult, err = transfer(ctx, q, TransferTxParams{
+				FromAccountID: approval.FromAccountID,
+				ToAccountID:   approval.ToAccountID,
+				Amount:        approval.Amount,
+				Memo:          approval.Memo,
+				Reference:     approval.Reference,
+				Metadata:      approval.Metadata,
+			})
+		}
+
+		if err != nil {
+			return err
+		}
+
+		result.Approval, err = q.DecideTransferApproval(ctx, DecideTransferApprovalParams{
+			Status:     util.TransferApprovalApproved,
+			DecidedBy:  sql.NullString{String: arg.DecidedBy, Valid: true},
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+			ID:         approval.ID,
+		})
+
+		return err
+	})
+
+	return result, err
+}
+
+// RejectTransferTx releases the money held for a pending approval without making the transfer
+func (store *SQLStore) RejectTransferTx(ctx context.Context, arg DecideTransferTxParams) (TransferApproval, error) {
+	var approval TransferApproval
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		pending, err := pendingApproval(ctx, q, arg)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountHeld(ctx, AddAccountHeldParams{
+			Amount: -pending.Amount,
+			ID:     int32(pending.FromAccountID),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		approval, err = q.DecideTransferApproval(ctx, DecideTransferApprovalParams{
+			Status:    util.TransferApprovalRejected,
+			DecidedBy: sql.NullString{String: arg.DecidedBy, Valid: true},
+			Reason:    arg.Reason,
+			ID:        pending.ID,
+		})
+
+		return err
+	})
+
+	return approval, err
+}
+
+// ExpireTransferApprovalsTx expires the pending approvals past their expiry and releases the money held for them.
+// The approvals and then their sender accounts are locked in ID order before anything is updated, the order
+// ApproveTransferTx and RejectTransferTx lock them in, so that expiring many approvals at once cannot deadlock with
+// them or with transfers.
+func (store *SQLStore) ExpireTransferApprovalsTx(ctx context.Context) error {
+	return store.execTx(ctx, nil, func(q *Queries) error {
+		approvals, err := q.ListExpiredTransferApprovalsForUpdate(ctx)
+
+		if err != nil || len(approvals) == 0 {
+			return err
+		}
+
+		accountIDs := make([]int64, len(approvals))
+
+		for i, approval := range approvals {
+			accountIDs[i] = approval.FromAccountID
+		}
+
+		if err = lockAccountsByID(ctx, q, accountIDs); err != nil {
+			return err
+		}
+
+		return q.ExpireTransferApprovals(ctx)
+	})
+}
+
+// CreatePendingTransferTx records a transfer that holds the amount on the sender account without moving it yet. The
+// transfer is checked like TransferTx would and the exchange rate is fixed now, the fee is charged when it is posted.
+func (store *SQLStore) CreatePendingTransferTx(ctx context.Context, arg PendingTransferTxParams) (
+	PendingTransferTxResult, error) {
+
+	var result PendingTransferTxResult
+
+	// Transfers without metadata store an empty object rather than NULL
+	metadata := arg.Metadata
+
+	if len(metadata) == 0 {
+		metadata = json.RawMessage("{}")
+	}
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+
+		if err != nil {
+			return err
+		}
+
+		if !hasSufficientFunds(fromAccount, arg.Amount) {
+			return ErrInsufficientFunds
+		}
+
+		if err = checkTransferLimits(ctx, q, fromAccount, arg.Amount); err != nil {
+			return err
+		}
+
+		rate, err := exchangeRate(ctx, q, fromAccount, toAccount)
+
+		if err != nil {
+			return err
+		}
+
+		toAmount, err := convertAmount(arg.Amount, rate)
+
+		if err != nil {
+			return err
+		}
+
+		if toAmount <= 0 {
+			return ErrConvertedAmountTooSmall
+		}
+
+		result.Transfer, err = q.CreatePendingTransfer(ctx, CreatePendingTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			ToAmount:      toAmount,
+			ExchangeRate:  rate,
+			Memo:          arg.Memo,
+			Reference:     arg.Reference,
+			Metadata:      metadata,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CreateTransferHold(ctx, CreateTransferHoldParams{
+			TransferID: result.Transfer.ID,
+			Amount:     arg.Amount,
+			ExpiresAt:  arg.ExpiresAt,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeld(ctx, AddAccountHeldParams{
+			Amount: arg.Amount,
+			ID:     fromAccount.ID,
+		})
+
+		return err
+	})
+
+	return result, err
+}
+
+// pendingTransfer locks the transfer and checks that it can still be posted or voided
+func pendingTransfer(ctx context.Context, q *Queries, id int64) (Transfer, error) {
+	transfer, err := q.GetTransferForUpdate(ctx, id)
+
+	if err != nil {
+		return transfer, err
+	}
+
+	if transfer.Status != util.TransferPending {
+		return transfer, ErrTransferNotPending
+	}
+
+	return transfer, nil
+}
+
+// PostTransferTx releases the money held for a pending transfer and moves the amount posted, which may be lower than
+// the amount held, at the exchange rate fixed when the transfer was created. The sender is charged the fee of the
+// amount posted. The transfer is locked so that it is posted at most once.
+func (store *SQLStore) PostTransferTx(ctx context.Context, arg PostTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		pending, err := pendingTransfer(ctx, q, arg.ID)
+
+		if err != nil {
+			return err
+		}
+
+		hold, err := q.GetTransferHold(ctx, pending.ID)
+
+		if err != nil {
+			return err
+		}
+
+		// The worker voids expired transfers periodically, they may still be pending in between
+		if !hold.ExpiresAt.After(time.Now()) {
+			return ErrTransferHoldExpired
+		}
+
+		amount := arg.Amount
+
+		if amount == 0 {
+			amount = pending.Amount
+		}
+
+		if amount > pending.Amount {
+			return ErrPostAmountExceedsHold
+		}
+
+		// Both accounts are locked before the hold is released to keep the lock order of every transfer the same
+		_, toAccount, err := lockAccounts(ctx, q, pending.FromAccountID, pending.ToAccountID)
+
+		if err != nil {
+			return err
+		}
+
+		fromAccount, err := q.AddAccountHeld(ctx, AddAccountHeldParams{
+			Amount: -pending.Amount,
+			ID:     int32(pending.FromAccountID),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		// The limits were checked when the money was held, the owner is only locked to count the free transfers
+		if _, err = q.GetUserForUpdate(ctx, fromAccount.Owner); err != nil {
+			return err
+		}
+
+		fee, feeAccount, err := priceTransfer(ctx, q, fromAccount, amount)
+
+		if err != nil {
+			return err
+		}
+
+		if !hasSufficientFunds(fromAccount, amount+fee.Total) {
+			return ErrInsufficientFunds
+		}
+
+		toAmount, err := convertAmount(amount, pending.ExchangeRate)
+
+		if err != nil {
+			return err
+		}
+
+		if toAmount <= 0 {
+			return ErrConvertedAmountTooSmall
+		}
+
+		journal, err := transferJournal(ctx, q, fromAccount, toAccount, amount, toAmount)
+
+		if err != nil {
+			return err
+		}
+
+		posted, err := q.PostPendingTransfer(ctx, PostPendingTransferParams{
+			Amount:    amount,
+			ToAmount:  toAmount,
+			JournalID: sql.NullInt64{Int64: journal.Journal.ID, Valid: true},
+			ID:        pending.ID,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result = newTransferTxResult(posted, journal)
+
+		result.Fee = fee
+
+		if fee.Total > 0 {
+			err = chargeFee(ctx, q, &result, feeAccount)
+		}
+
+		return err
+	})
+
+	return result, err
+}
+
+// VoidTransferTx releases the money held for a pending transfer without moving it
+func (store *SQLStore) VoidTransferTx(ctx context.Context, id int64) (PendingTransferTxResult, error) {
+	var result PendingTransferTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		pending, err := pendingTransfer(ctx, q, id)
+
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.GetTransferHold(ctx, pending.ID)
+
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeld(ctx, AddAccountHeldParams{
+			Amount: -pending.Amount,
+			ID:     int32(pending.FromAccountID),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = q.VoidPendingTransfer(ctx, pending.ID)
+		return err
+	})
+
+	return result, err
+}
+
+// ExpirePendingTransfersTx voids the pending transfers whose hold expired and releases the money held for them.
+// The transfers and then their sender accounts are locked in ID order before anything is updated, the order
+// PostTransferTx and VoidTransferTx lock them in, so that expiring many transfers at once cannot deadlock with them or
+// with other transfers.
+func (store *SQLStore) ExpirePendingTransfersTx(ctx context.Context) error {
+	return store.execTx(ctx, nil, func(q *Queries) error {
+		transfers, err := q.ListExpiredPendingTransfersForUpdate(ctx)
+
+		if err != nil || len(transfers) == 0 {
+			return err
+		}
+
+		accountIDs := make([]int64, len(transfers))
+
+		for i, transfer := range transfers {
+			accountIDs[i] = transfer.FromAccountID
+		}
+
+		if err = lockAccountsByID(ctx, q, accountIDs); err != nil {
+			return err
+		}
+
+		return q.ExpirePendingTransfers(ctx)
+	})
+}
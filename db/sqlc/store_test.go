@@ -2,16 +2,21 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"github.com/jwambugu/go-simple-bank-class/util"
 	"github.com/stretchr/testify/require"
+	"math"
 	"testing"
+	"time"
 )
 
 func TestStore_TransferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	accountOne := createRandomAccount(t)
-	accountTwo := createRandomAccount(t)
+	accountOne := createRandomAccountWithBalance(t, util.RandomInt(100, 1000))
+	accountTwo := createRandomAccountWithCurrency(t, util.RandomInt(100, 1000), accountOne.Currency)
 
 	fmt.Println(">> before tx:", accountOne.Balance, accountTwo.Balance)
 
@@ -128,8 +133,8 @@ func TestStore_TransferTx(t *testing.T) {
 func TestStore_TransferTxDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
-	accountOne := createRandomAccount(t)
-	accountTwo := createRandomAccount(t)
+	accountOne := createRandomAccountWithBalance(t, util.RandomInt(100, 1000))
+	accountTwo := createRandomAccountWithCurrency(t, util.RandomInt(100, 1000), accountOne.Currency)
 
 	fmt.Println(">> before tx:", accountOne.Balance, accountTwo.Balance)
 
@@ -181,3 +186,1394 @@ func TestStore_TransferTxDeadlock(t *testing.T) {
 	// The new account two balance will be the same as it was before the tx
 	require.Equal(t, accountTwo.Balance, updatedAccountTwo.Balance)
 }
+
+func TestStore_TransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	accountOne := createRandomAccountWithBalance(t, 10)
+	accountTwo := createRandomAccountWithCurrency(t, util.RandomMoney(), accountOne.Currency)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        11,
+	})
+
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.Empty(t, result)
+
+	// The balances must be untouched
+	updatedAccountOne, err := testQueries.GetAccount(context.Background(), accountOne.ID)
+	require.NoError(t, err)
+	require.Equal(t, accountOne.Balance, updatedAccountOne.Balance)
+
+	updatedAccountTwo, err := testQueries.GetAccount(context.Background(), accountTwo.ID)
+	require.NoError(t, err)
+	require.Equal(t, accountTwo.Balance, updatedAccountTwo.Balance)
+}
+
+func TestStore_TransferTxOverdraftLimit(t *testing.T) {
+	store := NewStore(testDB)
+
+	accountOne := createRandomAccountWithBalance(t, 10)
+	accountTwo := createRandomAccountWithCurrency(t, util.RandomMoney(), accountOne.Currency)
+
+	_, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             accountOne.ID,
+		OverdraftLimit: 20,
+	})
+	require.NoError(t, err)
+
+	// Going 20 below zero is within the limit
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        30,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, int64(-20), result.FromAccount.Balance)
+
+	// Any further debit exceeds it
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        1,
+	})
+
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestStore_RevokeUserTokensTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	session := createRandomSession(t)
+
+	user, err := store.RevokeUserTokensTx(context.Background(), session.Username)
+	require.NoError(t, err)
+	require.False(t, user.TokensRevokedAt.IsZero())
+
+	blocked, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+}
+
+func TestStore_UpdateUserRoleTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	session := createRandomSession(t)
+
+	user, err := store.UpdateUserRoleTx(context.Background(), UpdateUserRoleParams{
+		Role:     util.AdminRole,
+		Username: session.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.AdminRole, user.Role)
+	require.False(t, user.TokensRevokedAt.IsZero())
+
+	blocked, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+}
+
+func TestStore_IdempotentTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	accountOne := createRandomAccountWithBalance(t, 1000)
+	accountTwo := createRandomAccountWithCurrency(t, util.RandomMoney(), accountOne.Currency)
+
+	arg := IdempotentTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: int64(accountOne.ID),
+			ToAccountID:   int64(accountTwo.ID),
+			Amount:        10,
+		},
+		Username:       accountOne.Owner,
+		IdempotencyKey: util.RandomString(16),
+		RequestHash:    util.RandomString(32),
+	}
+
+	n := 5
+	errs := make(chan error)
+	results := make(chan TransferTxResult)
+
+	// Retry the same request concurrently, the money must only move once
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := store.IdempotentTransferTx(context.Background(), arg)
+
+			errs <- err
+			results <- result
+		}()
+	}
+
+	var transferID int64
+
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+
+		result := <-results
+		require.NotZero(t, result.Transfer.ID)
+
+		if transferID == 0 {
+			transferID = result.Transfer.ID
+		}
+
+		require.Equal(t, transferID, result.Transfer.ID)
+		require.Equal(t, accountOne.Balance-arg.Amount, result.FromAccount.Balance)
+	}
+
+	updatedAccountOne, err := testQueries.GetAccount(context.Background(), accountOne.ID)
+	require.NoError(t, err)
+	require.Equal(t, accountOne.Balance-arg.Amount, updatedAccountOne.Balance)
+
+	// Reusing the key for a different request is rejected
+	arg.RequestHash = util.RandomString(32)
+
+	_, err = store.IdempotentTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestStore_TransferTxDetails(t *testing.T) {
+	store := NewStore(testDB)
+
+	accountOne := createRandomAccountWithBalance(t, 1000)
+	accountTwo := createRandomAccountWithCurrency(t, 1000, accountOne.Currency)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        10,
+		Memo:          "Lunch",
+		Reference:     "REF-1",
+		Metadata:      json.RawMessage(`{"split": 2}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Lunch", result.Transfer.Memo)
+	require.Equal(t, "REF-1", result.Transfer.Reference)
+	require.JSONEq(t, `{"split": 2}`, string(result.Transfer.Metadata))
+
+	// Transfers without metadata store an empty object
+	result, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.Transfer.Memo)
+	require.JSONEq(t, `{}`, string(result.Transfer.Metadata))
+}
+
+func TestStore_TransferTxCrossCurrency(t *testing.T) {
+	store := NewStore(testDB)
+
+	createExchangeRate(t, util.EUR, util.USD, "1.1")
+
+	accountOne := createRandomAccountWithCurrency(t, 1000, util.EUR)
+	accountTwo := createRandomAccountWithCurrency(t, 1000, util.USD)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(100), result.Transfer.Amount)
+	require.Equal(t, int64(110), result.Transfer.ToAmount)
+	require.Equal(t, "1.1", result.Transfer.ExchangeRate)
+
+	// Each entry is in its account's own currency
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, int64(110), result.ToEntry.Amount)
+
+	require.Equal(t, accountOne.Balance-100, result.FromAccount.Balance)
+	require.Equal(t, accountTwo.Balance+110, result.ToAccount.Balance)
+
+	// The FX accounts take the euros and pay out the dollars so that the journal balances in both currencies
+	entries, err := testQueries.ListJournalEntries(context.Background(), result.Transfer.JournalID)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	require.Equal(t, result.FromEntry, entries[0])
+	require.Equal(t, result.ToEntry, entries[1])
+
+	eurFxAccount, err := testQueries.GetFxAccount(context.Background(), util.EUR)
+	require.NoError(t, err)
+
+	usdFxAccount, err := testQueries.GetFxAccount(context.Background(), util.USD)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(eurFxAccount.ID), entries[2].AccountID)
+	require.Equal(t, int64(100), entries[2].Amount)
+	require.Equal(t, int64(usdFxAccount.ID), entries[3].AccountID)
+	require.Equal(t, int64(-110), entries[3].Amount)
+}
+
+func TestStore_TransferTxExchangeRateNotFound(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, _ = testQueries.DeleteExchangeRate(context.Background(), DeleteExchangeRateParams{
+		FromCurrency: util.USD,
+		ToCurrency:   util.EUR,
+	})
+
+	accountOne := createRandomAccountWithCurrency(t, 1000, util.USD)
+	accountTwo := createRandomAccountWithCurrency(t, 1000, util.EUR)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        100,
+	})
+	require.ErrorIs(t, err, ErrExchangeRateNotFound)
+}
+
+func TestConvertAmount(t *testing.T) {
+	testCases := []struct {
+		amount   int64
+		rate     string
+		expected int64
+	}{
+		{amount: 100, rate: "1", expected: 100},
+		{amount: 100, rate: "1.1", expected: 110},
+		{amount: 333, rate: "0.5", expected: 167},
+		{amount: 1, rate: "0.49", expected: 0},
+		{amount: 1000, rate: "0.92345678", expected: 923},
+	}
+
+	for _, testCase := range testCases {
+		converted, err := convertAmount(testCase.amount, testCase.rate)
+		require.NoError(t, err)
+		require.Equal(t, testCase.expected, converted)
+	}
+
+	_, err := convertAmount(100, "not a rate")
+	require.Error(t, err)
+}
+
+func TestStore_AccountStatementTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	accountOne := createRandomAccountWithBalance(t, 1000)
+	accountTwo := createRandomAccountWithCurrency(t, 1000, accountOne.Currency)
+
+	arg := TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        100,
+	}
+
+	first, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	arg.Amount = 50
+
+	second, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// Only the first transfer falls within the statement, the second one is undone to get the closing balance
+	statement, err := store.AccountStatementTx(context.Background(), AccountStatementTxParams{
+		AccountID: int64(accountOne.ID),
+		From:      first.FromEntry.CreatedAt,
+		To:        second.FromEntry.CreatedAt,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1000), statement.OpeningBalance)
+	require.Equal(t, int64(900), statement.ClosingBalance)
+	require.Len(t, statement.Lines, 1)
+	require.Equal(t, first.FromEntry.ID, statement.Lines[0].ID)
+	require.Equal(t, int64(900), statement.Lines[0].Balance)
+
+	// A statement up to now closes on the current balance
+	statement, err = store.AccountStatementTx(context.Background(), AccountStatementTxParams{
+		AccountID: int64(accountOne.ID),
+		From:      first.FromEntry.CreatedAt,
+		To:        second.FromEntry.CreatedAt.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1000), statement.OpeningBalance)
+	require.Equal(t, int64(850), statement.ClosingBalance)
+	require.Equal(t, second.FromAccount.Balance, statement.ClosingBalance)
+	require.Len(t, statement.Lines, 2)
+	require.Equal(t, int64(850), statement.Lines[1].Balance)
+}
+
+func TestStore_ReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	admin := createRandomUser(t)
+	accountOne := createRandomAccountWithBalance(t, 1000)
+	accountTwo := createRandomAccountWithCurrency(t, 1000, accountOne.Currency)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Reason:     "sent to the wrong account",
+		ReversedBy: admin.Username,
+	})
+	require.NoError(t, err)
+
+	// The money moves back from the receiver to the sender
+	require.Equal(t, int64(accountTwo.ID), result.Transfer.FromAccountID)
+	require.Equal(t, int64(accountOne.ID), result.Transfer.ToAccountID)
+	require.Equal(t, int64(100), result.Transfer.Amount)
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, int64(100), result.ToEntry.Amount)
+	require.Equal(t, accountOne.Balance, result.ToAccount.Balance)
+	require.Equal(t, accountTwo.Balance, result.FromAccount.Balance)
+
+	require.Equal(t, transfer.Transfer.ID, result.Reversal.TransferID)
+	require.Equal(t, result.Transfer.ID, result.Reversal.ReversalTransferID)
+	require.Equal(t, admin.Username, result.Reversal.ReversedBy)
+
+	// Both transfers show the link
+	original, err := testQueries.GetTransfer(context.Background(), transfer.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.ID, original.ReversedByTransferID)
+	require.Zero(t, original.ReversalOfTransferID)
+
+	reversal, err := testQueries.GetTransfer(context.Background(), result.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, transfer.Transfer.ID, reversal.ReversalOfTransferID)
+	require.Zero(t, reversal.ReversedByTransferID)
+
+	// A transfer is only reversed once and reversals are final
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Reason:     "again",
+		ReversedBy: admin.Username,
+	})
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: result.Transfer.ID,
+		Reason:     "undo the reversal",
+		ReversedBy: admin.Username,
+	})
+	require.ErrorIs(t, err, ErrCannotReverseReversal)
+}
+
+func TestStore_ReverseTransferTxRefundsFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	defer func() {
+		_, _ = testQueries.DeleteFeeSchedule(context.Background(), util.CAD)
+	}()
+
+	_, err := testQueries.UpsertFeeSchedule(context.Background(), UpsertFeeScheduleParams{
+		Currency:   util.CAD,
+		Flat:       10,
+		Percentage: "0",
+	})
+	require.NoError(t, err)
+
+	feeAccount, err := testQueries.GetFeeAccount(context.Background(), util.CAD)
+	require.NoError(t, err)
+
+	admin := createRandomUser(t)
+	accountOne := createRandomAccountWithCurrency(t, 1000, util.CAD)
+	accountTwo := createRandomAccountWithCurrency(t, 0, util.CAD)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), transfer.Fee.Total)
+	require.Equal(t, accountOne.Balance-110, transfer.FromAccount.Balance)
+
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Reason:     "sent to the wrong account",
+		ReversedBy: admin.Username,
+	})
+	require.NoError(t, err)
+
+	// The sender gets back the amount and the fee
+	require.Equal(t, accountOne.Balance, result.ToAccount.Balance)
+	require.Equal(t, accountTwo.Balance, result.FromAccount.Balance)
+
+	require.Equal(t, int64(feeAccount.ID), result.FeeRefund.FromAccountID)
+	require.Equal(t, int64(accountOne.ID), result.FeeRefund.ToAccountID)
+	require.Equal(t, int64(10), result.FeeRefund.Amount)
+
+	// The fee transfer is marked as reversed by the refund
+	feeReversal, err := testQueries.GetTransferReversal(context.Background(), transfer.Fee.FeeTransferID)
+	require.NoError(t, err)
+	require.Equal(t, result.FeeRefund.ID, feeReversal.ReversalTransferID)
+
+	// Returning the money does not count as the receiver spending it
+	stats, err := testQueries.GetOutgoingTransferStatsSince(context.Background(), GetOutgoingTransferStatsSinceParams{
+		Owner:       accountTwo.Owner,
+		Currency:    util.CAD,
+		CreatedFrom: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, stats.Count)
+
+	spent, err := testQueries.SumOutgoingTransfersSince(context.Background(), SumOutgoingTransfersSinceParams{
+		Owner:       accountTwo.Owner,
+		Currency:    util.CAD,
+		CreatedFrom: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, spent)
+}
+
+func TestStore_ReverseTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	admin := createRandomUser(t)
+	accountOne := createRandomAccountWithBalance(t, 1000)
+	accountTwo := createRandomAccountWithCurrency(t, 0, accountOne.Currency)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	// The receiver spends the money before the transfer is reversed
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountTwo.ID),
+		ToAccountID:   int64(accountOne.ID),
+		Amount:        50,
+	})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Reason:     "sent to the wrong account",
+		ReversedBy: admin.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = testQueries.GetTransferReversal(context.Background(), transfer.Transfer.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestInvertRate(t *testing.T) {
+	testCases := []struct {
+		rate     string
+		expected string
+	}{
+		{rate: "1", expected: "1"},
+		{rate: "0.5", expected: "2"},
+		{rate: "1.25", expected: "0.8"},
+		{rate: "3", expected: "0.3333333333"},
+	}
+
+	for _, testCase := range testCases {
+		inverse, err := invertRate(testCase.rate)
+		require.NoError(t, err)
+		require.Equal(t, testCase.expected, inverse)
+	}
+
+	_, err := invertRate("0")
+	require.Error(t, err)
+
+	_, err = invertRate("not a rate")
+	require.Error(t, err)
+}
+
+func TestStore_BulkTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 1000)
+	accountOne := createRandomAccountWithCurrency(t, 0, fromAccount.Currency)
+	accountTwo := createRandomAccountWithCurrency(t, 0, fromAccount.Currency)
+
+	result, err := store.BulkTransferTx(context.Background(), BulkTransferTxParams{
+		FromAccountID: int64(fromAccount.ID),
+		Legs: []BulkTransferLeg{
+			{ToAccountID: int64(accountTwo.ID), Amount: 100},
+			{ToAccountID: int64(accountOne.ID), Amount: 200},
+			{ToAccountID: int64(accountTwo.ID), Amount: 300},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NotZero(t, result.Batch.ID)
+	require.Equal(t, int64(600), result.Batch.TotalAmount)
+	require.Equal(t, int64(400), result.FromAccount.Balance)
+
+	// The legs keep the order of the request
+	require.Len(t, result.Legs, 3)
+	require.Equal(t, int64(accountTwo.ID), result.Legs[0].Transfer.ToAccountID)
+	require.Equal(t, int64(accountOne.ID), result.Legs[1].Transfer.ToAccountID)
+	require.Equal(t, int64(-300), result.Legs[2].FromEntry.Amount)
+	require.Equal(t, int64(300), result.Legs[2].ToEntry.Amount)
+
+	legs, err := testQueries.ListTransferBatchLegs(context.Background(), result.Batch.ID)
+	require.NoError(t, err)
+	require.Len(t, legs, 3)
+
+	for i, leg := range legs {
+		require.Equal(t, int32(i), leg.LegIndex)
+		require.Equal(t, result.Legs[i].Transfer.ID, leg.TransferID)
+	}
+
+	updatedAccountTwo, err := testQueries.GetAccount(context.Background(), accountTwo.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(400), updatedAccountTwo.Balance)
+}
+
+func TestStore_BulkTransferTxIsAtomic(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createRandomAccountWithBalance(t, 1000)
+	toAccount := createRandomAccountWithCurrency(t, 0, fromAccount.Currency)
+
+	// The legs fit the balance one by one but not together
+	_, err := store.BulkTransferTx(context.Background(), BulkTransferTxParams{
+		FromAccountID: int64(fromAccount.ID),
+		Legs: []BulkTransferLeg{
+			{ToAccountID: int64(toAccount.ID), Amount: 600},
+			{ToAccountID: int64(toAccount.ID), Amount: 600},
+		},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// A missing receiver fails the whole batch and names the leg
+	_, err = store.BulkTransferTx(context.Background(), BulkTransferTxParams{
+		FromAccountID: int64(fromAccount.ID),
+		Legs: []BulkTransferLeg{
+			{ToAccountID: int64(toAccount.ID), Amount: 100},
+			{ToAccountID: math.MaxInt32, Amount: 100},
+		},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	var legErr *LegError
+	require.ErrorAs(t, err, &legErr)
+	require.Equal(t, 1, legErr.Index)
+
+	updatedFromAccount, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updatedFromAccount.Balance)
+
+	updatedToAccount, err := testQueries.GetAccount(context.Background(), toAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, toAccount.Balance, updatedToAccount.Balance)
+}
+
+func TestStore_BulkTransferTxChargesFees(t *testing.T) {
+	store := NewStore(testDB)
+
+	defer func() {
+		_, _ = testQueries.DeleteFeeSchedule(context.Background(), util.CAD)
+	}()
+
+	_, err := testQueries.UpsertFeeSchedule(context.Background(), UpsertFeeScheduleParams{
+		Currency:              util.CAD,
+		Flat:                  10,
+		Percentage:            "0",
+		FreeTransfersPerMonth: 1,
+	})
+	require.NoError(t, err)
+
+	feeAccount, err := testQueries.GetFeeAccount(context.Background(), util.CAD)
+	require.NoError(t, err)
+
+	fromAccount := createRandomAccountWithCurrency(t, 305, util.CAD)
+	toAccount := createRandomAccountWithCurrency(t, 0, util.CAD)
+
+	legs := []BulkTransferLeg{
+		{ToAccountID: int64(toAccount.ID), Amount: 100},
+		{ToAccountID: int64(toAccount.ID), Amount: 100},
+		{ToAccountID: int64(toAccount.ID), Amount: 100},
+	}
+
+	// The amounts fit the balance but not with the fees of the legs that are not free
+	_, err = store.BulkTransferTx(context.Background(), BulkTransferTxParams{
+		FromAccountID: int64(fromAccount.ID),
+		Legs:          legs,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	result, err := store.BulkTransferTx(context.Background(), BulkTransferTxParams{
+		FromAccountID: int64(fromAccount.ID),
+		Legs:          legs[:2],
+	})
+	require.NoError(t, err)
+
+	// Only the first leg uses up the free transfer of the month
+	require.True(t, result.Legs[0].Fee.FreeTransfer)
+	require.Zero(t, result.Legs[0].Fee.Total)
+	require.Zero(t, result.Legs[0].Fee.FeeTransferID)
+
+	require.False(t, result.Legs[1].Fee.FreeTransfer)
+	require.Equal(t, int64(10), result.Legs[1].Fee.Total)
+	require.NotZero(t, result.Legs[1].Fee.FeeTransferID)
+
+	require.Equal(t, int64(200), result.Batch.TotalAmount)
+	require.Equal(t, int64(95), result.FromAccount.Balance)
+
+	fee, err := testQueries.GetTransferFee(context.Background(), result.Legs[1].Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), fee.Total)
+
+	feeTransfer, err := testQueries.GetTransfer(context.Background(), fee.FeeTransferID)
+	require.NoError(t, err)
+	require.Equal(t, int64(feeAccount.ID), feeTransfer.ToAccountID)
+
+	updatedFeeAccount, err := testQueries.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+10, updatedFeeAccount.Balance)
+}
+
+func TestStore_BulkTransferTxDeadlock(t *testing.T) {
+	store := NewStore(testDB)
+
+	accountOne := createRandomAccountWithBalance(t, 1000)
+	accountTwo := createRandomAccountWithCurrency(t, 1000, accountOne.Currency)
+	accountThree := createRandomAccountWithCurrency(t, 1000, accountOne.Currency)
+
+	// Concurrent batches pay the same accounts in opposite orders
+	n := 10
+	errsChan := make(chan error)
+
+	for i := 0; i < n; i++ {
+		arg := BulkTransferTxParams{
+			FromAccountID: int64(accountOne.ID),
+			Legs: []BulkTransferLeg{
+				{ToAccountID: int64(accountTwo.ID), Amount: 10},
+				{ToAccountID: int64(accountThree.ID), Amount: 10},
+			},
+		}
+
+		if i%2 == 1 {
+			arg = BulkTransferTxParams{
+				FromAccountID: int64(accountThree.ID),
+				Legs: []BulkTransferLeg{
+					{ToAccountID: int64(accountTwo.ID), Amount: 10},
+					{ToAccountID: int64(accountOne.ID), Amount: 10},
+				},
+			}
+		}
+
+		go func() {
+			_, err := store.BulkTransferTx(context.Background(), arg)
+			errsChan <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		err := <-errsChan
+		require.NoError(t, err)
+	}
+
+	updatedAccountTwo, err := testQueries.GetAccount(context.Background(), accountTwo.ID)
+	require.NoError(t, err)
+	require.Equal(t, accountTwo.Balance+int64(n)*10, updatedAccountTwo.Balance)
+}
+
+func TestStore_TransferTxLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	accountOne := createRandomAccountWithBalance(t, 1000)
+	accountTwo := createRandomAccountWithCurrency(t, 1000, accountOne.Currency)
+
+	createTransferLimit(t, util.TransferLimitUserScope, accountOne.Owner, accountOne.Currency, 100, 150, 1000)
+
+	arg := TransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		ToAccountID:   int64(accountTwo.ID),
+		Amount:        101,
+	}
+
+	var limitErr *TransferLimitError
+
+	// A single transfer above the per transaction limit
+	_, err := store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, util.PerTransactionLimit, limitErr.Period)
+
+	arg.Amount = 100
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// The second transfer of the day only has 50 left
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, util.DailyLimit, limitErr.Period)
+	require.Equal(t, int64(50), limitErr.Remaining)
+
+	// Bulk transfers count all their legs together
+	_, err = store.BulkTransferTx(context.Background(), BulkTransferTxParams{
+		FromAccountID: int64(accountOne.ID),
+		Legs: []BulkTransferLeg{
+			{ToAccountID: int64(accountTwo.ID), Amount: 30},
+			{ToAccountID: int64(accountTwo.ID), Amount: 30},
+		},
+	})
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, util.DailyLimit, limitErr.Period)
+
+	arg.Amount = 50
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// The receiver has no limit
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(accountTwo.ID),
+		ToAccountID:   int64(accountOne.ID),
+		Amount:        500,
+	})
+	require.NoError(t, err)
+}
+
+func TestStore_AcceptMoneyRequestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	requester := createRandomAccountWithBalance(t, 1000)
+	payer := createRandomAccountWithCurrency(t, 1000, requester.Currency)
+
+	request := createRandomMoneyRequest(t, requester, payer, time.Now().Add(time.Hour))
+
+	arg := AcceptMoneyRequestTxParams{
+		ID:            request.ID,
+		FromAccountID: int64(payer.ID),
+		ToAccountID:   int64(requester.ID),
+	}
+
+	result, err := store.AcceptMoneyRequestTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, util.MoneyRequestAccepted, result.MoneyRequest.Status)
+	require.Equal(t, result.Transfer.ID, result.MoneyRequest.TransferID.Int64)
+	require.Equal(t, request.Amount, result.Transfer.Amount)
+	require.Equal(t, payer.Balance-request.Amount, result.FromAccount.Balance)
+	require.Equal(t, requester.Balance+request.Amount, result.ToAccount.Balance)
+
+	// A request is paid at most once
+	_, err = store.AcceptMoneyRequestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrMoneyRequestNotPending)
+
+	// Requests the worker has not expired yet can no longer be accepted
+	expired := createRandomMoneyRequest(t, requester, payer, time.Now().Add(-time.Minute))
+	arg.ID = expired.ID
+
+	_, err = store.AcceptMoneyRequestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrMoneyRequestExpired)
+
+	account, err := testQueries.GetAccount(context.Background(), payer.ID)
+	require.NoError(t, err)
+	require.Equal(t, payer.Balance-request.Amount, account.Balance)
+}
+
+func TestStore_DepositAndWithdrawTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccountWithBalance(t, 0)
+
+	settlementBefore, err := testQueries.GetSettlementAccount(context.Background(), account.Currency)
+	require.NoError(t, err)
+
+	deposit, err := store.DepositTx(context.Background(), CashTxParams{
+		AccountID: int64(account.ID),
+		Amount:    500,
+		Memo:      "Cash at branch",
+		PostedBy:  "teller",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(settlementBefore.ID), deposit.Transfer.FromAccountID)
+	require.Equal(t, int64(account.ID), deposit.Transfer.ToAccountID)
+	require.Equal(t, "Cash at branch", deposit.Transfer.Memo)
+	require.JSONEq(t, `{"posted_by": "teller"}`, string(deposit.Transfer.Metadata))
+	require.Equal(t, int64(500), deposit.ToAccount.Balance)
+	require.Equal(t, int64(500), deposit.ToEntry.Amount)
+	require.Equal(t, int64(-500), deposit.FromEntry.Amount)
+
+	withdrawal, err := store.WithdrawTx(context.Background(), CashTxParams{
+		AccountID: int64(account.ID),
+		Amount:    200,
+		PostedBy:  "teller",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(account.ID), withdrawal.Transfer.FromAccountID)
+	require.Equal(t, int64(300), withdrawal.FromAccount.Balance)
+
+	// Every entry has its counterpart on the settlement account so the ledger sums to zero
+	settlementAfter, err := testQueries.GetSettlementAccount(context.Background(), account.Currency)
+	require.NoError(t, err)
+	require.Equal(t, int64(300), settlementBefore.Balance-settlementAfter.Balance)
+
+	_, err = store.WithdrawTx(context.Background(), CashTxParams{
+		AccountID: int64(account.ID),
+		Amount:    301,
+		PostedBy:  "teller",
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.DepositTx(context.Background(), CashTxParams{
+		AccountID: int64(settlementAfter.ID),
+		Amount:    100,
+		PostedBy:  "teller",
+	})
+	require.ErrorIs(t, err, ErrCashOnSettlementAccount)
+
+	// Staff cannot deposit cash into their own accounts
+	_, err = store.DepositTx(context.Background(), CashTxParams{
+		AccountID: int64(account.ID),
+		Amount:    100,
+		PostedBy:  account.Owner,
+	})
+	require.ErrorIs(t, err, ErrCashOnOwnAccount)
+}
+
+func TestStore_DepositApprovalTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccountWithBalance(t, 0)
+	teller := createRandomUser(t)
+	officer := createRandomUser(t)
+
+	settlementBefore, err := testQueries.GetSettlementAccount(context.Background(), account.Currency)
+	require.NoError(t, err)
+
+	arg := RequestTransferApprovalTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: int64(settlementBefore.ID),
+			ToAccountID:   int64(account.ID),
+			Amount:        5000,
+			Metadata:      json.RawMessage(`{"posted_by": "` + teller.Username + `"}`),
+		},
+		RequestedBy: teller.Username,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	// The settlement account has no floor, the deposit is held without checking its balance
+	requested, err := store.RequestTransferApprovalTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// The owner of the account cannot approve the deposit
+	_, err = store.ApproveTransferTx(context.Background(), DecideTransferTxParams{
+		ID:           requested.Approval.ID,
+		DecidedBy:    account.Owner,
+		CanDecideAny: true,
+	})
+	require.ErrorIs(t, err, ErrCashOnOwnAccount)
+
+	approved, err := store.ApproveTransferTx(context.Background(), DecideTransferTxParams{
+		ID:           requested.Approval.ID,
+		DecidedBy:    officer.Username,
+		CanDecideAny: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(5000), approved.ToAccount.Balance)
+	require.Zero(t, approved.Fee.Total)
+
+	settlementAfter, err := testQueries.GetSettlementAccount(context.Background(), account.Currency)
+	require.NoError(t, err)
+	require.Equal(t, int64(5000), settlementBefore.Balance-settlementAfter.Balance)
+	require.Equal(t, settlementBefore.Held, settlementAfter.Held)
+
+	// Nor can a teller request a deposit into their own account
+	arg.RequestedBy = account.Owner
+
+	_, err = store.RequestTransferApprovalTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrCashOnOwnAccount)
+}
+
+func TestStore_TransferApprovalTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	from := createRandomAccountWithBalance(t, 1000)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+	officer := createRandomAccount(t)
+
+	arg := RequestTransferApprovalTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: int64(from.ID),
+			ToAccountID:   int64(to.ID),
+			Amount:        600,
+			Memo:          "Equipment",
+		},
+		RequestedBy:    from.Owner,
+		IdempotencyKey: util.RandomString(16),
+		RequestHash:    "hash",
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}
+
+	requested, err := store.RequestTransferApprovalTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, util.TransferApprovalPending, requested.Approval.Status)
+	require.Equal(t, from.Balance, requested.FromAccount.Balance)
+	require.Equal(t, int64(600), requested.FromAccount.Held)
+
+	// Retries with the same key get the first approval back without holding the money twice
+	replayed, err := store.RequestTransferApprovalTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, requested.Approval.ID, replayed.Approval.ID)
+	require.Equal(t, int64(600), replayed.FromAccount.Held)
+
+	// Held money cannot be spent
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(from.ID),
+		ToAccountID:   int64(to.ID),
+		Amount:        500,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	decide := DecideTransferTxParams{
+		ID:           requested.Approval.ID,
+		DecidedBy:    from.Owner,
+		CanDecideAny: true,
+	}
+
+	// The requester cannot approve their own transfer
+	_, err = store.ApproveTransferTx(context.Background(), decide)
+	require.ErrorIs(t, err, ErrApproverIsRequester)
+
+	decide.DecidedBy = officer.Owner
+
+	approved, err := store.ApproveTransferTx(context.Background(), decide)
+	require.NoError(t, err)
+	require.Equal(t, util.TransferApprovalApproved, approved.Approval.Status)
+	require.Equal(t, officer.Owner, approved.Approval.DecidedBy.String)
+	require.Equal(t, approved.Transfer.ID, approved.Approval.TransferID.Int64)
+	require.Equal(t, "Equipment", approved.Transfer.Memo)
+	require.Equal(t, from.Balance-600, approved.FromAccount.Balance)
+	require.Zero(t, approved.FromAccount.Held)
+	require.Equal(t, to.Balance+600, approved.ToAccount.Balance)
+
+	// A transfer is approved at most once
+	_, err = store.ApproveTransferTx(context.Background(), decide)
+	require.ErrorIs(t, err, ErrTransferApprovalNotPending)
+
+	_, err = store.RejectTransferTx(context.Background(), decide)
+	require.ErrorIs(t, err, ErrTransferApprovalNotPending)
+}
+
+func TestStore_RejectTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	from := createRandomAccountWithBalance(t, 1000)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+	officer := createRandomAccount(t)
+
+	requested, err := store.RequestTransferApprovalTx(context.Background(), RequestTransferApprovalTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: int64(from.ID),
+			ToAccountID:   int64(to.ID),
+			Amount:        600,
+		},
+		RequestedBy: from.Owner,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Only money that is not held can be requested
+	_, err = store.RequestTransferApprovalTx(context.Background(), RequestTransferApprovalTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: int64(from.ID),
+			ToAccountID:   int64(to.ID),
+			Amount:        600,
+		},
+		RequestedBy: from.Owner,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	rejected, err := store.RejectTransferTx(context.Background(), DecideTransferTxParams{
+		ID:           requested.Approval.ID,
+		DecidedBy:    officer.Owner,
+		CanDecideAny: true,
+		Reason:       "Unknown recipient",
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.TransferApprovalRejected, rejected.Status)
+	require.Equal(t, "Unknown recipient", rejected.Reason)
+	require.False(t, rejected.TransferID.Valid)
+
+	account, err := testQueries.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, account.Balance)
+	require.Zero(t, account.Held)
+}
+
+func TestStore_ApproveTransferTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+
+	from := createRandomAccountWithBalance(t, 1000)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+	officer := createRandomAccount(t)
+
+	requested, err := store.RequestTransferApprovalTx(context.Background(), RequestTransferApprovalTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: int64(from.ID),
+			ToAccountID:   int64(to.ID),
+			Amount:        600,
+		},
+		RequestedBy: from.Owner,
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	// Approvals the worker has not expired yet can no longer be approved
+	_, err = store.ApproveTransferTx(context.Background(), DecideTransferTxParams{
+		ID:           requested.Approval.ID,
+		DecidedBy:    officer.Owner,
+		CanDecideAny: true,
+	})
+	require.ErrorIs(t, err, ErrTransferApprovalExpired)
+
+	err = store.ExpireTransferApprovalsTx(context.Background())
+	require.NoError(t, err)
+
+	account, err := testQueries.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.Held)
+}
+
+func TestStore_ApproveTransferTxCoOwner(t *testing.T) {
+	store := NewStore(testDB)
+
+	from := createRandomAccountWithBalance(t, 1000)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+	stranger := createRandomUser(t)
+	coOwner := createRandomAccountCoOwner(t, from)
+
+	requested, err := store.RequestTransferApprovalTx(context.Background(), RequestTransferApprovalTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: int64(from.ID),
+			ToAccountID:   int64(to.ID),
+			Amount:        600,
+		},
+		RequestedBy: from.Owner,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Users who cannot approve any transfer must co-own the sender account
+	decide := DecideTransferTxParams{
+		ID:        requested.Approval.ID,
+		DecidedBy: stranger.Username,
+	}
+
+	_, err = store.ApproveTransferTx(context.Background(), decide)
+	require.ErrorIs(t, err, ErrApproverNotCoOwner)
+
+	_, err = store.RejectTransferTx(context.Background(), decide)
+	require.ErrorIs(t, err, ErrApproverNotCoOwner)
+
+	decide.DecidedBy = coOwner.Username
+
+	approved, err := store.ApproveTransferTx(context.Background(), decide)
+	require.NoError(t, err)
+	require.Equal(t, util.TransferApprovalApproved, approved.Approval.Status)
+	require.Equal(t, coOwner.Username, approved.Approval.DecidedBy.String)
+	require.Equal(t, from.Balance-600, approved.FromAccount.Balance)
+}
+
+func TestStore_TransferTxFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	// Schedules apply to every transfer in the currency, they are removed once checked
+	defer func() {
+		_, _ = testQueries.DeleteFeeSchedule(context.Background(), util.EUR)
+	}()
+
+	_, err := testQueries.UpsertFeeSchedule(context.Background(), UpsertFeeScheduleParams{
+		Currency:              util.EUR,
+		Flat:                  10,
+		Percentage:            "2",
+		MinFee:                25,
+		MaxFee:                100,
+		FreeTransfersPerMonth: 1,
+	})
+	require.NoError(t, err)
+
+	feeAccount, err := testQueries.GetFeeAccount(context.Background(), util.EUR)
+	require.NoError(t, err)
+
+	from := createRandomAccountWithCurrency(t, 1000, util.EUR)
+	to := createRandomAccountWithCurrency(t, 0, util.EUR)
+
+	arg := TransferTxParams{
+		FromAccountID: int64(from.ID),
+		ToAccountID:   int64(to.ID),
+		Amount:        500,
+	}
+
+	// The first transfer of the month is free
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.Fee.FreeTransfer)
+	require.Zero(t, result.Fee.FreeTransfersLeft)
+	require.Zero(t, result.Fee.Total)
+	require.Zero(t, result.Fee.FeeTransferID)
+	require.Equal(t, int64(500), result.FromAccount.Balance)
+
+	_, err = testQueries.GetTransferFee(context.Background(), result.Transfer.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// The second one is charged 10 + 2% of 100 = 12, raised to the minimum of 25
+	arg.Amount = 100
+
+	result, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, result.Fee.FreeTransfer)
+	require.Equal(t, util.EUR, result.Fee.Currency)
+	require.Equal(t, int64(10), result.Fee.Flat)
+	require.Equal(t, int64(2), result.Fee.PercentageAmount)
+	require.Equal(t, int64(13), result.Fee.Adjustment)
+	require.Equal(t, int64(25), result.Fee.Total)
+	require.Equal(t, int64(375), result.FromAccount.Balance)
+	require.Equal(t, int64(600), result.ToAccount.Balance)
+
+	feeTransfer, err := testQueries.GetTransfer(context.Background(), result.Fee.FeeTransferID)
+	require.NoError(t, err)
+	require.Equal(t, int64(from.ID), feeTransfer.FromAccountID)
+	require.Equal(t, int64(feeAccount.ID), feeTransfer.ToAccountID)
+	require.Equal(t, int64(25), feeTransfer.Amount)
+
+	fee, err := testQueries.GetTransferFee(context.Background(), result.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Fee.FeeTransferID, fee.FeeTransferID)
+	require.Equal(t, result.Fee.Total, fee.Total)
+
+	updatedFeeAccount, err := testQueries.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(25), updatedFeeAccount.Balance-feeAccount.Balance)
+
+	// Fees are not counted as transfers of the sender
+	stats, err := testQueries.GetOutgoingTransferStatsSince(context.Background(), GetOutgoingTransferStatsSinceParams{
+		Owner:       from.Owner,
+		Currency:    util.EUR,
+		CreatedFrom: from.CreatedAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), stats.Count)
+	require.Equal(t, int64(600), stats.Total)
+
+	// The sender must be able to cover the fee on top of the amount
+	arg.Amount = 360
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestStore_PendingTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	from := createRandomAccountWithBalance(t, 1000)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+
+	pending, err := store.CreatePendingTransferTx(context.Background(), PendingTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: int64(from.ID),
+			ToAccountID:   int64(to.ID),
+			Amount:        600,
+			Memo:          "Hotel",
+		},
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.TransferPending, pending.Transfer.Status)
+	require.False(t, pending.Transfer.PostedAt.Valid)
+	require.Equal(t, int64(600), pending.Hold.Amount)
+	require.Equal(t, from.Balance, pending.FromAccount.Balance)
+	require.Equal(t, int64(600), pending.FromAccount.Held)
+	require.Equal(t, from.Balance-600, pending.FromAccount.AvailableBalance)
+
+	// Held money cannot be spent
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: int64(from.ID),
+		ToAccountID:   int64(to.ID),
+		Amount:        500,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// Only the amount held can be posted
+	_, err = store.PostTransferTx(context.Background(), PostTransferTxParams{
+		ID:     pending.Transfer.ID,
+		Amount: 601,
+	})
+	require.ErrorIs(t, err, ErrPostAmountExceedsHold)
+
+	// The rest of the money held is released when the transfer is posted for less
+	posted, err := store.PostTransferTx(context.Background(), PostTransferTxParams{
+		ID:     pending.Transfer.ID,
+		Amount: 450,
+	})
+	require.NoError(t, err)
+	require.Equal(t, pending.Transfer.ID, posted.Transfer.ID)
+	require.Equal(t, util.TransferPosted, posted.Transfer.Status)
+	require.True(t, posted.Transfer.PostedAt.Valid)
+	require.Equal(t, int64(450), posted.Transfer.Amount)
+	require.Equal(t, int64(450), posted.Transfer.ToAmount)
+	require.Equal(t, "Hotel", posted.Transfer.Memo)
+	require.Equal(t, int64(-450), posted.FromEntry.Amount)
+	require.Equal(t, int64(450), posted.ToEntry.Amount)
+	require.Equal(t, from.Balance-450, posted.FromAccount.Balance)
+	require.Zero(t, posted.FromAccount.Held)
+	require.Equal(t, from.Balance-450, posted.FromAccount.AvailableBalance)
+	require.Equal(t, to.Balance+450, posted.ToAccount.Balance)
+
+	// The entries are booked when the transfer is posted
+	require.Equal(t, posted.Transfer.PostedAt.Time, posted.FromEntry.CreatedAt)
+
+	// A transfer is posted at most once
+	_, err = store.PostTransferTx(context.Background(), PostTransferTxParams{ID: pending.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotPending)
+
+	_, err = store.VoidTransferTx(context.Background(), pending.Transfer.ID)
+	require.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestStore_VoidTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	from := createRandomAccountWithBalance(t, 1000)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+
+	pending, err := store.CreatePendingTransferTx(context.Background(), PendingTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: int64(from.ID),
+			ToAccountID:   int64(to.ID),
+			Amount:        600,
+		},
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Only money that is not held can be reserved
+	_, err = store.CreatePendingTransferTx(context.Background(), PendingTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: int64(from.ID),
+			ToAccountID:   int64(to.ID),
+			Amount:        500,
+		},
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// Pending transfers have not moved any money to give back
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: pending.Transfer.ID,
+		Reason:     "Duplicate",
+		ReversedBy: from.Owner,
+	})
+	require.ErrorIs(t, err, ErrTransferNotPosted)
+
+	voided, err := store.VoidTransferTx(context.Background(), pending.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.TransferVoided, voided.Transfer.Status)
+	require.False(t, voided.Transfer.PostedAt.Valid)
+	require.Equal(t, from.Balance, voided.FromAccount.Balance)
+	require.Zero(t, voided.FromAccount.Held)
+
+	_, err = store.PostTransferTx(context.Background(), PostTransferTxParams{ID: pending.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotPending)
+
+	account, err := testQueries.GetAccount(context.Background(), to.ID)
+	require.NoError(t, err)
+	require.Equal(t, to.Balance, account.Balance)
+}
+
+func TestStore_PostTransferTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+
+	from := createRandomAccountWithBalance(t, 1000)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+
+	pending, err := store.CreatePendingTransferTx(context.Background(), PendingTransferTxParams{
+		TransferTxParams: TransferTxParams{
+			FromAccountID: int64(from.ID),
+			ToAccountID:   int64(to.ID),
+			Amount:        600,
+		},
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	// Transfers the worker has not voided yet can no longer be posted
+	_, err = store.PostTransferTx(context.Background(), PostTransferTxParams{ID: pending.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferHoldExpired)
+
+	err = store.ExpirePendingTransfersTx(context.Background())
+	require.NoError(t, err)
+
+	account, err := testQueries.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.Held)
+
+	transfer, err := testQueries.GetTransfer(context.Background(), pending.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.TransferVoided, transfer.Status)
+}
+
+func TestStore_ExpirePendingTransfersTxDeadlock(t *testing.T) {
+	store := NewStore(testDB)
+
+	accountOne := createRandomAccountWithBalance(t, 1000)
+	accountTwo := createRandomAccountWithCurrency(t, 1000, accountOne.Currency)
+
+	// Both accounts hold money for expired transfers, the one with the higher ID first
+	for _, arg := range []TransferTxParams{
+		{FromAccountID: int64(accountTwo.ID), ToAccountID: int64(accountOne.ID), Amount: 100},
+		{FromAccountID: int64(accountOne.ID), ToAccountID: int64(accountTwo.ID), Amount: 100},
+	} {
+		_, err := store.CreatePendingTransferTx(context.Background(), PendingTransferTxParams{
+			TransferTxParams: arg,
+			ExpiresAt:        time.Now().Add(-time.Minute),
+		})
+		require.NoError(t, err)
+	}
+
+	// Expiring the holds runs alongside transfers between the same accounts in both directions
+	n := 10
+	errsChan := make(chan error)
+
+	for i := 0; i < n; i++ {
+		fromAccountID, toAccountID := int64(accountOne.ID), int64(accountTwo.ID)
+
+		if i%2 == 1 {
+			fromAccountID, toAccountID = toAccountID, fromAccountID
+		}
+
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: fromAccountID,
+				ToAccountID:   toAccountID,
+				Amount:        10,
+			})
+			errsChan <- err
+		}()
+
+		go func() {
+			errsChan <- store.ExpirePendingTransfersTx(context.Background())
+		}()
+	}
+
+	for i := 0; i < 2*n; i++ {
+		err := <-errsChan
+		require.NoError(t, err)
+	}
+
+	for _, account := range []Account{accountOne, accountTwo} {
+		updated, err := testQueries.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, updated.Balance)
+		require.Zero(t, updated.Held)
+	}
+}
@@ -5,24 +5,125 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
 )
 
+const countTransfersToAccount = `-- name: CountTransfersToAccount :one
+SELECT COUNT(*)
+FROM transfers
+         JOIN accounts ON accounts.id = transfers.from_account_id
+WHERE accounts.owner = $1
+  AND transfers.to_account_id = $2
+`
+
+type CountTransfersToAccountParams struct {
+	Owner       string `json:"owner"`
+	ToAccountID int64  `json:"toAccountID"`
+}
+
+func (q *Queries) CountTransfersToAccount(ctx context.Context, arg CountTransfersToAccountParams) (int64, error) {
+	row := q.queryRow(ctx, q.countTransfersToAccountStmt, countTransfersToAccount, arg.Owner, arg.ToAccountID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createPendingTransfer = `-- name: CreatePendingTransfer :one
+INSERT INTO transfers (from_account_id,
+                       to_account_id,
+                       amount,
+                       to_amount,
+                       exchange_rate,
+                       memo,
+                       reference,
+                       metadata,
+                       status,
+                       posted_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'pending', NULL)
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, memo, reference, metadata, status, posted_at, journal_id
+`
+
+type CreatePendingTransferParams struct {
+	FromAccountID int64           `json:"fromAccountID"`
+	ToAccountID   int64           `json:"toAccountID"`
+	Amount        int64           `json:"amount"`
+	ToAmount      int64           `json:"toAmount"`
+	ExchangeRate  string          `json:"exchangeRate"`
+	Memo          string          `json:"memo"`
+	Reference     string          `json:"reference"`
+	Metadata      json.RawMessage `json:"metadata"`
+}
+
+func (q *Queries) CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (Transfer, error) {
+	row := q.queryRow(ctx, q.createPendingTransferStmt, createPendingTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.Memo,
+		arg.Reference,
+		arg.Metadata,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Memo,
+		&i.Reference,
+		&i.Metadata,
+		&i.Status,
+		&i.PostedAt,
+		&i.JournalID,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (from_account_id,
                        to_account_id,
-                       amount)
-VALUES ($1, $2, $3)
-RETURNING id, from_account_id, to_account_id, amount, created_at
+                       amount,
+                       to_amount,
+                       exchange_rate,
+                       memo,
+                       reference,
+                       metadata,
+                       journal_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, memo, reference, metadata, status, posted_at, journal_id
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"fromAccountID"`
-	ToAccountID   int64 `json:"toAccountID"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64           `json:"fromAccountID"`
+	ToAccountID   int64           `json:"toAccountID"`
+	Amount        int64           `json:"amount"`
+	ToAmount      int64           `json:"toAmount"`
+	ExchangeRate  string          `json:"exchangeRate"`
+	Memo          string          `json:"memo"`
+	Reference     string          `json:"reference"`
+	Metadata      json.RawMessage `json:"metadata"`
+	JournalID     sql.NullInt64   `json:"journalID"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.queryRow(ctx, q.createTransferStmt, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.queryRow(ctx, q.createTransferStmt, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.Memo,
+		arg.Reference,
+		arg.Metadata,
+		arg.JournalID,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -30,19 +131,135 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Memo,
+		&i.Reference,
+		&i.Metadata,
+		&i.Status,
+		&i.PostedAt,
+		&i.JournalID,
+	)
+	return i, err
+}
+
+const expirePendingTransfers = `-- name: ExpirePendingTransfers :exec
+WITH expired AS (
+    UPDATE transfers
+        SET status = 'voided'
+        FROM transfer_holds
+        WHERE transfer_holds.transfer_id = transfers.id
+            AND transfers.status = 'pending'
+            AND transfer_holds.expires_at <= now()
+        RETURNING transfers.from_account_id, transfers.amount)
+UPDATE accounts
+SET held = held - released.amount
+FROM (SELECT from_account_id, SUM(amount)::bigint AS amount
+      FROM expired
+      GROUP BY from_account_id) AS released
+WHERE accounts.id = released.from_account_id
+`
+
+func (q *Queries) ExpirePendingTransfers(ctx context.Context) error {
+	_, err := q.exec(ctx, q.expirePendingTransfersStmt, expirePendingTransfers)
+	return err
+}
+
+const getOutgoingTransferStatsSince = `-- name: GetOutgoingTransferStatsSince :one
+SELECT COUNT(*) AS count,
+       COALESCE(SUM(transfers.amount), 0)::bigint AS total
+FROM transfers
+         JOIN accounts ON accounts.id = transfers.from_account_id
+WHERE accounts.owner = $1
+  AND accounts.currency = $2
+  AND transfers.created_at >= $3
+  AND transfers.status = 'posted'
+  AND NOT EXISTS(SELECT 1 FROM transfer_fees WHERE transfer_fees.fee_transfer_id = transfers.id)
+  AND NOT EXISTS(SELECT 1 FROM transfer_reversals WHERE transfer_reversals.reversal_transfer_id = transfers.id)
+`
+
+type GetOutgoingTransferStatsSinceParams struct {
+	Owner       string    `json:"owner"`
+	Currency    string    `json:"currency"`
+	CreatedFrom time.Time `json:"createdFrom"`
+}
+
+type GetOutgoingTransferStatsSinceRow struct {
+	Count int64 `json:"count"`
+	Total int64 `json:"total"`
+}
+
+func (q *Queries) GetOutgoingTransferStatsSince(ctx context.Context, arg GetOutgoingTransferStatsSinceParams) (GetOutgoingTransferStatsSinceRow, error) {
+	row := q.queryRow(ctx, q.getOutgoingTransferStatsSinceStmt, getOutgoingTransferStatsSince, arg.Owner, arg.Currency, arg.CreatedFrom)
+	var i GetOutgoingTransferStatsSinceRow
+	err := row.Scan(
+		&i.Count,
+		&i.Total,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT transfers.id, transfers.from_account_id, transfers.to_account_id, transfers.amount, transfers.created_at, transfers.to_amount, transfers.exchange_rate, transfers.memo, transfers.reference, transfers.metadata, transfers.status, transfers.posted_at, transfers.journal_id,
+       COALESCE(reversed_by.reversal_transfer_id, 0)::bigint AS reversed_by_transfer_id,
+       COALESCE(reversal_of.transfer_id, 0)::bigint          AS reversal_of_transfer_id
 FROM transfers
-WHERE id = $1
+         LEFT JOIN transfer_reversals AS reversed_by ON reversed_by.transfer_id = transfers.id
+         LEFT JOIN transfer_reversals AS reversal_of ON reversal_of.reversal_transfer_id = transfers.id
+WHERE transfers.id = $1
 LIMIT 1
 `
 
-func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
+type GetTransferRow struct {
+	ID                   int64           `json:"id"`
+	FromAccountID        int64           `json:"fromAccountID"`
+	ToAccountID          int64           `json:"toAccountID"`
+	Amount               int64           `json:"amount"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	ToAmount             int64           `json:"toAmount"`
+	ExchangeRate         string          `json:"exchangeRate"`
+	Memo                 string          `json:"memo"`
+	Reference            string          `json:"reference"`
+	Metadata             json.RawMessage `json:"metadata"`
+	Status               string          `json:"status"`
+	PostedAt             sql.NullTime    `json:"postedAt"`
+	JournalID            sql.NullInt64   `json:"journalID"`
+	ReversedByTransferID int64           `json:"reversedByTransferID"`
+	ReversalOfTransferID int64           `json:"reversalOfTransferID"`
+}
+
+func (q *Queries) GetTransfer(ctx context.Context, id int64) (GetTransferRow, error) {
 	row := q.queryRow(ctx, q.getTransferStmt, getTransfer, id)
+	var i GetTransferRow
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Memo,
+		&i.Reference,
+		&i.Metadata,
+		&i.Status,
+		&i.PostedAt,
+		&i.JournalID,
+		&i.ReversedByTransferID,
+		&i.ReversalOfTransferID,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, memo, reference, metadata, status, posted_at, journal_id
+FROM transfers
+WHERE id = $1
+LIMIT 1 FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.queryRow(ctx, q.getTransferForUpdateStmt, getTransferForUpdate, id)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -50,12 +267,174 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Memo,
+		&i.Reference,
+		&i.Metadata,
+		&i.Status,
+		&i.PostedAt,
+		&i.JournalID,
 	)
 	return i, err
 }
 
+const listExpiredPendingTransfersForUpdate = `-- name: ListExpiredPendingTransfersForUpdate :many
+SELECT transfers.id, transfers.from_account_id, transfers.to_account_id, transfers.amount, transfers.created_at, transfers.to_amount, transfers.exchange_rate, transfers.memo, transfers.reference, transfers.metadata, transfers.status, transfers.posted_at, transfers.journal_id
+FROM transfers
+         JOIN transfer_holds ON transfer_holds.transfer_id = transfers.id
+WHERE transfers.status = 'pending'
+  AND transfer_holds.expires_at <= now()
+ORDER BY transfers.id
+FOR NO KEY UPDATE OF transfers
+`
+
+func (q *Queries) ListExpiredPendingTransfersForUpdate(ctx context.Context) ([]Transfer, error) {
+	rows, err := q.query(ctx, q.listExpiredPendingTransfersForUpdateStmt, listExpiredPendingTransfersForUpdate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.Memo,
+			&i.Reference,
+			&i.Metadata,
+			&i.Status,
+			&i.PostedAt,
+			&i.JournalID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOwnerTransfers = `-- name: ListOwnerTransfers :many
+SELECT transfers.id, transfers.from_account_id, transfers.to_account_id, transfers.amount, transfers.created_at, transfers.to_amount, transfers.exchange_rate, transfers.memo, transfers.reference, transfers.metadata, transfers.status, transfers.posted_at, transfers.journal_id,
+       COALESCE(reversed_by.reversal_transfer_id, 0)::bigint AS reversed_by_transfer_id,
+       COALESCE(reversal_of.transfer_id, 0)::bigint          AS reversal_of_transfer_id
+FROM transfers
+         JOIN accounts AS from_accounts ON from_accounts.id = transfers.from_account_id
+         JOIN accounts AS to_accounts ON to_accounts.id = transfers.to_account_id
+         LEFT JOIN transfer_reversals AS reversed_by ON reversed_by.transfer_id = transfers.id
+         LEFT JOIN transfer_reversals AS reversal_of ON reversal_of.reversal_transfer_id = transfers.id
+WHERE ((from_accounts.owner = $1 AND $2::varchar <> 'in' AND
+        ($3::bigint = 0 OR transfers.from_account_id = $3))
+    OR (to_accounts.owner = $1 AND $2::varchar <> 'out' AND
+        ($3::bigint = 0 OR transfers.to_account_id = $3)))
+  AND transfers.created_at >= $4
+  AND transfers.created_at < $5
+  AND transfers.amount >= $6
+  AND transfers.amount <= $7
+  AND ($8::varchar = '' OR strpos(lower(transfers.memo), lower($8)) > 0 OR
+       strpos(lower(transfers.reference), lower($8)) > 0)
+  AND transfers.metadata @> $9::jsonb
+ORDER BY transfers.id DESC
+LIMIT $10 OFFSET $11
+`
+
+type ListOwnerTransfersParams struct {
+	Owner       string          `json:"owner"`
+	Direction   string          `json:"direction"`
+	AccountID   int64           `json:"accountID"`
+	CreatedFrom time.Time       `json:"createdFrom"`
+	CreatedTo   time.Time       `json:"createdTo"`
+	MinAmount   int64           `json:"minAmount"`
+	MaxAmount   int64           `json:"maxAmount"`
+	Search      string          `json:"search"`
+	Metadata    json.RawMessage `json:"metadata"`
+	Limit       int32           `json:"limit"`
+	Offset      int32           `json:"offset"`
+}
+
+type ListOwnerTransfersRow struct {
+	ID                   int64           `json:"id"`
+	FromAccountID        int64           `json:"fromAccountID"`
+	ToAccountID          int64           `json:"toAccountID"`
+	Amount               int64           `json:"amount"`
+	CreatedAt            time.Time       `json:"createdAt"`
+	ToAmount             int64           `json:"toAmount"`
+	ExchangeRate         string          `json:"exchangeRate"`
+	Memo                 string          `json:"memo"`
+	Reference            string          `json:"reference"`
+	Metadata             json.RawMessage `json:"metadata"`
+	Status               string          `json:"status"`
+	PostedAt             sql.NullTime    `json:"postedAt"`
+	JournalID            sql.NullInt64   `json:"journalID"`
+	ReversedByTransferID int64           `json:"reversedByTransferID"`
+	ReversalOfTransferID int64           `json:"reversalOfTransferID"`
+}
+
+func (q *Queries) ListOwnerTransfers(ctx context.Context, arg ListOwnerTransfersParams) ([]ListOwnerTransfersRow, error) {
+	rows, err := q.query(ctx, q.listOwnerTransfersStmt, listOwnerTransfers,
+		arg.Owner,
+		arg.Direction,
+		arg.AccountID,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Search,
+		arg.Metadata,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOwnerTransfersRow{}
+	for rows.Next() {
+		var i ListOwnerTransfersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.Memo,
+			&i.Reference,
+			&i.Metadata,
+			&i.Status,
+			&i.PostedAt,
+			&i.JournalID,
+			&i.ReversedByTransferID,
+			&i.ReversalOfTransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, memo, reference, metadata, status, posted_at, journal_id
 FROM transfers
 WHERE from_account_id = $1
    OR to_account_id = $2
@@ -90,6 +469,14 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.Memo,
+			&i.Reference,
+			&i.Metadata,
+			&i.Status,
+			&i.PostedAt,
+			&i.JournalID,
 		); err != nil {
 			return nil, err
 		}
@@ -103,3 +490,100 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	}
 	return items, nil
 }
+
+const postPendingTransfer = `-- name: PostPendingTransfer :one
+UPDATE transfers
+SET status     = 'posted',
+    amount     = $1,
+    to_amount  = $2,
+    journal_id = $3,
+    posted_at  = now()
+WHERE id = $4
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, memo, reference, metadata, status, posted_at, journal_id
+`
+
+type PostPendingTransferParams struct {
+	Amount    int64         `json:"amount"`
+	ToAmount  int64         `json:"toAmount"`
+	JournalID sql.NullInt64 `json:"journalID"`
+	ID        int64         `json:"id"`
+}
+
+func (q *Queries) PostPendingTransfer(ctx context.Context, arg PostPendingTransferParams) (Transfer, error) {
+	row := q.queryRow(ctx, q.postPendingTransferStmt, postPendingTransfer,
+		arg.Amount,
+		arg.ToAmount,
+		arg.JournalID,
+		arg.ID,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Memo,
+		&i.Reference,
+		&i.Metadata,
+		&i.Status,
+		&i.PostedAt,
+		&i.JournalID,
+	)
+	return i, err
+}
+
+const sumOutgoingTransfersSince = `-- name: SumOutgoingTransfersSince :one
+SELECT COALESCE(SUM(transfers.amount), 0)::bigint AS total
+FROM transfers
+         JOIN accounts ON accounts.id = transfers.from_account_id
+WHERE accounts.owner = $1
+  AND accounts.currency = $2
+  AND transfers.created_at >= $3
+  AND transfers.status <> 'voided'
+  AND NOT EXISTS(SELECT 1 FROM transfer_fees WHERE transfer_fees.fee_transfer_id = transfers.id)
+  AND NOT EXISTS(SELECT 1 FROM transfer_reversals WHERE transfer_reversals.reversal_transfer_id = transfers.id)
+`
+
+type SumOutgoingTransfersSinceParams struct {
+	Owner       string    `json:"owner"`
+	Currency    string    `json:"currency"`
+	CreatedFrom time.Time `json:"createdFrom"`
+}
+
+func (q *Queries) SumOutgoingTransfersSince(ctx context.Context, arg SumOutgoingTransfersSinceParams) (int64, error) {
+	row := q.queryRow(ctx, q.sumOutgoingTransfersSinceStmt, sumOutgoingTransfersSince, arg.Owner, arg.Currency, arg.CreatedFrom)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const voidPendingTransfer = `-- name: VoidPendingTransfer :one
+UPDATE transfers
+SET status = 'voided'
+WHERE id = $1
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, memo, reference, metadata, status, posted_at, journal_id
+`
+
+func (q *Queries) VoidPendingTransfer(ctx context.Context, id int64) (Transfer, error) {
+	row := q.queryRow(ctx, q.voidPendingTransferStmt, voidPendingTransfer, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Memo,
+		&i.Reference,
+		&i.Metadata,
+		&i.Status,
+		&i.PostedAt,
+		&i.JournalID,
+	)
+	return i, err
+}
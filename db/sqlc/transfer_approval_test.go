@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomTransferApproval(t *testing.T, from, to Account, expiresAt time.Time) TransferApproval {
+	arg := CreateTransferApprovalParams{
+		FromAccountID: int64(from.ID),
+		ToAccountID:   int64(to.ID),
+		Amount:        util.RandomInt(1, 100),
+		Memo:          "Equipment",
+		Metadata:      json.RawMessage("{}"),
+		RequestedBy:   from.Owner,
+		ExpiresAt:     expiresAt,
+	}
+
+	approval, err := testQueries.CreateTransferApproval(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, approval)
+
+	require.Equal(t, arg.FromAccountID, approval.FromAccountID)
+	require.Equal(t, arg.ToAccountID, approval.ToAccountID)
+	require.Equal(t, arg.Amount, approval.Amount)
+	require.Equal(t, arg.Memo, approval.Memo)
+	require.Equal(t, arg.RequestedBy, approval.RequestedBy)
+	require.Equal(t, util.TransferApprovalPending, approval.Status)
+	require.WithinDuration(t, arg.ExpiresAt, approval.ExpiresAt, time.Second)
+	require.False(t, approval.DecidedBy.Valid)
+	require.False(t, approval.TransferID.Valid)
+
+	require.NotZero(t, approval.ID)
+	require.NotZero(t, approval.CreatedAt)
+
+	return approval
+}
+
+func TestQueries_CreateTransferApproval(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+
+	createRandomTransferApproval(t, from, to, time.Now().Add(time.Hour))
+}
+
+func TestQueries_CreateTransferApprovalIdempotencyKey(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+
+	arg := CreateTransferApprovalParams{
+		FromAccountID:  int64(from.ID),
+		ToAccountID:    int64(to.ID),
+		Amount:         10,
+		Metadata:       json.RawMessage("{}"),
+		RequestedBy:    from.Owner,
+		IdempotencyKey: util.RandomString(16),
+		RequestHash:    "hash",
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}
+
+	approval, err := testQueries.CreateTransferApproval(context.Background(), arg)
+	require.NoError(t, err)
+
+	// A key can only be used once per user
+	_, err = testQueries.CreateTransferApproval(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	got, err := testQueries.GetTransferApprovalByIdempotencyKey(context.Background(),
+		GetTransferApprovalByIdempotencyKeyParams{
+			RequestedBy:    arg.RequestedBy,
+			IdempotencyKey: arg.IdempotencyKey,
+		})
+	require.NoError(t, err)
+	require.Equal(t, approval.ID, got.ID)
+
+	// Approvals without a key do not conflict with each other
+	arg.IdempotencyKey = ""
+
+	for i := 0; i < 2; i++ {
+		_, err = testQueries.CreateTransferApproval(context.Background(), arg)
+		require.NoError(t, err)
+	}
+}
+
+func TestQueries_ListTransferApprovals(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+
+	for i := 0; i < 2; i++ {
+		createRandomTransferApproval(t, from, to, time.Now().Add(time.Hour))
+	}
+
+	expired := createRandomTransferApproval(t, from, to, time.Now().Add(-time.Minute))
+
+	_, err := testQueries.DecideTransferApproval(context.Background(), DecideTransferApprovalParams{
+		Status: util.TransferApprovalExpired,
+		ID:     expired.ID,
+	})
+	require.NoError(t, err)
+
+	arg := ListTransferApprovalsParams{
+		Username: from.Owner,
+		Limit:    10,
+	}
+
+	approvals, err := testQueries.ListTransferApprovals(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, approvals, 3)
+
+	arg.Status = util.TransferApprovalPending
+
+	approvals, err = testQueries.ListTransferApprovals(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, approvals, 2)
+
+	for _, approval := range approvals {
+		require.Equal(t, from.Owner, approval.RequestedBy)
+	}
+
+	// Co-owners see the approvals of the account, which they may decide
+	coOwner := createRandomAccountCoOwner(t, from)
+	arg.Username = coOwner.Username
+
+	approvals, err = testQueries.ListTransferApprovals(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, approvals, 2)
+}
+
+func TestQueries_ExpireTransferApprovals(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+
+	expired := createRandomTransferApproval(t, from, to, time.Now().Add(-time.Minute))
+	pending := createRandomTransferApproval(t, from, to, time.Now().Add(time.Hour))
+
+	_, err := testQueries.AddAccountHeld(context.Background(), AddAccountHeldParams{
+		Amount: expired.Amount + pending.Amount,
+		ID:     from.ID,
+	})
+	require.NoError(t, err)
+
+	err = testQueries.ExpireTransferApprovals(context.Background())
+	require.NoError(t, err)
+
+	got, err := testQueries.GetTransferApproval(context.Background(), expired.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.TransferApprovalExpired, got.Status)
+
+	got, err = testQueries.GetTransferApproval(context.Background(), pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.TransferApprovalPending, got.Status)
+
+	// Only the money of the expired approval is released
+	account, err := testQueries.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, pending.Amount, account.Held)
+}
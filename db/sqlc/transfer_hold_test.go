@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createRandomTransferHold(t *testing.T, from, to Account, expiresAt time.Time) (Transfer, TransferHold) {
+	amount := util.RandomInt(1, 100)
+
+	transfer, err := testQueries.CreatePendingTransfer(context.Background(), CreatePendingTransferParams{
+		FromAccountID: int64(from.ID),
+		ToAccountID:   int64(to.ID),
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  "1",
+		Metadata:      json.RawMessage("{}"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.TransferPending, transfer.Status)
+	require.False(t, transfer.PostedAt.Valid)
+
+	arg := CreateTransferHoldParams{
+		TransferID: transfer.ID,
+		Amount:     amount,
+		ExpiresAt:  expiresAt,
+	}
+
+	hold, err := testQueries.CreateTransferHold(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.TransferID, hold.TransferID)
+	require.Equal(t, arg.Amount, hold.Amount)
+	require.WithinDuration(t, arg.ExpiresAt, hold.ExpiresAt, time.Second)
+
+	return transfer, hold
+}
+
+func TestQueries_CreateTransferHold(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+
+	createRandomTransferHold(t, from, to, time.Now().Add(time.Hour))
+}
+
+func TestQueries_GetTransferHold(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+
+	transfer, hold := createRandomTransferHold(t, from, to, time.Now().Add(time.Hour))
+
+	gotHold, err := testQueries.GetTransferHold(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, hold, gotHold)
+
+	_, err = testQueries.GetTransferHold(context.Background(), 0)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestQueries_VoidPendingTransfer(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, 0, from.Currency)
+
+	transfer, _ := createRandomTransferHold(t, from, to, time.Now().Add(time.Hour))
+
+	voided, err := testQueries.VoidPendingTransfer(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.TransferVoided, voided.Status)
+	require.False(t, voided.PostedAt.Valid)
+}
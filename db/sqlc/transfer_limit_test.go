@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func createTransferLimit(t *testing.T, scope, subject, currency string, perTransaction, daily,
+	monthly int64) TransferLimit {
+
+	arg := UpsertTransferLimitParams{
+		Scope:          scope,
+		Subject:        subject,
+		Currency:       currency,
+		PerTransaction: perTransaction,
+		Daily:          daily,
+		Monthly:        monthly,
+	}
+
+	limit, err := testQueries.UpsertTransferLimit(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.Scope, limit.Scope)
+	require.Equal(t, arg.Subject, limit.Subject)
+	require.Equal(t, arg.Currency, limit.Currency)
+	require.Equal(t, arg.PerTransaction, limit.PerTransaction)
+	require.Equal(t, arg.Daily, limit.Daily)
+	require.Equal(t, arg.Monthly, limit.Monthly)
+	require.NotZero(t, limit.UpdatedAt)
+
+	return limit
+}
+
+func TestQueries_UpsertTransferLimit(t *testing.T) {
+	user := createRandomUser(t)
+
+	first := createTransferLimit(t, util.TransferLimitUserScope, user.Username, util.USD, 100, 1000, 10000)
+	second := createTransferLimit(t, util.TransferLimitUserScope, user.Username, util.USD, 200, 2000, 20000)
+
+	require.True(t, second.UpdatedAt.After(first.UpdatedAt))
+}
+
+func TestQueries_GetEffectiveTransferLimit(t *testing.T) {
+	user := createRandomUser(t)
+
+	user, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Username: user.Username,
+		Role:     util.AdminRole,
+	})
+	require.NoError(t, err)
+
+	arg := GetEffectiveTransferLimitParams{
+		Currency: util.CAD,
+		Username: user.Username,
+		Role:     user.Role,
+	}
+
+	// The role limit applies until the user gets their own
+	roleLimit := createTransferLimit(t, util.TransferLimitRoleScope, util.AdminRole, util.CAD, 100, 1000, 10000)
+
+	limit, err := testQueries.GetEffectiveTransferLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, roleLimit.Scope, limit.Scope)
+
+	userLimit := createTransferLimit(t, util.TransferLimitUserScope, user.Username, util.CAD, 50, 500, 5000)
+
+	limit, err = testQueries.GetEffectiveTransferLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, userLimit.Scope, limit.Scope)
+	require.Equal(t, userLimit.Daily, limit.Daily)
+
+	_, err = testQueries.DeleteTransferLimit(context.Background(), DeleteTransferLimitParams{
+		Scope:    roleLimit.Scope,
+		Subject:  roleLimit.Subject,
+		Currency: roleLimit.Currency,
+	})
+	require.NoError(t, err)
+
+	// Other currencies are not limited
+	arg.Currency = util.EUR
+
+	_, err = testQueries.GetEffectiveTransferLimit(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestQueries_ListTransferLimits(t *testing.T) {
+	user := createRandomUser(t)
+	createTransferLimit(t, util.TransferLimitUserScope, user.Username, util.EUR, 100, 1000, 10000)
+
+	limits, err := testQueries.ListTransferLimits(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, limits)
+}
+
+func TestQueries_DeleteTransferLimit(t *testing.T) {
+	user := createRandomUser(t)
+	limit := createTransferLimit(t, util.TransferLimitUserScope, user.Username, util.EUR, 100, 1000, 10000)
+
+	arg := DeleteTransferLimitParams{
+		Scope:    limit.Scope,
+		Subject:  limit.Subject,
+		Currency: limit.Currency,
+	}
+
+	_, err := testQueries.DeleteTransferLimit(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = testQueries.DeleteTransferLimit(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
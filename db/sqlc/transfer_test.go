@@ -2,17 +2,32 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/jwambugu/go-simple-bank-class/util"
 	"github.com/stretchr/testify/require"
+	"math"
 	"testing"
 	"time"
 )
 
 func createRandomTransfer(t *testing.T, a, b Account) Transfer {
+	return createRandomTransferWithDetails(t, a, b, "", "", json.RawMessage("{}"))
+}
+
+func createRandomTransferWithDetails(t *testing.T, a, b Account, memo, reference string,
+	metadata json.RawMessage) Transfer {
+
+	amount := util.RandomMoney()
+
 	arg := CreateTransferParams{
 		FromAccountID: int64(a.ID),
 		ToAccountID:   int64(b.ID),
-		Amount:        util.RandomMoney(),
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  "1",
+		Memo:          memo,
+		Reference:     reference,
+		Metadata:      metadata,
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -22,6 +37,11 @@ func createRandomTransfer(t *testing.T, a, b Account) Transfer {
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.ToAmount, transfer.ToAmount)
+	require.Equal(t, arg.ExchangeRate, transfer.ExchangeRate)
+	require.Equal(t, arg.Memo, transfer.Memo)
+	require.Equal(t, arg.Reference, transfer.Reference)
+	require.JSONEq(t, string(arg.Metadata), string(transfer.Metadata))
 
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
@@ -34,6 +54,8 @@ func TestQueries_CreateTransfer(t *testing.T) {
 	b := createRandomAccount(t)
 
 	createRandomTransfer(t, a, b)
+	createRandomTransferWithDetails(t, a, b, "Rent for March", "INV-2021-03",
+		json.RawMessage(`{"invoice": "INV-2021-03", "lines": [1, 2]}`))
 }
 
 func TestQueries_GetTransfer(t *testing.T) {
@@ -80,3 +102,157 @@ func TestQueries_ListTransfers(t *testing.T) {
 		require.True(t, transfer.FromAccountID == int64(a.ID) && transfer.ToAccountID == int64(b.ID))
 	}
 }
+
+func TestQueries_ListOwnerTransfers(t *testing.T) {
+	a := createRandomAccount(t)
+	b := createRandomAccount(t)
+
+	outgoing := createRandomTransfer(t, a, b)
+	incoming := createRandomTransfer(t, b, a)
+
+	arg := ListOwnerTransfersParams{
+		Owner:     a.Owner,
+		CreatedTo: time.Now().Add(time.Minute),
+		MaxAmount: math.MaxInt64,
+		Metadata:  json.RawMessage("{}"),
+		Limit:     10,
+		Offset:    0,
+	}
+
+	// Both directions, newest first
+	transfers, err := testQueries.ListOwnerTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	require.Equal(t, incoming.ID, transfers[0].ID)
+	require.Equal(t, outgoing.ID, transfers[1].ID)
+
+	arg.Direction = "out"
+
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, outgoing.ID, transfers[0].ID)
+
+	arg.Direction = "in"
+	arg.AccountID = int64(a.ID)
+
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, incoming.ID, transfers[0].ID)
+
+	// Amount and date filters exclude everything outside the range
+	arg.Direction = ""
+	arg.AccountID = 0
+	arg.MinAmount = incoming.Amount + outgoing.Amount + 1
+
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+
+	arg.MinAmount = 0
+	arg.CreatedFrom = time.Now().Add(time.Minute)
+
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func TestQueries_ListOwnerTransfersSearch(t *testing.T) {
+	a := createRandomAccount(t)
+	b := createRandomAccount(t)
+
+	rent := createRandomTransferWithDetails(t, a, b, "Rent for March", "", json.RawMessage(`{"category": "housing"}`))
+	invoice := createRandomTransferWithDetails(t, a, b, "", "INV-2021-03",
+		json.RawMessage(`{"category": "supplies", "invoice": {"id": 3}}`))
+	createRandomTransfer(t, a, b)
+
+	arg := ListOwnerTransfersParams{
+		Owner:     a.Owner,
+		CreatedTo: time.Now().Add(time.Minute),
+		MaxAmount: math.MaxInt64,
+		Search:    "rent",
+		Metadata:  json.RawMessage("{}"),
+		Limit:     10,
+	}
+
+	// The search is case insensitive and matches the memo or the reference
+	transfers, err := testQueries.ListOwnerTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, rent.ID, transfers[0].ID)
+	require.Equal(t, rent.Memo, transfers[0].Memo)
+
+	arg.Search = "inv-2021"
+
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, invoice.ID, transfers[0].ID)
+
+	// Metadata matches transfers whose metadata contains the given object
+	arg.Search = ""
+	arg.Metadata = json.RawMessage(`{"invoice": {"id": 3}}`)
+
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, invoice.ID, transfers[0].ID)
+
+	arg.Metadata = json.RawMessage(`{"category": "travel"}`)
+
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func TestQueries_GetOutgoingTransferStatsSince(t *testing.T) {
+	a := createRandomAccount(t)
+	b := createRandomAccountWithCurrency(t, 1000, a.Currency)
+
+	since := time.Now().Add(-time.Minute)
+
+	first := createRandomTransfer(t, a, b)
+	second := createRandomTransfer(t, a, b)
+
+	// Transfers received by the owner do not count
+	createRandomTransfer(t, b, a)
+
+	stats, err := testQueries.GetOutgoingTransferStatsSince(context.Background(), GetOutgoingTransferStatsSinceParams{
+		Owner:       a.Owner,
+		Currency:    a.Currency,
+		CreatedFrom: since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), stats.Count)
+	require.Equal(t, first.Amount+second.Amount, stats.Total)
+
+	stats, err = testQueries.GetOutgoingTransferStatsSince(context.Background(), GetOutgoingTransferStatsSinceParams{
+		Owner:       a.Owner,
+		Currency:    a.Currency,
+		CreatedFrom: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, stats.Count)
+	require.Zero(t, stats.Total)
+}
+
+func TestQueries_CountTransfersToAccount(t *testing.T) {
+	a := createRandomAccount(t)
+	b := createRandomAccount(t)
+
+	arg := CountTransfersToAccountParams{
+		Owner:       a.Owner,
+		ToAccountID: int64(b.ID),
+	}
+
+	count, err := testQueries.CountTransfersToAccount(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	createRandomTransfer(t, a, b)
+
+	count, err = testQueries.CountTransfersToAccount(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
@@ -10,7 +10,7 @@ import (
 const createUser = `-- name: CreateUser :one
 INSERT INTO users(username, full_name, hashed_password, email)
 VALUES ($1, $2, $3, $4)
-RETURNING username, full_name, hashed_password, email, password_changed_at, created_at
+RETURNING username, full_name, hashed_password, email, password_changed_at, created_at, tokens_revoked_at, role
 `
 
 type CreateUserParams struct {
@@ -35,12 +35,14 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.TokensRevokedAt,
+		&i.Role,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, full_name, hashed_password, email, password_changed_at, created_at
+SELECT username, full_name, hashed_password, email, password_changed_at, created_at, tokens_revoked_at, role
 FROM users
 WHERE username = $1
 LIMIT 1
@@ -56,6 +58,82 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.TokensRevokedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getUserForUpdate = `-- name: GetUserForUpdate :one
+SELECT username, full_name, hashed_password, email, password_changed_at, created_at, tokens_revoked_at, role
+FROM users
+WHERE username = $1
+LIMIT 1 FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetUserForUpdate(ctx context.Context, username string) (User, error) {
+	row := q.queryRow(ctx, q.getUserForUpdateStmt, getUserForUpdate, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.FullName,
+		&i.HashedPassword,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TokensRevokedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const revokeUserTokens = `-- name: RevokeUserTokens :one
+UPDATE users
+SET tokens_revoked_at = now()
+WHERE username = $1
+RETURNING username, full_name, hashed_password, email, password_changed_at, created_at, tokens_revoked_at, role
+`
+
+func (q *Queries) RevokeUserTokens(ctx context.Context, username string) (User, error) {
+	row := q.queryRow(ctx, q.revokeUserTokensStmt, revokeUserTokens, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.FullName,
+		&i.HashedPassword,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TokensRevokedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $1
+WHERE username = $2
+RETURNING username, full_name, hashed_password, email, password_changed_at, created_at, tokens_revoked_at, role
+`
+
+type UpdateUserRoleParams struct {
+	Role     string `json:"role"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.queryRow(ctx, q.updateUserRoleStmt, updateUserRole, arg.Role, arg.Username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.FullName,
+		&i.HashedPassword,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.TokensRevokedAt,
+		&i.Role,
 	)
 	return i, err
 }
@@ -27,6 +27,7 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.FullName, user.FullName)
 	require.Equal(t, arg.HashedPassword, user.HashedPassword)
 	require.Equal(t, arg.Email, user.Email)
+	require.Equal(t, util.DepositorRole, user.Role)
 
 	require.True(t, user.PasswordChangedAt.IsZero())
 	require.NotZero(t, user.CreatedAt)
@@ -52,3 +53,29 @@ func TestQueries_GetUser(t *testing.T) {
 	require.WithinDuration(t, expected.PasswordChangedAt, actual.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, expected.CreatedAt, actual.CreatedAt, time.Second)
 }
+
+func TestQueries_RevokeUserTokens(t *testing.T) {
+	user := createRandomUser(t)
+	require.True(t, user.TokensRevokedAt.IsZero())
+
+	updatedUser, err := testQueries.RevokeUserTokens(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	require.Equal(t, user.Username, updatedUser.Username)
+	require.WithinDuration(t, time.Now(), updatedUser.TokensRevokedAt, time.Second)
+}
+
+func TestQueries_UpdateUserRole(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := UpdateUserRoleParams{
+		Role:     util.BankerRole,
+		Username: user.Username,
+	}
+
+	updatedUser, err := testQueries.UpdateUserRole(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, user.Username, updatedUser.Username)
+	require.Equal(t, util.BankerRole, updatedUser.Role)
+}
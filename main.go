@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"flag"
 	"github.com/jwambugu/go-simple-bank-class/api"
 	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/reconcile"
+	"github.com/jwambugu/go-simple-bank-class/risk"
+	"github.com/jwambugu/go-simple-bank-class/scheduler"
 	"github.com/jwambugu/go-simple-bank-class/util"
 	"log"
+	"os"
 
 	_ "github.com/lib/pq"
 )
@@ -22,6 +29,32 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
+
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		runReconcile(store, os.Args[2:])
+		return
+	}
+
+	riskEngine, err := risk.NewEngineFromFile(store, config.RiskRulesFile)
+
+	if err != nil {
+		log.Fatal("cannot create risk engine: ", err)
+	}
+
+	retryPolicy := scheduler.RetryPolicy{
+		MaxAttempts: config.SchedulerMaxAttempts,
+		Delay:       config.SchedulerRetryDelay,
+	}
+
+	worker, err := scheduler.NewWorker(store, riskEngine, config.SchedulerInterval, scheduler.DefaultBatchSize,
+		retryPolicy)
+
+	if err != nil {
+		log.Fatal("cannot create scheduled transfer worker: ", err)
+	}
+
+	go worker.Start(context.Background())
+
 	server, err := api.NewServer(config, store)
 
 	if err != nil {
@@ -30,3 +63,37 @@ func main() {
 
 	log.Fatal(server.Start(config.ServerAddress))
 }
+
+// runReconcile checks the ledger and prints the report as JSON. It exits with status 1 if the ledger does not
+// reconcile so that scheduled runs fail loudly.
+func runReconcile(store db.Store, args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	batchSize := flags.Int("batch-size", reconcile.DefaultBatchSize, "number of rows read per query")
+
+	if err := flags.Parse(args); err != nil {
+		log.Fatal("cannot parse flags: ", err)
+	}
+
+	reconciler, err := reconcile.NewReconciler(store, int32(*batchSize))
+
+	if err != nil {
+		log.Fatal("cannot create reconciler: ", err)
+	}
+
+	report, err := reconciler.Run(context.Background())
+
+	if err != nil {
+		log.Fatal("cannot reconcile the ledger: ", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal("cannot write the report: ", err)
+	}
+
+	if report.HasDiscrepancies() {
+		os.Exit(1)
+	}
+}
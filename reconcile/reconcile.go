@@ -0,0 +1,180 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+)
+
+// DefaultBatchSize is the number of rows read per query when no batch size is set
+const DefaultBatchSize = 1000
+
+// DriftedAccount is an account whose balance does not equal the sum of its entries
+type DriftedAccount struct {
+	AccountID    int32 `json:"account_id"`
+	Balance      int64 `json:"balance"`
+	EntriesTotal int64 `json:"entries_total"`
+	Drift        int64 `json:"drift"`
+}
+
+// OrphanedEntry is an entry that does not belong to any transfer
+type OrphanedEntry struct {
+	EntryID   int64 `json:"entry_id"`
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+// UnbalancedTransfer is a transfer that does not have exactly one debit and one credit entry
+type UnbalancedTransfer struct {
+	TransferID    int64 `json:"transfer_id"`
+	DebitEntries  int64 `json:"debit_entries"`
+	CreditEntries int64 `json:"credit_entries"`
+}
+
+// Report lists every discrepancy found in the ledger
+type Report struct {
+	AccountsChecked     int64                `json:"accounts_checked"`
+	EntriesChecked      int64                `json:"entries_checked"`
+	TransfersChecked    int64                `json:"transfers_checked"`
+	DriftedAccounts     []DriftedAccount     `json:"drifted_accounts"`
+	OrphanedEntries     []OrphanedEntry      `json:"orphaned_entries"`
+	UnbalancedTransfers []UnbalancedTransfer `json:"unbalanced_transfers"`
+}
+
+// HasDiscrepancies returns true if the ledger does not reconcile
+func (report Report) HasDiscrepancies() bool {
+	return len(report.DriftedAccounts) > 0 || len(report.OrphanedEntries) > 0 || len(report.UnbalancedTransfers) > 0
+}
+
+// Reconciler checks that account balances match their entries and that every transfer is backed by its entries
+type Reconciler struct {
+	store     db.Store
+	batchSize int32
+}
+
+// NewReconciler creates a new Reconciler which reads the ledger batchSize rows at a time
+func NewReconciler(store db.Store, batchSize int32) (*Reconciler, error) {
+	if batchSize <= 0 {
+		return nil, errors.New("batch size must be positive")
+	}
+
+	return &Reconciler{
+		store:     store,
+		batchSize: batchSize,
+	}, nil
+}
+
+// Run scans the whole ledger and reports every discrepancy found.
+// Each batch is read with a single query, so balances and entries are always compared from the same snapshot.
+func (reconciler *Reconciler) Run(ctx context.Context) (Report, error) {
+	report := Report{
+		DriftedAccounts:     []DriftedAccount{},
+		OrphanedEntries:     []OrphanedEntry{},
+		UnbalancedTransfers: []UnbalancedTransfer{},
+	}
+
+	if err := reconciler.checkAccounts(ctx, &report); err != nil {
+		return report, err
+	}
+
+	if err := reconciler.checkEntries(ctx, &report); err != nil {
+		return report, err
+	}
+
+	if err := reconciler.checkTransfers(ctx, &report); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+func (reconciler *Reconciler) checkAccounts(ctx context.Context, report *Report) error {
+	arg := db.ReconcileAccountsParams{Limit: reconciler.batchSize}
+
+	for {
+		accounts, err := reconciler.store.ReconcileAccounts(ctx, arg)
+
+		if err != nil {
+			return err
+		}
+
+		for _, account := range accounts {
+			if account.Balance != account.EntriesTotal {
+				report.DriftedAccounts = append(report.DriftedAccounts, DriftedAccount{
+					AccountID:    account.ID,
+					Balance:      account.Balance,
+					EntriesTotal: account.EntriesTotal,
+					Drift:        account.Balance - account.EntriesTotal,
+				})
+			}
+		}
+
+		report.AccountsChecked += int64(len(accounts))
+
+		if len(accounts) < int(arg.Limit) {
+			return nil
+		}
+
+		arg.AfterID = accounts[len(accounts)-1].ID
+	}
+}
+
+func (reconciler *Reconciler) checkEntries(ctx context.Context, report *Report) error {
+	arg := db.ReconcileEntriesParams{Limit: reconciler.batchSize}
+
+	for {
+		entries, err := reconciler.store.ReconcileEntries(ctx, arg)
+
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if !entry.HasTransfer {
+				report.OrphanedEntries = append(report.OrphanedEntries, OrphanedEntry{
+					EntryID:   entry.ID,
+					AccountID: entry.AccountID,
+					Amount:    entry.Amount,
+				})
+			}
+		}
+
+		report.EntriesChecked += int64(len(entries))
+
+		if len(entries) < int(arg.Limit) {
+			return nil
+		}
+
+		arg.AfterID = entries[len(entries)-1].ID
+	}
+}
+
+func (reconciler *Reconciler) checkTransfers(ctx context.Context, report *Report) error {
+	arg := db.ReconcileTransfersParams{Limit: reconciler.batchSize}
+
+	for {
+		transfers, err := reconciler.store.ReconcileTransfers(ctx, arg)
+
+		if err != nil {
+			return err
+		}
+
+		for _, transfer := range transfers {
+			if transfer.DebitEntries != 1 || transfer.CreditEntries != 1 {
+				report.UnbalancedTransfers = append(report.UnbalancedTransfers, UnbalancedTransfer{
+					TransferID:    transfer.ID,
+					DebitEntries:  transfer.DebitEntries,
+					CreditEntries: transfer.CreditEntries,
+				})
+			}
+		}
+
+		report.TransfersChecked += int64(len(transfers))
+
+		if len(transfers) < int(arg.Limit) {
+			return nil
+		}
+
+		arg.AfterID = transfers[len(transfers)-1].ID
+	}
+}
@@ -0,0 +1,120 @@
+package reconcile
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewReconciler(t *testing.T) {
+	_, err := NewReconciler(nil, 0)
+	require.Error(t, err)
+}
+
+func TestReconciler_Run(t *testing.T) {
+	testCases := []struct {
+		name        string
+		buildStubs  func(store *mockdb.MockStore)
+		checkReport func(t *testing.T, report Report, err error)
+	}{
+		{
+			name: "BalancedLedger",
+			buildStubs: func(store *mockdb.MockStore) {
+				// A full batch is followed by a request for the next one
+				store.EXPECT().
+					ReconcileAccounts(gomock.Any(), gomock.Eq(db.ReconcileAccountsParams{AfterID: 0, Limit: 2})).
+					Times(1).
+					Return([]db.ReconcileAccountsRow{
+						{ID: 1, Balance: 10, EntriesTotal: 10},
+						{ID: 2, Balance: -10, EntriesTotal: -10},
+					}, nil)
+
+				store.EXPECT().
+					ReconcileAccounts(gomock.Any(), gomock.Eq(db.ReconcileAccountsParams{AfterID: 2, Limit: 2})).
+					Times(1).
+					Return([]db.ReconcileAccountsRow{}, nil)
+
+				store.EXPECT().
+					ReconcileEntries(gomock.Any(), gomock.Eq(db.ReconcileEntriesParams{AfterID: 0, Limit: 2})).
+					Times(1).
+					Return([]db.ReconcileEntriesRow{{ID: 1, HasTransfer: true}}, nil)
+
+				store.EXPECT().
+					ReconcileTransfers(gomock.Any(), gomock.Eq(db.ReconcileTransfersParams{AfterID: 0, Limit: 2})).
+					Times(1).
+					Return([]db.ReconcileTransfersRow{{ID: 1, DebitEntries: 1, CreditEntries: 1}}, nil)
+			},
+			checkReport: func(t *testing.T, report Report, err error) {
+				require.NoError(t, err)
+				require.False(t, report.HasDiscrepancies())
+				require.Equal(t, int64(2), report.AccountsChecked)
+				require.Equal(t, int64(1), report.EntriesChecked)
+				require.Equal(t, int64(1), report.TransfersChecked)
+			},
+		},
+		{
+			name: "Discrepancies",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReconcileAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ReconcileAccountsRow{{ID: 1, Balance: 15, EntriesTotal: 10}}, nil)
+
+				store.EXPECT().
+					ReconcileEntries(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ReconcileEntriesRow{{ID: 7, AccountID: 1, Amount: 5, HasTransfer: false}}, nil)
+
+				store.EXPECT().
+					ReconcileTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ReconcileTransfersRow{{ID: 3, DebitEntries: 1, CreditEntries: 0}}, nil)
+			},
+			checkReport: func(t *testing.T, report Report, err error) {
+				require.NoError(t, err)
+				require.True(t, report.HasDiscrepancies())
+
+				require.Equal(t, []DriftedAccount{{AccountID: 1, Balance: 15, EntriesTotal: 10, Drift: 5}},
+					report.DriftedAccounts)
+				require.Equal(t, []OrphanedEntry{{EntryID: 7, AccountID: 1, Amount: 5}}, report.OrphanedEntries)
+				require.Equal(t, []UnbalancedTransfer{{TransferID: 3, DebitEntries: 1, CreditEntries: 0}},
+					report.UnbalancedTransfers)
+			},
+		},
+		{
+			name: "QueryError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReconcileAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+
+				store.EXPECT().ReconcileEntries(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ReconcileTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReport: func(t *testing.T, report Report, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			testCase.buildStubs(store)
+
+			reconciler, err := NewReconciler(store, 2)
+			require.NoError(t, err)
+
+			report, err := reconciler.Run(context.Background())
+			testCase.checkReport(t, report, err)
+		})
+	}
+}
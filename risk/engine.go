@@ -0,0 +1,188 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"time"
+)
+
+// severity orders the decisions, the most severe decision of the matched rules wins
+var severity = map[string]int{
+	util.RiskAllow:  0,
+	util.RiskReview: 1,
+	util.RiskDeny:   2,
+}
+
+// Transfer is a transfer about to be made
+type Transfer struct {
+	// Username is the owner of the sender account
+	Username      string
+	FromAccountID int64
+	ToAccountID   int64
+	// Amount is in the currency of the sender account
+	Amount   int64
+	Currency string
+}
+
+// Hit is a rule that matched a transfer
+type Hit struct {
+	Rule     string `json:"rule"`
+	Type     string `json:"type"`
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+}
+
+// Assessment is the decision taken for a transfer and the rules that led to it
+type Assessment struct {
+	Decision string `json:"decision"`
+	Hits     []Hit  `json:"hits"`
+}
+
+// Engine runs a set of rules against transfers. Transfers no rule matches are allowed.
+type Engine struct {
+	store db.Store
+	rules []Rule
+	now   func() time.Time
+}
+
+// NewEngine creates a new Engine running the rules in order
+func NewEngine(store db.Store, rules []Rule) (*Engine, error) {
+	names := make(map[string]bool, len(rules))
+	validated := make([]Rule, len(rules))
+
+	for i, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+
+		if names[rule.Name] {
+			return nil, fmt.Errorf("rule %s is defined more than once", rule.Name)
+		}
+
+		names[rule.Name] = true
+		validated[i] = rule
+	}
+
+	return &Engine{
+		store: store,
+		rules: validated,
+		now:   time.Now,
+	}, nil
+}
+
+// NewEngineFromFile creates a new Engine running the rules of the rules file.
+// Every transfer is allowed when path is empty.
+func NewEngineFromFile(store db.Store, path string) (*Engine, error) {
+	var rules []Rule
+
+	if path != "" {
+		loaded, err := LoadRules(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rules = loaded
+	}
+
+	return NewEngine(store, rules)
+}
+
+// Evaluate runs every rule against the transfer
+func (engine *Engine) Evaluate(ctx context.Context, transfer Transfer) (Assessment, error) {
+	assessment := Assessment{
+		Decision: util.RiskAllow,
+		Hits:     []Hit{},
+	}
+
+	for _, rule := range engine.rules {
+		if rule.Currency != "" && rule.Currency != transfer.Currency {
+			continue
+		}
+
+		reason, err := engine.match(ctx, rule, transfer)
+
+		if err != nil {
+			return assessment, fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+
+		if reason == "" {
+			continue
+		}
+
+		assessment.Hits = append(assessment.Hits, Hit{
+			Rule:     rule.Name,
+			Type:     rule.Type,
+			Decision: rule.Decision,
+			Reason:   reason,
+		})
+
+		if severity[rule.Decision] > severity[assessment.Decision] {
+			assessment.Decision = rule.Decision
+		}
+	}
+
+	return assessment, nil
+}
+
+// match returns why the rule matches the transfer, or an empty string if it does not
+func (engine *Engine) match(ctx context.Context, rule Rule, transfer Transfer) (string, error) {
+	switch rule.Type {
+	case VelocityRule:
+		stats, err := engine.store.GetOutgoingTransferStatsSince(ctx, db.GetOutgoingTransferStatsSinceParams{
+			Owner:       transfer.Username,
+			Currency:    transfer.Currency,
+			CreatedFrom: engine.now().Add(-rule.window),
+		})
+
+		if err != nil {
+			return "", err
+		}
+
+		// The transfer being assessed counts towards the limits
+		count := stats.Count + 1
+		total := stats.Total + transfer.Amount
+
+		if rule.MaxCount > 0 && count > rule.MaxCount {
+			return fmt.Sprintf("%d transfers within %s, more than %d", count, rule.window, rule.MaxCount), nil
+		}
+
+		if rule.MaxAmount > 0 && total > rule.MaxAmount {
+			return fmt.Sprintf("%d %s sent within %s, more than %d", total, transfer.Currency, rule.window,
+				rule.MaxAmount), nil
+		}
+	case NewRecipientLargeAmountRule:
+		if transfer.Amount < rule.MinAmount {
+			return "", nil
+		}
+
+		count, err := engine.store.CountTransfersToAccount(ctx, db.CountTransfersToAccountParams{
+			Owner:       transfer.Username,
+			ToAccountID: transfer.ToAccountID,
+		})
+
+		if err != nil {
+			return "", err
+		}
+
+		if count == 0 {
+			return fmt.Sprintf("first transfer to account %d is %d %s, at least %d", transfer.ToAccountID,
+				transfer.Amount, transfer.Currency, rule.MinAmount), nil
+		}
+	case UnusualHourRule:
+		now := engine.now().In(rule.location)
+
+		if rule.isUnusualHour(now.Hour()) {
+			return fmt.Sprintf("made at %s, between %02d:00 and %02d:00 %s", now.Format("15:04"), rule.StartHour,
+				rule.EndHour, rule.location), nil
+		}
+	case RoundAmountRule:
+		if transfer.Amount >= rule.MinAmount && transfer.Amount%rule.Multiple == 0 {
+			return fmt.Sprintf("amount %d is a multiple of %d", transfer.Amount, rule.Multiple), nil
+		}
+	}
+
+	return "", nil
+}
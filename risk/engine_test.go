@@ -0,0 +1,363 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewEngine(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules []Rule
+	}{
+		{
+			name:  "MissingName",
+			rules: []Rule{{Type: RoundAmountRule, Decision: util.RiskReview, Multiple: 100}},
+		},
+		{
+			name:  "UnsupportedDecision",
+			rules: []Rule{{Name: "round", Type: RoundAmountRule, Decision: "block", Multiple: 100}},
+		},
+		{
+			name:  "UnsupportedType",
+			rules: []Rule{{Name: "round", Type: "geolocation", Decision: util.RiskReview}},
+		},
+		{
+			name:  "UnsupportedCurrency",
+			rules: []Rule{{Name: "round", Type: RoundAmountRule, Decision: util.RiskReview, Currency: "KES", Multiple: 100}},
+		},
+		{
+			name:  "VelocityWithoutWindow",
+			rules: []Rule{{Name: "velocity", Type: VelocityRule, Decision: util.RiskReview, MaxCount: 5}},
+		},
+		{
+			name:  "VelocityWithoutLimits",
+			rules: []Rule{{Name: "velocity", Type: VelocityRule, Decision: util.RiskReview, Window: "1h"}},
+		},
+		{
+			name:  "NewRecipientWithoutMinAmount",
+			rules: []Rule{{Name: "new_recipient", Type: NewRecipientLargeAmountRule, Decision: util.RiskReview}},
+		},
+		{
+			name:  "InvalidHour",
+			rules: []Rule{{Name: "night", Type: UnusualHourRule, Decision: util.RiskReview, StartHour: 22, EndHour: 24}},
+		},
+		{
+			name:  "EmptyHourRange",
+			rules: []Rule{{Name: "night", Type: UnusualHourRule, Decision: util.RiskReview, StartHour: 2, EndHour: 2}},
+		},
+		{
+			name: "UnknownLocation",
+			rules: []Rule{{
+				Name:      "night",
+				Type:      UnusualHourRule,
+				Decision:  util.RiskReview,
+				StartHour: 0,
+				EndHour:   5,
+				Location:  "Nowhere/Town",
+			}},
+		},
+		{
+			name:  "RoundAmountWithoutMultiple",
+			rules: []Rule{{Name: "round", Type: RoundAmountRule, Decision: util.RiskReview}},
+		},
+		{
+			name: "DuplicateName",
+			rules: []Rule{
+				{Name: "round", Type: RoundAmountRule, Decision: util.RiskReview, Multiple: 100},
+				{Name: "round", Type: RoundAmountRule, Decision: util.RiskDeny, Multiple: 1000},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewEngine(nil, tc.rules)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+
+	rulesFile := filepath.Join(dir, "rules.json")
+	err := ioutil.WriteFile(rulesFile, []byte(`{
+  "rules": [
+    {"name": "burst", "type": "velocity", "decision": "review", "window": "1h", "max_count": 5},
+    {"name": "night", "type": "unusual_hour", "decision": "allow", "start_hour": 22, "end_hour": 5}
+  ]
+}`), 0600)
+	require.NoError(t, err)
+
+	rules, err := LoadRules(rulesFile)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Equal(t, "burst", rules[0].Name)
+	require.Equal(t, int64(5), rules[0].MaxCount)
+	require.Equal(t, 22, rules[1].StartHour)
+
+	_, err = NewEngine(nil, rules)
+	require.NoError(t, err)
+
+	unknownField := filepath.Join(dir, "unknown.json")
+	err = ioutil.WriteFile(unknownField, []byte(`{"rules": [{"name": "burst", "max_counts": 5}]}`), 0600)
+	require.NoError(t, err)
+
+	_, err = LoadRules(unknownField)
+	require.Error(t, err)
+
+	_, err = LoadRules(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
+
+func TestNewEngineFromFile(t *testing.T) {
+	engine, err := NewEngineFromFile(nil, "")
+	require.NoError(t, err)
+	require.Empty(t, engine.rules)
+
+	_, err = NewEngineFromFile(nil, filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	now := time.Date(2021, time.August, 10, 3, 30, 0, 0, time.UTC)
+
+	transfer := Transfer{
+		Username:      "alice",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        50000,
+		Currency:      util.USD,
+	}
+
+	testCases := []struct {
+		name            string
+		rules           []Rule
+		buildStubs      func(store *mockdb.MockStore)
+		checkAssessment func(t *testing.T, assessment Assessment, err error)
+	}{
+		{
+			name:       "NoRules",
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.RiskAllow, assessment.Decision)
+				require.Empty(t, assessment.Hits)
+			},
+		},
+		{
+			name: "VelocityCount",
+			rules: []Rule{
+				{Name: "burst", Type: VelocityRule, Decision: util.RiskReview, Window: "1h", MaxCount: 3},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.GetOutgoingTransferStatsSinceParams{
+					Owner:       transfer.Username,
+					Currency:    transfer.Currency,
+					CreatedFrom: now.Add(-time.Hour),
+				}
+
+				store.EXPECT().
+					GetOutgoingTransferStatsSince(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.GetOutgoingTransferStatsSinceRow{Count: 3, Total: 300}, nil)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.RiskReview, assessment.Decision)
+				require.Len(t, assessment.Hits, 1)
+				require.Equal(t, "burst", assessment.Hits[0].Rule)
+				require.Equal(t, "4 transfers within 1h0m0s, more than 3", assessment.Hits[0].Reason)
+			},
+		},
+		{
+			name: "VelocityAmount",
+			rules: []Rule{
+				{Name: "drain", Type: VelocityRule, Decision: util.RiskDeny, Window: "24h", MaxAmount: 60000},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOutgoingTransferStatsSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetOutgoingTransferStatsSinceRow{Count: 1, Total: 20000}, nil)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.RiskDeny, assessment.Decision)
+				require.Len(t, assessment.Hits, 1)
+			},
+		},
+		{
+			name: "VelocityWithinLimits",
+			rules: []Rule{
+				{Name: "burst", Type: VelocityRule, Decision: util.RiskReview, Window: "1h", MaxCount: 3, MaxAmount: 60000},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOutgoingTransferStatsSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetOutgoingTransferStatsSinceRow{Count: 2, Total: 10000}, nil)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.RiskAllow, assessment.Decision)
+				require.Empty(t, assessment.Hits)
+			},
+		},
+		{
+			name: "NewRecipientLargeAmount",
+			rules: []Rule{
+				{Name: "new_recipient", Type: NewRecipientLargeAmountRule, Decision: util.RiskReview, MinAmount: 10000},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CountTransfersToAccountParams{
+					Owner:       transfer.Username,
+					ToAccountID: transfer.ToAccountID,
+				}
+
+				store.EXPECT().CountTransfersToAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(0), nil)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.RiskReview, assessment.Decision)
+				require.Len(t, assessment.Hits, 1)
+			},
+		},
+		{
+			name: "KnownRecipient",
+			rules: []Rule{
+				{Name: "new_recipient", Type: NewRecipientLargeAmountRule, Decision: util.RiskReview, MinAmount: 10000},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersToAccount(gomock.Any(), gomock.Any()).Times(1).Return(int64(2), nil)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.RiskAllow, assessment.Decision)
+			},
+		},
+		{
+			name: "NewRecipientSmallAmount",
+			rules: []Rule{
+				{Name: "new_recipient", Type: NewRecipientLargeAmountRule, Decision: util.RiskReview, MinAmount: 100000},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersToAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.RiskAllow, assessment.Decision)
+			},
+		},
+		{
+			name: "UnusualHourAcrossMidnight",
+			rules: []Rule{
+				{Name: "night", Type: UnusualHourRule, Decision: util.RiskReview, StartHour: 22, EndHour: 5},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.RiskReview, assessment.Decision)
+				require.Equal(t, "made at 03:30, between 22:00 and 05:00 UTC", assessment.Hits[0].Reason)
+			},
+		},
+		{
+			name: "UsualHourInLocation",
+			rules: []Rule{
+				// 03:30 UTC is 23:30 the day before in New York
+				{Name: "night", Type: UnusualHourRule, Decision: util.RiskReview, StartHour: 0, EndHour: 5,
+					Location: "America/New_York"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.RiskAllow, assessment.Decision)
+			},
+		},
+		{
+			name: "RoundAmount",
+			rules: []Rule{
+				{Name: "round", Type: RoundAmountRule, Decision: util.RiskAllow, Multiple: 10000, MinAmount: 10000},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				// Rules deciding allow record their hits without blocking the transfer
+				require.Equal(t, util.RiskAllow, assessment.Decision)
+				require.Len(t, assessment.Hits, 1)
+				require.Equal(t, "amount 50000 is a multiple of 10000", assessment.Hits[0].Reason)
+			},
+		},
+		{
+			name: "OtherCurrency",
+			rules: []Rule{
+				{Name: "round", Type: RoundAmountRule, Decision: util.RiskDeny, Currency: util.EUR, Multiple: 10000},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.RiskAllow, assessment.Decision)
+				require.Empty(t, assessment.Hits)
+			},
+		},
+		{
+			name: "MostSevereDecision",
+			rules: []Rule{
+				{Name: "round", Type: RoundAmountRule, Decision: util.RiskAllow, Multiple: 10000},
+				{Name: "night", Type: UnusualHourRule, Decision: util.RiskDeny, StartHour: 1, EndHour: 4},
+				{Name: "large_round", Type: RoundAmountRule, Decision: util.RiskReview, Multiple: 50000},
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.RiskDeny, assessment.Decision)
+				require.Len(t, assessment.Hits, 3)
+			},
+		},
+		{
+			name: "StoreError",
+			rules: []Rule{
+				{Name: "burst", Type: VelocityRule, Decision: util.RiskReview, Window: "1h", MaxCount: 3},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetOutgoingTransferStatsSince(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetOutgoingTransferStatsSinceRow{}, errors.New("connection lost"))
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			engine, err := NewEngine(store, tc.rules)
+			require.NoError(t, err)
+
+			engine.now = func() time.Time {
+				return now
+			}
+
+			assessment, err := engine.Evaluate(context.Background(), transfer)
+			tc.checkAssessment(t, assessment, err)
+		})
+	}
+}
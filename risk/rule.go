@@ -0,0 +1,143 @@
+package risk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"io/ioutil"
+	"time"
+)
+
+// Supported types of rules
+const (
+	// VelocityRule matches when the sender makes too many transfers or sends too much money within a window
+	VelocityRule = "velocity"
+	// NewRecipientLargeAmountRule matches large transfers to an account the sender never paid before
+	NewRecipientLargeAmountRule = "new_recipient_large_amount"
+	// UnusualHourRule matches transfers made between two hours of the day
+	UnusualHourRule = "unusual_hour"
+	// RoundAmountRule matches large amounts that are a multiple of a round number
+	RoundAmountRule = "round_amount"
+)
+
+// Rule is a check run against every transfer. Only the fields used by its type are read.
+type Rule struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Decision is taken when the rule matches, rules deciding allow only record their hits
+	Decision string `json:"decision"`
+	// Currency restricts the rule to transfers sent in the currency, the rule checks every transfer when it is empty
+	Currency string `json:"currency"`
+	// Window is how far back velocity rules look, e.g. "1h"
+	Window string `json:"window"`
+	// MaxCount and MaxAmount are the most transfers and money sent within the window, zero means no limit
+	MaxCount  int64 `json:"max_count"`
+	MaxAmount int64 `json:"max_amount"`
+	// MinAmount is the smallest amount matched by new recipient and round amount rules
+	MinAmount int64 `json:"min_amount"`
+	// StartHour and EndHour delimit the unusual hours in Location, the range wraps around midnight when EndHour is
+	// before StartHour
+	StartHour int    `json:"start_hour"`
+	EndHour   int    `json:"end_hour"`
+	Location  string `json:"location"`
+	// Multiple is the round number amounts are divided by
+	Multiple int64 `json:"multiple"`
+
+	window   time.Duration
+	location *time.Location
+}
+
+// rulesFile is the format of a rules file
+type rulesFile struct {
+	Rules []Rule `json:"rules"`
+}
+
+// LoadRules reads the rules from a JSON file of the form {"rules": [...]}
+func LoadRules(path string) ([]Rule, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read rules file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var file rulesFile
+
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("cannot parse rules file: %w", err)
+	}
+
+	return file.Rules, nil
+}
+
+// validate checks that the rule can be run and parses its window and location
+func (rule *Rule) validate() error {
+	if rule.Name == "" {
+		return errors.New("rule name is required")
+	}
+
+	if !util.IsSupportedRiskDecision(rule.Decision) {
+		return fmt.Errorf("rule %s: unsupported decision %q", rule.Name, rule.Decision)
+	}
+
+	if rule.Currency != "" && !util.IsSupportedCurrency(rule.Currency) {
+		return fmt.Errorf("rule %s: unsupported currency %q", rule.Name, rule.Currency)
+	}
+
+	switch rule.Type {
+	case VelocityRule:
+		window, err := time.ParseDuration(rule.Window)
+
+		if err != nil || window <= 0 {
+			return fmt.Errorf("rule %s: window must be a positive duration", rule.Name)
+		}
+
+		if rule.MaxCount <= 0 && rule.MaxAmount <= 0 {
+			return fmt.Errorf("rule %s: max_count or max_amount is required", rule.Name)
+		}
+
+		rule.window = window
+	case NewRecipientLargeAmountRule:
+		if rule.MinAmount <= 0 {
+			return fmt.Errorf("rule %s: min_amount must be positive", rule.Name)
+		}
+	case UnusualHourRule:
+		if rule.StartHour < 0 || rule.StartHour > 23 || rule.EndHour < 0 || rule.EndHour > 23 {
+			return fmt.Errorf("rule %s: start_hour and end_hour must be between 0 and 23", rule.Name)
+		}
+
+		if rule.StartHour == rule.EndHour {
+			return fmt.Errorf("rule %s: start_hour and end_hour must be different", rule.Name)
+		}
+
+		location, err := time.LoadLocation(rule.Location)
+
+		if err != nil {
+			return fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+
+		rule.location = location
+	case RoundAmountRule:
+		if rule.Multiple <= 0 {
+			return fmt.Errorf("rule %s: multiple must be positive", rule.Name)
+		}
+	default:
+		return fmt.Errorf("rule %s: unsupported type %q", rule.Name, rule.Type)
+	}
+
+	return nil
+}
+
+// isUnusualHour returns true if the hour is within the unusual hours of the rule, StartHour included and EndHour
+// excluded
+func (rule Rule) isUnusualHour(hour int) bool {
+	if rule.StartHour < rule.EndHour {
+		return hour >= rule.StartHour && hour < rule.EndHour
+	}
+
+	return hour >= rule.StartHour || hour < rule.EndHour
+}
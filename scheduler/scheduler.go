@@ -0,0 +1,254 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/risk"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"log"
+	"time"
+)
+
+const (
+	// DefaultBatchSize is the number of due transfers claimed per run when no batch size is set
+	DefaultBatchSize = 100
+
+	// ClaimTimeout is how long a claimed transfer may stay processing before another worker claims it again. A worker
+	// that takes longer loses its claim and neither makes the transfer nor records the attempt.
+	ClaimTimeout = 10 * time.Minute
+)
+
+// RetryPolicy controls how scheduled transfers that failed for lack of funds are tried again. Every other failure is
+// final, trying again would fail the same way.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a scheduled transfer is tried before it is marked as failed, including the
+	// first attempt
+	MaxAttempts int32
+	// Delay is how long the worker waits before trying a failed transfer again, multiplied by the number of attempts
+	// made so far
+	Delay time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used when none is configured
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Delay:       time.Minute,
+}
+
+// RiskEvaluator assesses a scheduled transfer before it is made, only transfers it allows are made
+type RiskEvaluator interface {
+	Evaluate(ctx context.Context, transfer risk.Transfer) (risk.Assessment, error)
+}
+
+// riskDecisionError is returned when the risk rules do not allow a scheduled transfer. The transfer is not retried,
+// the rules that matched are left out of the error since it is shown to the customer.
+type riskDecisionError struct {
+	decision db.RiskDecision
+}
+
+func (e *riskDecisionError) Error() string {
+	return fmt.Sprintf("transfer was not allowed by the risk rules, decision %s [%d]", e.decision.Decision,
+		e.decision.ID)
+}
+
+// Worker executes scheduled transfers once they are due, including the transfers of standing orders, and closes money
+// requests and transfer approvals once they expire.
+// Several workers may run against the same database, each due transfer is claimed by one of them at a time. The money
+// is moved in the same database transaction that completes the scheduled transfer, so a transfer left processing
+// because a worker stopped mid-way has not been made. It is claimed again once the claim times out.
+type Worker struct {
+	store         db.Store
+	riskEvaluator RiskEvaluator
+	interval      time.Duration
+	batchSize     int32
+	retryPolicy   RetryPolicy
+}
+
+// NewWorker creates a new Worker which looks for due transfers every interval, batchSize transfers at a time. Each
+// transfer is assessed by the risk evaluator before it is made, transfers that fail are retried as the retry policy
+// allows.
+func NewWorker(store db.Store, riskEvaluator RiskEvaluator, interval time.Duration, batchSize int32,
+	retryPolicy RetryPolicy) (*Worker, error) {
+	if interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+
+	if batchSize <= 0 {
+		return nil, errors.New("batch size must be positive")
+	}
+
+	if retryPolicy.MaxAttempts <= 0 {
+		return nil, errors.New("max attempts must be positive")
+	}
+
+	if retryPolicy.Delay < 0 {
+		return nil, errors.New("retry delay cannot be negative")
+	}
+
+	return &Worker{
+		store:         store,
+		riskEvaluator: riskEvaluator,
+		interval:      interval,
+		batchSize:     batchSize,
+		retryPolicy:   retryPolicy,
+	}, nil
+}
+
+// Start runs the worker until the context is done
+func (worker *Worker) Start(ctx context.Context) {
+	ticker := time.NewTicker(worker.interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while full batches are claimed, there may be more transfers due
+		for {
+			processed, err := worker.RunOnce(ctx)
+
+			if err != nil {
+				log.Println("cannot execute scheduled transfers:", err)
+				break
+			}
+
+			if processed < int(worker.batchSize) {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce expires money requests, transfer approvals and pending transfers, whose held money is released, and
+// schedules the transfers of due standing orders, then claims a batch of due transfers and transfers whose claim timed
+// out and executes them. It returns
+// the number of transfers claimed. Failing to expire something or to execute a transfer is logged, it does not stop
+// the other transfers from being executed.
+func (worker *Worker) RunOnce(ctx context.Context) (int, error) {
+	if err := worker.store.ExpireMoneyRequests(ctx); err != nil {
+		log.Println("cannot expire money requests:", err)
+	}
+
+	if err := worker.store.ExpireTransferApprovalsTx(ctx); err != nil {
+		log.Println("cannot expire transfer approvals:", err)
+	}
+
+	if err := worker.store.ExpirePendingTransfersTx(ctx); err != nil {
+		log.Println("cannot expire pending transfers:", err)
+	}
+
+	if _, err := worker.store.GenerateStandingOrderTransfersTx(ctx, worker.batchSize); err != nil {
+		return 0, err
+	}
+
+	scheduledTransfers, err := worker.store.ClaimDueScheduledTransfers(ctx, db.ClaimDueScheduledTransfersParams{
+		StaleBefore: sql.NullTime{Time: time.Now().Add(-ClaimTimeout), Valid: true},
+		Limit:       worker.batchSize,
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	// The whole batch is claimed, every transfer must be executed or it would be left processing
+	for _, scheduledTransfer := range scheduledTransfers {
+		if err := worker.execute(ctx, scheduledTransfer); err != nil {
+			log.Printf("cannot execute scheduled transfer [%d]: %v", scheduledTransfer.ID, err)
+		}
+	}
+
+	return len(scheduledTransfers), nil
+}
+
+// assess runs the risk rules against the scheduled transfer as it is about to be made and records the decision if any
+// rule matched, like for transfers made through the API. A riskDecisionError is returned if the transfer is not allowed.
+func (worker *Worker) assess(ctx context.Context, scheduledTransfer db.ScheduledTransfer) error {
+	fromAccount, err := worker.store.GetAccount(ctx, int32(scheduledTransfer.FromAccountID))
+
+	if err != nil {
+		return err
+	}
+
+	transfer := risk.Transfer{
+		Username:      fromAccount.Owner,
+		FromAccountID: scheduledTransfer.FromAccountID,
+		ToAccountID:   scheduledTransfer.ToAccountID,
+		Amount:        scheduledTransfer.Amount,
+		Currency:      fromAccount.Currency,
+	}
+
+	assessment, err := worker.riskEvaluator.Evaluate(ctx, transfer)
+
+	if err != nil {
+		return err
+	}
+
+	if len(assessment.Hits) == 0 {
+		return nil
+	}
+
+	ruleHits, err := json.Marshal(assessment.Hits)
+
+	if err != nil {
+		return err
+	}
+
+	decision, err := worker.store.CreateRiskDecision(ctx, db.CreateRiskDecisionParams{
+		Username:      transfer.Username,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Currency:      transfer.Currency,
+		Decision:      assessment.Decision,
+		RuleHits:      ruleHits,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if decision.Decision != util.RiskAllow {
+		return &riskDecisionError{decision: decision}
+	}
+
+	return nil
+}
+
+// execute makes the transfer, which records the successful attempt with it, or records the failed attempt. A transfer
+// that failed for lack of funds is retried later until it has been tried as many times as the retry policy allows, the
+// sender may still add money. Any other failure is final.
+func (worker *Worker) execute(ctx context.Context, scheduledTransfer db.ScheduledTransfer) error {
+	err := worker.assess(ctx, scheduledTransfer)
+
+	if err == nil {
+		_, err = worker.store.ExecuteScheduledTransferTx(ctx, scheduledTransfer)
+
+		// Another worker owns the transfer once the claim is lost, the attempt is left for it to record
+		if err == nil || errors.Is(err, db.ErrScheduledTransferNotClaimed) {
+			return err
+		}
+	}
+
+	arg := db.RecordScheduledTransferAttemptTxParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		AttemptCount:        scheduledTransfer.AttemptCount,
+		Status:              util.ScheduledTransferFailed,
+		Error:               err.Error(),
+		NextAttemptAt:       scheduledTransfer.NextAttemptAt,
+	}
+
+	if errors.Is(err, db.ErrInsufficientFunds) && scheduledTransfer.AttemptCount < worker.retryPolicy.MaxAttempts {
+		arg.Status = util.ScheduledTransferPending
+		arg.NextAttemptAt = time.Now().Add(worker.retryPolicy.Delay * time.Duration(scheduledTransfer.AttemptCount))
+	}
+
+	_, err = worker.store.RecordScheduledTransferAttemptTx(ctx, arg)
+	return err
+}
@@ -0,0 +1,601 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/jwambugu/go-simple-bank-class/db/mock"
+	db "github.com/jwambugu/go-simple-bank-class/db/sqlc"
+	"github.com/jwambugu/go-simple-bank-class/risk"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func stubNoStandingOrdersDue(store *mockdb.MockStore) {
+	store.EXPECT().
+		GenerateStandingOrderTransfersTx(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return([]db.ScheduledTransfer{}, nil)
+}
+
+func stubNothingToExpire(store *mockdb.MockStore) {
+	store.EXPECT().ExpireMoneyRequests(gomock.Any()).AnyTimes().Return(nil)
+	store.EXPECT().ExpireTransferApprovalsTx(gomock.Any()).AnyTimes().Return(nil)
+	store.EXPECT().ExpirePendingTransfersTx(gomock.Any()).AnyTimes().Return(nil)
+}
+
+func stubSenderAccount(store *mockdb.MockStore) {
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(db.Account{ID: 10, Owner: "alice", Currency: util.USD}, nil)
+}
+
+// newTestWorker creates a worker running the rules against every scheduled transfer
+func newTestWorker(t *testing.T, store db.Store, batchSize int32, rules ...risk.Rule) *Worker {
+	engine, err := risk.NewEngine(store, rules)
+	require.NoError(t, err)
+
+	worker, err := NewWorker(store, engine, time.Second, batchSize, DefaultRetryPolicy)
+	require.NoError(t, err)
+
+	return worker
+}
+
+func TestNewWorker(t *testing.T) {
+	_, err := NewWorker(nil, nil, 0, DefaultBatchSize, DefaultRetryPolicy)
+	require.Error(t, err)
+
+	_, err = NewWorker(nil, nil, time.Second, 0, DefaultRetryPolicy)
+	require.Error(t, err)
+
+	_, err = NewWorker(nil, nil, time.Second, DefaultBatchSize, RetryPolicy{MaxAttempts: 0, Delay: time.Minute})
+	require.Error(t, err)
+
+	_, err = NewWorker(nil, nil, time.Second, DefaultBatchSize, RetryPolicy{MaxAttempts: 3, Delay: -time.Minute})
+	require.Error(t, err)
+}
+
+func TestWorker_RunOnce(t *testing.T) {
+	scheduledTransfer := db.ScheduledTransfer{
+		ID:            1,
+		FromAccountID: 10,
+		ToAccountID:   20,
+		Amount:        100,
+		Status:        util.ScheduledTransferProcessing,
+		NextAttemptAt: time.Now().Add(-time.Minute),
+		AttemptCount:  1,
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, processed int, err error)
+	}{
+		{
+			name: "Completed",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.ClaimDueScheduledTransfersParams) (
+						[]db.ScheduledTransfer, error) {
+
+						// Transfers claimed longer than the timeout ago are claimed again
+						require.Equal(t, int32(2), arg.Limit)
+						require.True(t, arg.StaleBefore.Valid)
+						require.WithinDuration(t, time.Now().Add(-ClaimTimeout), arg.StaleBefore.Time, time.Second)
+
+						return []db.ScheduledTransfer{scheduledTransfer}, nil
+					})
+
+				store.EXPECT().
+					ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(scheduledTransfer)).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: db.Transfer{ID: 5}}, nil)
+
+				// The successful attempt is recorded with the transfer
+				store.EXPECT().RecordScheduledTransferAttemptTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, processed int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, processed)
+			},
+		},
+		{
+			name: "RetriedAfterFailure",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ScheduledTransfer{scheduledTransfer}, nil)
+
+				store.EXPECT().
+					ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(scheduledTransfer)).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+
+				store.EXPECT().
+					RecordScheduledTransferAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RecordScheduledTransferAttemptTxParams) (
+						db.ScheduledTransfer, error) {
+
+						require.Equal(t, util.ScheduledTransferPending, arg.Status)
+						require.Equal(t, scheduledTransfer.AttemptCount, arg.AttemptCount)
+						require.Equal(t, db.ErrInsufficientFunds.Error(), arg.Error)
+						require.False(t, arg.TransferID.Valid)
+						require.WithinDuration(t, time.Now().Add(DefaultRetryPolicy.Delay), arg.NextAttemptAt, time.Second)
+
+						return db.ScheduledTransfer{}, nil
+					})
+			},
+			check: func(t *testing.T, processed int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, processed)
+			},
+		},
+		{
+			name: "FailedAfterLastAttempt",
+			buildStubs: func(store *mockdb.MockStore) {
+				lastAttempt := scheduledTransfer
+				lastAttempt.AttemptCount = DefaultRetryPolicy.MaxAttempts
+
+				store.EXPECT().
+					ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ScheduledTransfer{lastAttempt}, nil)
+
+				store.EXPECT().
+					ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+
+				arg := db.RecordScheduledTransferAttemptTxParams{
+					ScheduledTransferID: scheduledTransfer.ID,
+					AttemptCount:        DefaultRetryPolicy.MaxAttempts,
+					Status:              util.ScheduledTransferFailed,
+					Error:               db.ErrInsufficientFunds.Error(),
+					NextAttemptAt:       scheduledTransfer.NextAttemptAt,
+				}
+
+				store.EXPECT().
+					RecordScheduledTransferAttemptTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ScheduledTransfer{}, nil)
+			},
+			check: func(t *testing.T, processed int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, processed)
+			},
+		},
+		{
+			name: "ClaimLost",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ScheduledTransfer{scheduledTransfer}, nil)
+
+				store.EXPECT().
+					ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(scheduledTransfer)).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrScheduledTransferNotClaimed)
+
+				// The worker which claimed the transfer again records the attempt
+				store.EXPECT().RecordScheduledTransferAttemptTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, processed int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, processed)
+			},
+		},
+		{
+			name: "RecordErrorDoesNotStopBatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				other := scheduledTransfer
+				other.ID = 2
+
+				store.EXPECT().
+					ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ScheduledTransfer{scheduledTransfer, other}, nil)
+
+				store.EXPECT().
+					ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(scheduledTransfer)).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+
+				store.EXPECT().
+					RecordScheduledTransferAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ScheduledTransfer{}, sql.ErrConnDone)
+
+				// The rest of the claimed batch is still executed
+				store.EXPECT().
+					ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(other)).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: db.Transfer{ID: 6}}, nil)
+			},
+			check: func(t *testing.T, processed int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 2, processed)
+			},
+		},
+		{
+			name: "ClaimError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ScheduledTransfer{}, sql.ErrConnDone)
+
+				store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, processed int, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Zero(t, processed)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubNothingToExpire(store)
+			stubNoStandingOrdersDue(store)
+			stubSenderAccount(store)
+
+			worker := newTestWorker(t, store, 2)
+
+			processed, err := worker.RunOnce(context.Background())
+			tc.check(t, processed, err)
+		})
+	}
+}
+
+func TestWorker_RunOnceRetry(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 5,
+		Delay:       30 * time.Second,
+	}
+
+	testCases := []struct {
+		name         string
+		err          error
+		attemptCount int32
+		status       string
+		retryAfter   time.Duration
+	}{
+		{
+			name:         "InsufficientFunds",
+			err:          db.ErrInsufficientFunds,
+			attemptCount: 2,
+			status:       util.ScheduledTransferPending,
+			retryAfter:   time.Minute,
+		},
+		{
+			name:         "InsufficientFundsLastAttempt",
+			err:          db.ErrInsufficientFunds,
+			attemptCount: 5,
+			status:       util.ScheduledTransferFailed,
+		},
+		{
+			name:         "TransferLimitExceeded",
+			err:          &db.TransferLimitError{Period: "daily", Limit: 100, Remaining: 0, Currency: util.USD},
+			attemptCount: 1,
+			status:       util.ScheduledTransferFailed,
+		},
+		{
+			name:         "AccountNotFound",
+			err:          sql.ErrNoRows,
+			attemptCount: 1,
+			status:       util.ScheduledTransferFailed,
+		},
+		{
+			name:         "DatabaseError",
+			err:          sql.ErrConnDone,
+			attemptCount: 1,
+			status:       util.ScheduledTransferFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			scheduledTransfer := db.ScheduledTransfer{
+				ID:            1,
+				FromAccountID: 10,
+				ToAccountID:   20,
+				Amount:        150,
+				Status:        util.ScheduledTransferProcessing,
+				NextAttemptAt: time.Now().Add(-time.Minute),
+				AttemptCount:  tc.attemptCount,
+			}
+
+			store := mockdb.NewMockStore(ctrl)
+			stubNothingToExpire(store)
+			stubNoStandingOrdersDue(store)
+			stubSenderAccount(store)
+
+			store.EXPECT().
+				ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return([]db.ScheduledTransfer{scheduledTransfer}, nil)
+
+			store.EXPECT().
+				ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(scheduledTransfer)).
+				Times(1).
+				Return(db.TransferTxResult{}, tc.err)
+
+			store.EXPECT().
+				RecordScheduledTransferAttemptTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.RecordScheduledTransferAttemptTxParams) (
+					db.ScheduledTransfer, error) {
+
+					require.Equal(t, tc.status, arg.Status)
+					require.Equal(t, tc.err.Error(), arg.Error)
+
+					// Failed transfers keep the time of their last attempt
+					if tc.status == util.ScheduledTransferFailed {
+						require.Equal(t, scheduledTransfer.NextAttemptAt, arg.NextAttemptAt)
+					} else {
+						require.WithinDuration(t, time.Now().Add(tc.retryAfter), arg.NextAttemptAt, time.Second)
+					}
+
+					return db.ScheduledTransfer{}, nil
+				})
+
+			engine, err := risk.NewEngine(store, nil)
+			require.NoError(t, err)
+
+			worker, err := NewWorker(store, engine, time.Second, DefaultBatchSize, policy)
+			require.NoError(t, err)
+
+			processed, err := worker.RunOnce(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, processed)
+		})
+	}
+}
+
+func TestWorker_RunOnceStandingOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	stubNothingToExpire(store)
+
+	// Transfers of standing orders are scheduled before the due transfers are claimed
+	gomock.InOrder(
+		store.EXPECT().
+			GenerateStandingOrderTransfersTx(gomock.Any(), gomock.Eq(int32(DefaultBatchSize))).
+			Times(1).
+			Return([]db.ScheduledTransfer{{ID: 1}}, nil),
+		store.EXPECT().
+			ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return([]db.ScheduledTransfer{}, nil),
+	)
+
+	worker := newTestWorker(t, store, DefaultBatchSize)
+
+	processed, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, processed)
+
+	store.EXPECT().
+		GenerateStandingOrderTransfersTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.ScheduledTransfer{}, sql.ErrConnDone)
+
+	_, err = worker.RunOnce(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestWorker_RunOnceExpiryErrors(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "MoneyRequests",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExpireMoneyRequests(gomock.Any()).Times(1).Return(sql.ErrConnDone)
+				store.EXPECT().ExpireTransferApprovalsTx(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().ExpirePendingTransfersTx(gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name: "TransferApprovals",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExpireMoneyRequests(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().ExpireTransferApprovalsTx(gomock.Any()).Times(1).Return(sql.ErrConnDone)
+				store.EXPECT().ExpirePendingTransfersTx(gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name: "PendingTransfers",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ExpireMoneyRequests(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().ExpireTransferApprovalsTx(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().ExpirePendingTransfersTx(gomock.Any()).Times(1).Return(sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubNoStandingOrdersDue(store)
+
+			// Failing to expire something is logged, the due transfers are still executed
+			store.EXPECT().
+				ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return([]db.ScheduledTransfer{}, nil)
+
+			worker := newTestWorker(t, store, DefaultBatchSize)
+
+			_, err := worker.RunOnce(context.Background())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWorker_RunOnceRisk(t *testing.T) {
+	scheduledTransfer := db.ScheduledTransfer{
+		ID:            1,
+		FromAccountID: 10,
+		ToAccountID:   20,
+		Amount:        100,
+		Status:        util.ScheduledTransferProcessing,
+		NextAttemptAt: time.Now().Add(-time.Minute),
+		AttemptCount:  1,
+	}
+
+	testCases := []struct {
+		name       string
+		decision   string
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name:     "Allowed",
+			decision: util.RiskAllow,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateRiskDecision(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateRiskDecisionParams) (db.RiskDecision, error) {
+						require.Equal(t, "alice", arg.Username)
+						require.Equal(t, scheduledTransfer.FromAccountID, arg.FromAccountID)
+						require.Equal(t, scheduledTransfer.ToAccountID, arg.ToAccountID)
+						require.Equal(t, scheduledTransfer.Amount, arg.Amount)
+						require.Equal(t, util.USD, arg.Currency)
+
+						return db.RiskDecision{ID: 3, Decision: arg.Decision}, nil
+					})
+
+				store.EXPECT().
+					ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(scheduledTransfer)).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: db.Transfer{ID: 5}}, nil)
+
+				store.EXPECT().RecordScheduledTransferAttemptTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:     "Denied",
+			decision: util.RiskDeny,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateRiskDecision(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RiskDecision{ID: 4, Decision: util.RiskDeny}, nil)
+
+				store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				// The rules would not allow it later either, so it fails without being retried
+				store.EXPECT().
+					RecordScheduledTransferAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RecordScheduledTransferAttemptTxParams) (
+						db.ScheduledTransfer, error) {
+
+						require.Equal(t, util.ScheduledTransferFailed, arg.Status)
+						require.Contains(t, arg.Error, "[4]")
+						require.NotContains(t, arg.Error, "round")
+
+						return db.ScheduledTransfer{}, nil
+					})
+			},
+		},
+		{
+			name:     "NeedsReview",
+			decision: util.RiskReview,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateRiskDecision(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RiskDecision{ID: 5, Decision: util.RiskReview}, nil)
+
+				store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				store.EXPECT().
+					RecordScheduledTransferAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RecordScheduledTransferAttemptTxParams) (
+						db.ScheduledTransfer, error) {
+
+						require.Equal(t, util.ScheduledTransferFailed, arg.Status)
+						return db.ScheduledTransfer{}, nil
+					})
+			},
+		},
+		{
+			name:     "CreateRiskDecisionError",
+			decision: util.RiskDeny,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateRiskDecision(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RiskDecision{}, sql.ErrConnDone)
+
+				store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+				// Only a lack of funds is retried
+				store.EXPECT().
+					RecordScheduledTransferAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RecordScheduledTransferAttemptTxParams) (
+						db.ScheduledTransfer, error) {
+
+						require.Equal(t, util.ScheduledTransferFailed, arg.Status)
+						return db.ScheduledTransfer{}, nil
+					})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubNothingToExpire(store)
+			stubNoStandingOrdersDue(store)
+			stubSenderAccount(store)
+
+			store.EXPECT().
+				ClaimDueScheduledTransfers(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return([]db.ScheduledTransfer{scheduledTransfer}, nil)
+
+			worker := newTestWorker(t, store, DefaultBatchSize, risk.Rule{
+				Name:     "round",
+				Type:     risk.RoundAmountRule,
+				Decision: tc.decision,
+				Multiple: 100,
+			})
+
+			processed, err := worker.RunOnce(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, processed)
+		})
+	}
+}
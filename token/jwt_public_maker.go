@@ -0,0 +1,107 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"github.com/dgrijalva/jwt-go"
+	"time"
+)
+
+const keyIDHeader = "kid"
+
+// JWTPublicMaker is a JSON Web Token maker which signs tokens with an asymmetric key.
+// Ed25519 keys sign with EdDSA and RSA keys sign with RS256.
+// Tokens carry the ID of their signing key in the "kid" header.
+type JWTPublicMaker struct {
+	keyring *Keyring
+}
+
+// CreateToken creates a new token for a specific username, role, type and duration
+func (maker *JWTPublicMaker) CreateToken(
+	username string,
+	role string,
+	tokenType string,
+	duration time.Duration,
+) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, tokenType, duration)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := maker.keyring.SigningKey()
+
+	jwtToken := jwt.NewWithClaims(jwtSigningMethod(key), payload)
+	jwtToken.Header[keyIDHeader] = key.ID
+
+	token, err := jwtToken.SignedString(key.PrivateKey)
+	return token, payload, err
+}
+
+// VerifyToken checks if the token is valid or not
+func (maker *JWTPublicMaker) VerifyToken(token string) (*Payload, error) {
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, func(token *jwt.Token) (interface{}, error) {
+		keyID, ok := token.Header[keyIDHeader].(string)
+
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		key, ok := maker.keyring.VerificationKey(keyID)
+
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		// The algorithm must be the one of the key, never the one the token claims
+		if token.Method != jwtSigningMethod(key) {
+			return nil, ErrInvalidToken
+		}
+
+		return key.PublicKey, nil
+	})
+
+	if err != nil {
+		validationErr, ok := err.(*jwt.ValidationError)
+
+		if ok && errors.Is(validationErr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+// Keyring returns the keys used by the maker
+func (maker *JWTPublicMaker) Keyring() *Keyring {
+	return maker.keyring
+}
+
+func jwtSigningMethod(key Key) jwt.SigningMethod {
+	switch key.PublicKey.(type) {
+	case ed25519.PublicKey:
+		return SigningMethodEdDSA
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256
+	}
+
+	return nil
+}
+
+// NewJWTPublicMaker creates a new JWTPublicMaker
+func NewJWTPublicMaker(keyring *Keyring) (Maker, error) {
+	if jwtSigningMethod(keyring.SigningKey()) == nil {
+		return nil, ErrUnsupportedKey
+	}
+
+	return &JWTPublicMaker{keyring: keyring}, nil
+}
@@ -0,0 +1,112 @@
+package token
+
+import (
+	"github.com/dgrijalva/jwt-go"
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestNewJWTPublicMaker(t *testing.T) {
+	for _, key := range []Key{randomEd25519Key(t), randomRSAKey(t)} {
+		keyring, err := NewKeyring(key)
+		require.NoError(t, err)
+
+		maker, err := NewJWTPublicMaker(keyring)
+		require.NoError(t, err)
+
+		username := util.RandomOwner()
+		role := util.BankerRole
+		duration := time.Minute
+
+		issuedAt := time.Now()
+		expiresAt := issuedAt.Add(duration)
+
+		token, payload, err := maker.CreateToken(username, role, AccessToken, duration)
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+		require.NotEmpty(t, payload)
+
+		payload, err = maker.VerifyToken(token)
+		require.NoError(t, err)
+		require.NotEmpty(t, payload)
+
+		require.NotZero(t, payload.ID)
+		require.Equal(t, username, payload.Username)
+		require.Equal(t, role, payload.Role)
+		require.Equal(t, AccessToken, payload.Type)
+		require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+		require.WithinDuration(t, expiresAt, payload.ExpiresAt, time.Second)
+	}
+}
+
+func TestExpiredJWTPublicToken(t *testing.T) {
+	keyring, err := NewKeyring(randomEd25519Key(t))
+	require.NoError(t, err)
+
+	maker, err := NewJWTPublicMaker(keyring)
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, AccessToken, -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestJWTPublicTokenKeyRotation(t *testing.T) {
+	keyring, err := NewKeyring(randomRSAKey(t))
+	require.NoError(t, err)
+
+	maker, err := NewJWTPublicMaker(keyring)
+	require.NoError(t, err)
+
+	oldKeyID := keyring.SigningKey().ID
+
+	token, _, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, AccessToken, time.Minute)
+	require.NoError(t, err)
+
+	// Tokens signed before the rotation are still valid
+	err = keyring.Rotate(randomRSAKey(t))
+	require.NoError(t, err)
+
+	_, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+
+	// Until the old key is retired
+	err = keyring.Retire(oldKeyID)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestInvalidJWTPublicTokenAlgorithm(t *testing.T) {
+	key := randomRSAKey(t)
+
+	keyring, err := NewKeyring(key)
+	require.NoError(t, err)
+
+	maker, err := NewJWTPublicMaker(keyring)
+	require.NoError(t, err)
+
+	payload, err := NewPayload(util.RandomOwner(), util.DepositorRole, AccessToken, time.Minute)
+	require.NoError(t, err)
+
+	// A token using the key ID but another algorithm must be rejected
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
+	jwtToken.Header[keyIDHeader] = key.ID
+
+	token, err := jwtToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	payload, err = maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
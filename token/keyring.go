@@ -0,0 +1,321 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sync"
+)
+
+const minRSAKeyBits = 2048
+
+// Different types of error returned when managing keys
+var (
+	ErrUnsupportedKey  = errors.New("unsupported key type: must be ed25519 or rsa")
+	ErrKeyTypeMismatch = errors.New("all keys of a keyring must be of the same type")
+	ErrUnknownKey      = errors.New("unknown key")
+)
+
+// Key is an asymmetric key used to sign or verify tokens
+type Key struct {
+	ID         string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// CanSign checks if the key holds a private key that can sign tokens
+func (key Key) CanSign() bool {
+	return key.PrivateKey != nil
+}
+
+// NewSigningKey creates a key that can both sign and verify tokens
+func NewSigningKey(privateKey crypto.Signer) (Key, error) {
+	key, err := NewVerificationKey(privateKey.Public())
+
+	if err != nil {
+		return Key{}, err
+	}
+
+	key.PrivateKey = privateKey
+	return key, nil
+}
+
+// NewVerificationKey creates a key that can only verify tokens.
+// The key ID is derived from the public key so every service computes the same ID for the same key.
+func NewVerificationKey(publicKey crypto.PublicKey) (Key, error) {
+	switch k := publicKey.(type) {
+	case ed25519.PublicKey:
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return Key{}, fmt.Errorf("invalid key size: rsa keys must be at least %d bits", minRSAKeyBits)
+		}
+	default:
+		return Key{}, ErrUnsupportedKey
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+
+	if err != nil {
+		return Key{}, err
+	}
+
+	sum := sha256.Sum256(der)
+
+	key := Key{
+		ID:        base64.RawURLEncoding.EncodeToString(sum[:12]),
+		PublicKey: publicKey,
+	}
+
+	return key, nil
+}
+
+// Keyring holds the key used to sign new tokens and every key whose tokens are still accepted.
+// Rotating the signing key keeps the previous one for verification until it is retired,
+// so tokens issued before the rotation stay valid.
+type Keyring struct {
+	mu         sync.RWMutex
+	signingKey Key
+	keys       map[string]Key
+}
+
+// SigningKey returns the key used to sign new tokens
+func (keyring *Keyring) SigningKey() Key {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+
+	return keyring.signingKey
+}
+
+// VerificationKey returns the key with the specified ID if its tokens are still accepted
+func (keyring *Keyring) VerificationKey(id string) (Key, bool) {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+
+	key, ok := keyring.keys[id]
+	return key, ok
+}
+
+// Rotate makes the key the new signing key. The previous signing key is kept for verification.
+func (keyring *Keyring) Rotate(signingKey Key) error {
+	if !signingKey.CanSign() {
+		return errors.New("signing key must have a private key")
+	}
+
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+
+	if !sameKeyType(keyring.signingKey, signingKey) {
+		return ErrKeyTypeMismatch
+	}
+
+	keyring.signingKey = signingKey
+	keyring.keys[signingKey.ID] = signingKey
+
+	return nil
+}
+
+// Retire stops accepting tokens signed with the key with the specified ID
+func (keyring *Keyring) Retire(id string) error {
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+
+	if id == keyring.signingKey.ID {
+		return errors.New("cannot retire the signing key")
+	}
+
+	if _, ok := keyring.keys[id]; !ok {
+		return ErrUnknownKey
+	}
+
+	delete(keyring.keys, id)
+	return nil
+}
+
+// JWKS returns the public keys of the keyring as a JSON Web Key Set
+func (keyring *Keyring) JWKS() JWKS {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(keyring.keys))}
+
+	// Always list the signing key first
+	jwks.Keys = append(jwks.Keys, newJWK(keyring.signingKey))
+
+	for id, key := range keyring.keys {
+		if id != keyring.signingKey.ID {
+			jwks.Keys = append(jwks.Keys, newJWK(key))
+		}
+	}
+
+	return jwks
+}
+
+// NewKeyring creates a new Keyring which signs tokens with the signing key and also accepts tokens signed by any of
+// the verification keys
+func NewKeyring(signingKey Key, verificationKeys ...Key) (*Keyring, error) {
+	if !signingKey.CanSign() {
+		return nil, errors.New("signing key must have a private key")
+	}
+
+	keyring := &Keyring{
+		signingKey: signingKey,
+		keys:       map[string]Key{signingKey.ID: signingKey},
+	}
+
+	for _, key := range verificationKeys {
+		if !sameKeyType(signingKey, key) {
+			return nil, ErrKeyTypeMismatch
+		}
+
+		keyring.keys[key.ID] = key
+	}
+
+	return keyring, nil
+}
+
+// LoadKeyring creates a new Keyring from a PEM encoded PKCS #8 private key file and PEM encoded PKIX public key files
+func LoadKeyring(signingKeyFile string, verificationKeyFiles []string) (*Keyring, error) {
+	data, err := ioutil.ReadFile(signingKeyFile)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read signing key: %w", err)
+	}
+
+	privateKey, err := ParsePrivateKeyPEM(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	signingKey, err := NewSigningKey(privateKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	verificationKeys := make([]Key, 0, len(verificationKeyFiles))
+
+	for _, file := range verificationKeyFiles {
+		data, err := ioutil.ReadFile(file)
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot read verification key: %w", err)
+		}
+
+		publicKey, err := ParsePublicKeyPEM(data)
+
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := NewVerificationKey(publicKey)
+
+		if err != nil {
+			return nil, err
+		}
+
+		verificationKeys = append(verificationKeys, key)
+	}
+
+	return NewKeyring(signingKey, verificationKeys...)
+}
+
+// ParsePrivateKeyPEM parses a PEM encoded PKCS #8 private key
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block containing the private key")
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	return signer, nil
+}
+
+// ParsePublicKeyPEM parses a PEM encoded PKIX public key
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block containing the public key")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	return publicKey, nil
+}
+
+func sameKeyType(a, b Key) bool {
+	switch a.PublicKey.(type) {
+	case ed25519.PublicKey:
+		_, ok := b.PublicKey.(ed25519.PublicKey)
+		return ok
+	case *rsa.PublicKey:
+		_, ok := b.PublicKey.(*rsa.PublicKey)
+		return ok
+	}
+
+	return false
+}
+
+// JWK is a public key in the JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func newJWK(key Key) JWK {
+	jwk := JWK{
+		KeyID: key.ID,
+		Use:   "sig",
+	}
+
+	switch publicKey := key.PublicKey.(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Algorithm = SigningMethodEdDSA.Alg()
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.Algorithm = "RS256"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	}
+
+	return jwk
+}
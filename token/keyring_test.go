@@ -0,0 +1,149 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func randomEd25519Key(t *testing.T) Key {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	key, err := NewSigningKey(privateKey)
+	require.NoError(t, err)
+
+	return key
+}
+
+func randomRSAKey(t *testing.T) Key {
+	privateKey, err := rsa.GenerateKey(rand.Reader, minRSAKeyBits)
+	require.NoError(t, err)
+
+	key, err := NewSigningKey(privateKey)
+	require.NoError(t, err)
+
+	return key
+}
+
+func TestNewVerificationKey(t *testing.T) {
+	key := randomEd25519Key(t)
+
+	verificationKey, err := NewVerificationKey(key.PublicKey)
+	require.NoError(t, err)
+	require.Equal(t, key.ID, verificationKey.ID)
+	require.False(t, verificationKey.CanSign())
+	require.True(t, key.CanSign())
+
+	smallRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	_, err = NewVerificationKey(smallRSAKey.Public())
+	require.Error(t, err)
+
+	_, err = NewVerificationKey("not a key")
+	require.ErrorIs(t, err, ErrUnsupportedKey)
+}
+
+func TestKeyringRotation(t *testing.T) {
+	oldKey := randomEd25519Key(t)
+	newKey := randomEd25519Key(t)
+
+	keyring, err := NewKeyring(oldKey)
+	require.NoError(t, err)
+	require.Equal(t, oldKey.ID, keyring.SigningKey().ID)
+
+	err = keyring.Rotate(newKey)
+	require.NoError(t, err)
+	require.Equal(t, newKey.ID, keyring.SigningKey().ID)
+
+	// The old key still verifies tokens until it is retired
+	_, ok := keyring.VerificationKey(oldKey.ID)
+	require.True(t, ok)
+	require.Len(t, keyring.JWKS().Keys, 2)
+	require.Equal(t, newKey.ID, keyring.JWKS().Keys[0].KeyID)
+
+	err = keyring.Retire(oldKey.ID)
+	require.NoError(t, err)
+
+	_, ok = keyring.VerificationKey(oldKey.ID)
+	require.False(t, ok)
+
+	require.ErrorIs(t, keyring.Retire(oldKey.ID), ErrUnknownKey)
+	require.Error(t, keyring.Retire(newKey.ID))
+	require.ErrorIs(t, keyring.Rotate(randomRSAKey(t)), ErrKeyTypeMismatch)
+}
+
+func TestNewKeyringKeyTypeMismatch(t *testing.T) {
+	_, err := NewKeyring(randomEd25519Key(t), randomRSAKey(t))
+	require.ErrorIs(t, err, ErrKeyTypeMismatch)
+
+	verificationKey, err := NewVerificationKey(randomEd25519Key(t).PublicKey)
+	require.NoError(t, err)
+
+	_, err = NewKeyring(verificationKey)
+	require.Error(t, err)
+}
+
+func TestKeyringJWKS(t *testing.T) {
+	edKey := randomEd25519Key(t)
+
+	keyring, err := NewKeyring(edKey)
+	require.NoError(t, err)
+
+	jwk := keyring.JWKS().Keys[0]
+	require.Equal(t, "OKP", jwk.KeyType)
+	require.Equal(t, "Ed25519", jwk.Curve)
+	require.Equal(t, "EdDSA", jwk.Algorithm)
+	require.Equal(t, edKey.ID, jwk.KeyID)
+	require.NotEmpty(t, jwk.X)
+
+	rsaKey := randomRSAKey(t)
+
+	keyring, err = NewKeyring(rsaKey)
+	require.NoError(t, err)
+
+	jwk = keyring.JWKS().Keys[0]
+	require.Equal(t, "RSA", jwk.KeyType)
+	require.Equal(t, "RS256", jwk.Algorithm)
+	require.Equal(t, "AQAB", jwk.E)
+	require.NotEmpty(t, jwk.N)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	dir := t.TempDir()
+
+	signingKey := randomEd25519Key(t)
+	verificationKey := randomEd25519Key(t)
+
+	privateKeyDER, err := x509.MarshalPKCS8PrivateKey(signingKey.PrivateKey)
+	require.NoError(t, err)
+
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(verificationKey.PublicKey)
+	require.NoError(t, err)
+
+	signingKeyFile := filepath.Join(dir, "signing.pem")
+	verificationKeyFile := filepath.Join(dir, "verification.pem")
+
+	err = ioutil.WriteFile(signingKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDER}), 0600)
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(verificationKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER}), 0600)
+	require.NoError(t, err)
+
+	keyring, err := LoadKeyring(signingKeyFile, []string{verificationKeyFile})
+	require.NoError(t, err)
+	require.Equal(t, signingKey.ID, keyring.SigningKey().ID)
+
+	_, ok := keyring.VerificationKey(verificationKey.ID)
+	require.True(t, ok)
+
+	_, err = LoadKeyring(filepath.Join(dir, "missing.pem"), nil)
+	require.Error(t, err)
+}
@@ -4,9 +4,17 @@ import "time"
 
 // Maker is an interface for managing tokens
 type Maker interface {
-	// CreateToken creates a new token for a specific username and duration
-	CreateToken(username string, duration time.Duration) (string, error)
+	// CreateToken creates a new token for a specific username, role, type and duration
+	CreateToken(username string, role string, tokenType string, duration time.Duration) (string, *Payload, error)
 
 	// VerifyToken checks if the token is valid or not
 	VerifyToken(token string) (*Payload, error)
 }
+
+// PublicKeyMaker is a Maker which signs tokens with asymmetric keys so that other services can verify them offline
+type PublicKeyMaker interface {
+	Maker
+
+	// Keyring returns the keys used to sign and verify tokens
+	Keyring() *Keyring
+}
@@ -13,15 +13,21 @@ type PasetoMaker struct {
 	symmetricKey []byte
 }
 
-// CreateToken creates a new token for a specific username and duration
-func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+// CreateToken creates a new token for a specific username, role, type and duration
+func (maker *PasetoMaker) CreateToken(
+	username string,
+	role string,
+	tokenType string,
+	duration time.Duration,
+) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, tokenType, duration)
 
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	token, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	return token, payload, err
 }
 
 // VerifyToken checks if the token is valid or not
@@ -0,0 +1,84 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"github.com/o1egl/paseto"
+	"time"
+)
+
+// pasetoFooter is the unencrypted footer of a public PASETO token
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
+// PasetoPublicMaker is a PASETO v2.public token maker which signs tokens with Ed25519 keys.
+// Tokens carry the ID of their signing key in the footer.
+type PasetoPublicMaker struct {
+	paseto  *paseto.V2
+	keyring *Keyring
+}
+
+// CreateToken creates a new token for a specific username, role, type and duration
+func (maker *PasetoPublicMaker) CreateToken(
+	username string,
+	role string,
+	tokenType string,
+	duration time.Duration,
+) (string, *Payload, error) {
+	payload, err := NewPayload(username, role, tokenType, duration)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := maker.keyring.SigningKey()
+
+	token, err := maker.paseto.Sign(key.PrivateKey, payload, pasetoFooter{KeyID: key.ID})
+	return token, payload, err
+}
+
+// VerifyToken checks if the token is valid or not
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	var footer pasetoFooter
+
+	if err := paseto.ParseFooter(token, &footer); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	key, ok := maker.keyring.VerificationKey(footer.KeyID)
+
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{}
+
+	if err := maker.paseto.Verify(token, key.PublicKey, payload, nil); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := payload.Valid(); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// Keyring returns the keys used by the maker
+func (maker *PasetoPublicMaker) Keyring() *Keyring {
+	return maker.keyring
+}
+
+// NewPasetoPublicMaker creates a new PasetoPublicMaker
+func NewPasetoPublicMaker(keyring *Keyring) (Maker, error) {
+	if _, ok := keyring.SigningKey().PrivateKey.(ed25519.PrivateKey); !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	maker := &PasetoPublicMaker{
+		paseto:  paseto.NewV2(),
+		keyring: keyring,
+	}
+
+	return maker, nil
+}
@@ -0,0 +1,87 @@
+package token
+
+import (
+	"github.com/jwambugu/go-simple-bank-class/util"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestNewPasetoPublicMaker(t *testing.T) {
+	keyring, err := NewKeyring(randomEd25519Key(t))
+	require.NoError(t, err)
+
+	maker, err := NewPasetoPublicMaker(keyring)
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	role := util.BankerRole
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiresAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, role, AccessToken, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
+	require.Equal(t, AccessToken, payload.Type)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiresAt, payload.ExpiresAt, time.Second)
+}
+
+func TestNewPasetoPublicMakerRSAKey(t *testing.T) {
+	keyring, err := NewKeyring(randomRSAKey(t))
+	require.NoError(t, err)
+
+	maker, err := NewPasetoPublicMaker(keyring)
+	require.ErrorIs(t, err, ErrUnsupportedKey)
+	require.Nil(t, maker)
+}
+
+func TestExpiredPasetoPublicToken(t *testing.T) {
+	keyring, err := NewKeyring(randomEd25519Key(t))
+	require.NoError(t, err)
+
+	maker, err := NewPasetoPublicMaker(keyring)
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(util.RandomOwner(), util.DepositorRole, AccessToken, -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoPublicTokenUnknownKey(t *testing.T) {
+	keyring, err := NewKeyring(randomEd25519Key(t))
+	require.NoError(t, err)
+
+	maker, err := NewPasetoPublicMaker(keyring)
+	require.NoError(t, err)
+
+	otherKeyring, err := NewKeyring(randomEd25519Key(t))
+	require.NoError(t, err)
+
+	otherMaker, err := NewPasetoPublicMaker(otherKeyring)
+	require.NoError(t, err)
+
+	token, _, err := otherMaker.CreateToken(util.RandomOwner(), util.DepositorRole, AccessToken, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
@@ -12,10 +12,18 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// Types of token, an access token authorizes requests and a refresh token can only be used to renew access tokens
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
 // Payload contains the payload data of the token
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	Type      string    `json:"type"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
@@ -29,8 +37,8 @@ func (payload *Payload) Valid() error {
 	return nil
 }
 
-// NewPayload creates a new token payload with a specific username and duration
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+// NewPayload creates a new token payload with a specific username, role, type and duration
+func NewPayload(username string, role string, tokenType string, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 
 	if err != nil {
@@ -40,6 +48,8 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        tokenID,
 		Username:  username,
+		Role:      role,
+		Type:      tokenType,
 		IssuedAt:  time.Now(),
 		ExpiresAt: time.Now().Add(duration),
 	}
@@ -8,11 +8,21 @@ import (
 // Config stores all configuration of the application.
 // The values are read by viper from a config file or environment variable.
 type Config struct {
-	DBDriver            string        `mapstructure:"DB_DRIVER"`
-	DBSource            string        `mapstructure:"DB_SOURCE"`
-	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	DBDriver                  string        `mapstructure:"DB_DRIVER"`
+	DBSource                  string        `mapstructure:"DB_SOURCE"`
+	ServerAddress             string        `mapstructure:"SERVER_ADDRESS"`
+	TokenFormat               string        `mapstructure:"TOKEN_FORMAT"`
+	TokenSymmetricKey         string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenSigningKeyFile       string        `mapstructure:"TOKEN_SIGNING_KEY_FILE"`
+	TokenVerificationKeyFiles []string      `mapstructure:"TOKEN_VERIFICATION_KEY_FILES"`
+	AccessTokenDuration       time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration      time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	RevocationCacheTTL        time.Duration `mapstructure:"REVOCATION_CACHE_TTL"`
+	SchedulerInterval         time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	SchedulerMaxAttempts      int32         `mapstructure:"SCHEDULER_MAX_ATTEMPTS"`
+	SchedulerRetryDelay       time.Duration `mapstructure:"SCHEDULER_RETRY_DELAY"`
+	RiskRulesFile             string        `mapstructure:"RISK_RULES_FILE"`
+	TransferApprovalThreshold int64         `mapstructure:"TRANSFER_APPROVAL_THRESHOLD"`
 }
 
 // LoadConfig reads configuration from file or environment variables.
@@ -0,0 +1,10 @@
+package util
+
+// Constants for all statuses of a money request, only pending requests can be accepted, declined or cancelled
+const (
+	MoneyRequestPending   = "pending"
+	MoneyRequestAccepted  = "accepted"
+	MoneyRequestDeclined  = "declined"
+	MoneyRequestCancelled = "cancelled"
+	MoneyRequestExpired   = "expired"
+)
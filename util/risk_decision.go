@@ -0,0 +1,18 @@
+package util
+
+// Constants for the decisions of the risk rules, from the least to the most severe. Only allowed transfers are made.
+const (
+	RiskAllow  = "allow"
+	RiskReview = "review"
+	RiskDeny   = "deny"
+)
+
+// IsSupportedRiskDecision returns true if the decision is supported
+func IsSupportedRiskDecision(decision string) bool {
+	switch decision {
+	case RiskAllow, RiskReview, RiskDeny:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,19 @@
+package util
+
+// Constants for all supported user roles
+const (
+	DepositorRole = "depositor"
+	TellerRole    = "teller"
+	BankerRole    = "banker"
+	AdminRole     = "admin"
+)
+
+// IsSupportedRole returns true if the role is supported
+func IsSupportedRole(role string) bool {
+	switch role {
+	case DepositorRole, TellerRole, BankerRole, AdminRole:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,10 @@
+package util
+
+// Constants for all statuses of a scheduled transfer
+const (
+	ScheduledTransferPending    = "pending"
+	ScheduledTransferProcessing = "processing"
+	ScheduledTransferCompleted  = "completed"
+	ScheduledTransferFailed     = "failed"
+	ScheduledTransferCancelled  = "cancelled"
+)
@@ -0,0 +1,69 @@
+package util
+
+import "time"
+
+// Constants for all statuses of a standing order
+const (
+	StandingOrderActive    = "active"
+	StandingOrderPaused    = "paused"
+	StandingOrderCompleted = "completed"
+	StandingOrderCancelled = "cancelled"
+)
+
+// Constants for all supported standing order frequencies
+const (
+	DailyFrequency   = "daily"
+	WeeklyFrequency  = "weekly"
+	MonthlyFrequency = "monthly"
+)
+
+// IsSupportedFrequency returns true if the standing order frequency is supported
+func IsSupportedFrequency(frequency string) bool {
+	switch frequency {
+	case DailyFrequency, WeeklyFrequency, MonthlyFrequency:
+		return true
+	}
+
+	return false
+}
+
+// monthlyRun returns the run on the day of the month at the time of day of t. The last day of the month is used if the
+// month is shorter than day.
+func monthlyRun(year int, month time.Month, day int, t time.Time) time.Time {
+	first := time.Date(year, month, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+
+	if lastDay := first.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
+
+// FirstStandingOrderRun returns the first run of a standing order starting at start
+func FirstStandingOrderRun(frequency string, dayOfMonth int, start time.Time) time.Time {
+	if frequency != MonthlyFrequency {
+		return start
+	}
+
+	year, month, _ := start.Date()
+	run := monthlyRun(year, month, dayOfMonth, start)
+
+	if run.Before(start) {
+		run = monthlyRun(year, month+1, dayOfMonth, start)
+	}
+
+	return run
+}
+
+// NextStandingOrderRun returns the run of a standing order that follows the run at t
+func NextStandingOrderRun(frequency string, dayOfMonth int, t time.Time) time.Time {
+	switch frequency {
+	case DailyFrequency:
+		return t.AddDate(0, 0, 1)
+	case WeeklyFrequency:
+		return t.AddDate(0, 0, 7)
+	}
+
+	year, month, _ := t.Date()
+	return monthlyRun(year, month+1, dayOfMonth, t)
+}
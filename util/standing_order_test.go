@@ -0,0 +1,61 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+}
+
+func TestFirstStandingOrderRun(t *testing.T) {
+	testCases := []struct {
+		name       string
+		frequency  string
+		dayOfMonth int
+		start      time.Time
+		expected   time.Time
+	}{
+		{name: "Daily", frequency: DailyFrequency, start: date(2021, 3, 10), expected: date(2021, 3, 10)},
+		{name: "Weekly", frequency: WeeklyFrequency, start: date(2021, 3, 10), expected: date(2021, 3, 10)},
+		{name: "MonthlyLaterThisMonth", frequency: MonthlyFrequency, dayOfMonth: 15, start: date(2021, 3, 10),
+			expected: date(2021, 3, 15)},
+		{name: "MonthlyOnStartDay", frequency: MonthlyFrequency, dayOfMonth: 10, start: date(2021, 3, 10),
+			expected: date(2021, 3, 10)},
+		{name: "MonthlyNextMonth", frequency: MonthlyFrequency, dayOfMonth: 5, start: date(2021, 3, 10),
+			expected: date(2021, 4, 5)},
+		{name: "MonthlyShortMonth", frequency: MonthlyFrequency, dayOfMonth: 31, start: date(2021, 2, 10),
+			expected: date(2021, 2, 28)},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, FirstStandingOrderRun(tc.frequency, tc.dayOfMonth, tc.start))
+		})
+	}
+}
+
+func TestNextStandingOrderRun(t *testing.T) {
+	require.Equal(t, date(2021, 3, 1), NextStandingOrderRun(DailyFrequency, 0, date(2021, 2, 28)))
+	require.Equal(t, date(2021, 3, 7), NextStandingOrderRun(WeeklyFrequency, 0, date(2021, 2, 28)))
+
+	// Monthly orders go back to their day of the month after a shorter month
+	run := date(2021, 1, 31)
+	expected := []time.Time{date(2021, 2, 28), date(2021, 3, 31), date(2021, 4, 30), date(2021, 5, 31)}
+
+	for _, next := range expected {
+		run = NextStandingOrderRun(MonthlyFrequency, 31, run)
+		require.Equal(t, next, run)
+	}
+
+	require.Equal(t, date(2022, 1, 15), NextStandingOrderRun(MonthlyFrequency, 15, date(2021, 12, 15)))
+}
+
+func TestIsSupportedFrequency(t *testing.T) {
+	require.True(t, IsSupportedFrequency(MonthlyFrequency))
+	require.False(t, IsSupportedFrequency("yearly"))
+}
@@ -0,0 +1,9 @@
+package util
+
+// Constants for all statuses of a transfer approval, only pending approvals hold money and can be approved or rejected
+const (
+	TransferApprovalPending  = "pending"
+	TransferApprovalApproved = "approved"
+	TransferApprovalRejected = "rejected"
+	TransferApprovalExpired  = "expired"
+)
@@ -0,0 +1,14 @@
+package util
+
+// Constants for the scopes of a transfer limit, a user limit takes precedence over the limit of the user's role
+const (
+	TransferLimitUserScope = "user"
+	TransferLimitRoleScope = "role"
+)
+
+// Constants for the periods a transfer limit applies to
+const (
+	PerTransactionLimit = "per_transaction"
+	DailyLimit          = "daily"
+	MonthlyLimit        = "monthly"
+)
@@ -0,0 +1,9 @@
+package util
+
+// Constants for all statuses of a transfer, only posted transfers have moved money. Pending transfers hold the
+// amount on the sender account until they are posted or voided.
+const (
+	TransferPending = "pending"
+	TransferPosted  = "posted"
+	TransferVoided  = "voided"
+)